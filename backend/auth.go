@@ -0,0 +1,123 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//calc the auth data of plugin by the salt of server
+func (c *Conn) authResponse(plugin string) ([]byte, error) {
+	password := []byte(c.password)
+	switch plugin {
+	case "", mysql.AUTH_NAME:
+		return mysql.CalcPassword(c.salt, password), nil
+	case mysql.AUTH_CACHING_SHA2_PASSWORD:
+		return mysql.CalcCachingSha2Password(c.salt, password), nil
+	case mysql.AUTH_SHA256_PASSWORD:
+		if len(password) == 0 {
+			return []byte{0}, nil
+		}
+		//the password is sent in plain text over tls
+		if c.IsTLS() {
+			return append(password, 0), nil
+		}
+		return []byte{mysql.SHA256_REQUEST_PUBLIC_KEY}, nil
+	}
+	return nil, fmt.Errorf("auth plugin %s is not supported", plugin)
+}
+
+func (c *Conn) writeAuthData(data []byte) error {
+	return c.writePacket(append(make([]byte, 4), data...))
+}
+
+//read the result of auth, handle the auth switch request and
+//the more data of caching_sha2_password and sha256_password
+func (c *Conn) readAuthResult() error {
+	for {
+		data, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+
+		switch data[0] {
+		case mysql.OK_HEADER:
+			_, err = c.handleOKPacket(data)
+			return err
+		case mysql.ERR_HEADER:
+			return c.handleErrorPacket(data)
+		case mysql.AUTH_SWITCH_HEADER:
+			err = c.handleAuthSwitch(data[1:])
+		case mysql.AUTH_MORE_DATA_HEADER:
+			err = c.handleAuthMoreData(data[1:])
+		default:
+			err = errors.New("invalid auth packet")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//the auth switch request is plugin name[00] and the new salt
+func (c *Conn) handleAuthSwitch(data []byte) error {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return errors.New("old password auth is not supported")
+	}
+	c.authPlugin = string(data[:end])
+	salt := data[end+1:]
+	if 0 < len(salt) && salt[len(salt)-1] == 0 {
+		salt = salt[:len(salt)-1]
+	}
+	c.salt = append(c.salt[:0], salt...)
+
+	auth, err := c.authResponse(c.authPlugin)
+	if err != nil {
+		return err
+	}
+	return c.writeAuthData(auth)
+}
+
+func (c *Conn) handleAuthMoreData(data []byte) error {
+	if c.authPlugin == mysql.AUTH_CACHING_SHA2_PASSWORD && len(data) == 1 {
+		switch data[0] {
+		case mysql.CACHING_SHA2_FAST_AUTH_SUCCESS:
+			//the ok packet follows
+			return nil
+		case mysql.CACHING_SHA2_PERFORM_FULL_AUTH:
+			if c.IsTLS() {
+				return c.writeAuthData(append([]byte(c.password), 0))
+			}
+			return c.writeAuthData([]byte{mysql.CACHING_SHA2_REQUEST_PUBLIC_KEY})
+		}
+		return fmt.Errorf("invalid auth more data %d", data[0])
+	}
+
+	//the public key of server in PEM
+	pub, err := mysql.ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+	enc, err := mysql.EncryptPassword([]byte(c.password), c.salt, pub)
+	if err != nil {
+		return err
+	}
+	return c.writeAuthData(enc)
+}
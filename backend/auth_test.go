@@ -0,0 +1,104 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+func TestReadAuthResultFullAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	salt, _ := mysql.RandomBuf(20)
+	go func() {
+		pkg := mysql.NewPacketIO(serverConn)
+		pkg.Sequence = 2
+		write := func(data ...byte) {
+			if err := pkg.WritePacket(append(make([]byte, 4), data...)); err != nil {
+				t.Error(err)
+			}
+		}
+
+		//switch to caching_sha2_password
+		switchData := append([]byte{mysql.AUTH_SWITCH_HEADER}, mysql.AUTH_CACHING_SHA2_PASSWORD...)
+		switchData = append(switchData, 0)
+		switchData = append(switchData, salt...)
+		write(append(switchData, 0)...)
+		if _, err := pkg.ReadPacket(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		write(mysql.AUTH_MORE_DATA_HEADER, mysql.CACHING_SHA2_PERFORM_FULL_AUTH)
+		data, err := pkg.ReadPacket()
+		if err != nil || len(data) != 1 || data[0] != mysql.CACHING_SHA2_REQUEST_PUBLIC_KEY {
+			t.Errorf("expect public key request, got %v %v", data, err)
+			return
+		}
+
+		write(append([]byte{mysql.AUTH_MORE_DATA_HEADER}, pubPem...)...)
+		enc, err := pkg.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, enc, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for i := range plain {
+			plain[i] ^= salt[i%len(salt)]
+		}
+		if string(plain) != "kingshard\x00" {
+			t.Errorf("invalid password %q", plain)
+		}
+		write(mysql.OK_HEADER, 0, 0, 2, 0, 0, 0)
+	}()
+
+	c := new(Conn)
+	c.conn = clientConn
+	c.pkg = mysql.NewPacketIO(clientConn)
+	c.pkg.Sequence = 2
+	c.capability = clientCapability
+	c.password = "kingshard"
+	c.authPlugin = mysql.AUTH_NAME
+	if err := c.readAuthResult(); err != nil {
+		t.Fatal(err)
+	}
+	if c.authPlugin != mysql.AUTH_CACHING_SHA2_PASSWORD {
+		t.Fatalf("auth plugin is not switched, %s", c.authPlugin)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/flike/kingshard/mysql"
@@ -28,8 +29,17 @@ import (
 
 var (
 	pingPeriod = int64(time.Second * 16)
+
+	//the capabilities supported by proxy as a client
+	clientCapability = mysql.CLIENT_PROTOCOL_41 | mysql.CLIENT_SECURE_CONNECTION |
+		mysql.CLIENT_LONG_PASSWORD | mysql.CLIENT_TRANSACTIONS | mysql.CLIENT_LONG_FLAG |
+		mysql.CLIENT_PLUGIN_AUTH
 )
 
+//RowHandler is called with every row read from resultset, the row is
+//not kept in the result if handled is true
+type RowHandler func(result *mysql.Result, value []interface{}, row mysql.RowData) (handled bool, err error)
+
 //proxy <-> mysql server
 type Conn struct {
 	conn net.Conn
@@ -43,14 +53,35 @@ type Conn struct {
 
 	capability uint32
 
-	status uint16
+	status   uint16
+	warnings uint16 //the warnings of last statement
 
 	collation mysql.CollationId
 	charset   string
 	salt      []byte
 
-	pushTimestamp int64
-	pkgErr        error
+	//the auth plugin used by mysql server
+	authPlugin string
+
+	compress      string //zlib or zstd
+	compressLevel int
+
+	pushTimestamp   int64
+	createTimestamp int64
+	pkgErr          error
+	interrupted     int32 //set by Interrupt, the conn can not be reused
+
+	memTracker *MemTracker
+	rowHandler RowHandler
+
+	sessionVars []SessionVar //the variables set by clients
+
+	tls *TLSConfig //nil if tls is disabled
+
+	//the prepared statements cached by db and query
+	stmts         map[string]*Stmt
+	stmtKeys      []string //in the order of prepared, the oldest is evicted first
+	stmtCacheSize int      //0 means DefaultStmtCacheSize
 }
 
 func (c *Conn) Connect(addr string, user string, password string, db string) error {
@@ -70,6 +101,8 @@ func (c *Conn) ReConnect() error {
 	if c.conn != nil {
 		c.conn.Close()
 	}
+	//the prepared statements are released with the old session
+	c.resetStmtCache()
 
 	n := "tcp"
 	if strings.Contains(c.addr, "/") {
@@ -92,23 +125,31 @@ func (c *Conn) ReConnect() error {
 	tcpConn.SetKeepAlive(true)
 	c.conn = tcpConn
 	c.pkg = mysql.NewPacketIO(tcpConn)
+	//the new session has no variables set
+	c.sessionVars = nil
 
 	if err := c.readInitialHandshake(); err != nil {
 		c.conn.Close()
 		return err
 	}
 
+	if err := c.startTLS(); err != nil {
+		c.conn.Close()
+		return err
+	}
+
 	if err := c.writeAuthHandshake(); err != nil {
 		c.conn.Close()
 
 		return err
 	}
 
-	if _, err := c.readOK(); err != nil {
+	if err := c.readAuthResult(); err != nil {
 		c.conn.Close()
 
 		return err
 	}
+	c.enableCompression()
 
 	//we must always use autocommit
 	if !c.IsAutoCommit() {
@@ -124,6 +165,7 @@ func (c *Conn) ReConnect() error {
 
 func (c *Conn) Close() error {
 	if c.conn != nil {
+		c.closeStmtCache()
 		c.conn.Close()
 		c.conn = nil
 		c.salt = nil
@@ -133,6 +175,20 @@ func (c *Conn) Close() error {
 	return nil
 }
 
+//Interrupt break off the reading and writing of conn, the interrupted conn
+//will be closed when pushed back to pool
+func (c *Conn) Interrupt() {
+	atomic.StoreInt32(&c.interrupted, 1)
+	if c.conn != nil {
+		c.conn.SetDeadline(time.Now())
+	}
+}
+
+//IsInterrupted return true if the deadline of conn was expired by Interrupt
+func (c *Conn) IsInterrupted() bool {
+	return atomic.LoadInt32(&c.interrupted) == 1
+}
+
 func (c *Conn) readPacket() ([]byte, error) {
 	d, err := c.pkg.ReadPacket()
 	c.pkgErr = err
@@ -164,7 +220,8 @@ func (c *Conn) readInitialHandshake() error {
 	//connection id length is 4
 	pos := 1 + bytes.IndexByte(data[1:], 0x00) + 1 + 4
 
-	c.salt = append(c.salt, data[pos:pos+8]...)
+	c.salt = append(c.salt[:0], data[pos:pos+8]...)
+	c.authPlugin = ""
 
 	//skip filter
 	pos += 8 + 1
@@ -195,6 +252,16 @@ func (c *Conn) readInitialHandshake() error {
 		// mysql-proxy also use 12
 		// which is not documented but seems to work.
 		c.salt = append(c.salt, data[pos:pos+12]...)
+		pos += 13
+
+		//auth plugin name [null terminated string]
+		if c.capability&mysql.CLIENT_PLUGIN_AUTH > 0 && len(data) > pos {
+			if end := bytes.IndexByte(data[pos:], 0x00); end >= 0 {
+				c.authPlugin = string(data[pos : pos+end])
+			} else {
+				c.authPlugin = string(data[pos:])
+			}
+		}
 	}
 
 	return nil
@@ -202,10 +269,11 @@ func (c *Conn) readInitialHandshake() error {
 
 func (c *Conn) writeAuthHandshake() error {
 	// Adjust client capability flags based on server support
-	capability := mysql.CLIENT_PROTOCOL_41 | mysql.CLIENT_SECURE_CONNECTION |
-		mysql.CLIENT_LONG_PASSWORD | mysql.CLIENT_TRANSACTIONS | mysql.CLIENT_LONG_FLAG
-
-	capability &= c.capability
+	capability := clientCapability & c.capability
+	if c.IsTLS() {
+		capability |= mysql.CLIENT_SSL
+	}
+	capability |= c.negotiateCompress()
 
 	//packet length
 	//capbility 4
@@ -217,8 +285,13 @@ func (c *Conn) writeAuthHandshake() error {
 	//username
 	length += len(c.user) + 1
 
-	//we only support secure connection
-	auth := mysql.CalcPassword(c.salt, []byte(c.password))
+	//we only support secure connection,
+	//use mysql_native_password if the plugin of server is not supported
+	auth, err := c.authResponse(c.authPlugin)
+	if err != nil {
+		c.authPlugin = mysql.AUTH_NAME
+		auth = mysql.CalcPassword(c.salt, []byte(c.password))
+	}
 
 	length += 1 + len(auth)
 
@@ -228,6 +301,15 @@ func (c *Conn) writeAuthHandshake() error {
 		length += len(c.db) + 1
 	}
 
+	if capability&mysql.CLIENT_PLUGIN_AUTH > 0 {
+		length += len(c.authPlugin) + 1
+	}
+
+	//the level of zstd
+	if capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 {
+		length++
+	}
+
 	c.capability = capability
 
 	data := make([]byte, length+4)
@@ -265,13 +347,26 @@ func (c *Conn) writeAuthHandshake() error {
 	if len(c.db) > 0 {
 		pos += copy(data[pos:], c.db)
 		//data[pos] = 0x00
+		pos++
+	}
+
+	// auth plugin name [null terminated string]
+	if capability&mysql.CLIENT_PLUGIN_AUTH > 0 {
+		pos += copy(data[pos:], c.authPlugin)
+		//data[pos] = 0x00
+		pos++
+	}
+
+	// zstd compression level [1 byte]
+	if capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 {
+		data[pos] = c.zstdLevel()
 	}
 
 	return c.writePacket(data)
 }
 
 func (c *Conn) writeCommand(command byte) error {
-	c.pkg.Sequence = 0
+	c.pkg.ResetSequence()
 
 	return c.writePacket([]byte{
 		0x01, //1 bytes long
@@ -283,7 +378,7 @@ func (c *Conn) writeCommand(command byte) error {
 }
 
 func (c *Conn) writeCommandBuf(command byte, arg []byte) error {
-	c.pkg.Sequence = 0
+	c.pkg.ResetSequence()
 
 	length := len(arg) + 1
 
@@ -297,7 +392,7 @@ func (c *Conn) writeCommandBuf(command byte, arg []byte) error {
 }
 
 func (c *Conn) writeCommandStr(command byte, arg string) error {
-	c.pkg.Sequence = 0
+	c.pkg.ResetSequence()
 
 	length := len(arg) + 1
 
@@ -311,7 +406,7 @@ func (c *Conn) writeCommandStr(command byte, arg string) error {
 }
 
 func (c *Conn) writeCommandUint32(command byte, arg uint32) error {
-	c.pkg.Sequence = 0
+	c.pkg.ResetSequence()
 
 	return c.writePacket([]byte{
 		0x05, //5 bytes long
@@ -329,7 +424,7 @@ func (c *Conn) writeCommandUint32(command byte, arg uint32) error {
 }
 
 func (c *Conn) writeCommandStrStr(command byte, arg1 string, arg2 string) error {
-	c.pkg.Sequence = 0
+	c.pkg.ResetSequence()
 
 	data := make([]byte, 4, 6+len(arg1)+len(arg2))
 
@@ -378,18 +473,31 @@ func (c *Conn) GetAddr() string {
 	return c.addr
 }
 
+//SetMemTracker set the tracker of bytes read by the current query
+func (c *Conn) SetMemTracker(t *MemTracker) {
+	c.memTracker = t
+}
+
+//GetMemTracker return the tracker of bytes read by the current query
+func (c *Conn) GetMemTracker() *MemTracker {
+	return c.memTracker
+}
+
+//SetRowHandler set the handler of rows read by the current query
+func (c *Conn) SetRowHandler(h RowHandler) {
+	c.rowHandler = h
+}
+
+//GetRowHandler return the handler of rows read by the current query
+func (c *Conn) GetRowHandler() RowHandler {
+	return c.rowHandler
+}
+
 func (c *Conn) Execute(command string, args ...interface{}) (*mysql.Result, error) {
 	if len(args) == 0 {
 		return c.exec(command)
 	} else {
-		if s, err := c.Prepare(command); err != nil {
-			return nil, err
-		} else {
-			var r *mysql.Result
-			r, err = s.Execute(args...)
-			s.Close()
-			return r, err
-		}
+		return c.ExecutePrepared(command, args...)
 	}
 }
 
@@ -581,7 +689,7 @@ func (c *Conn) readResultRows(result *mysql.Result, isBinary bool) (err error) {
 		// EOF Packet
 		if c.isEOFPacket(data) {
 			if c.capability&mysql.CLIENT_PROTOCOL_41 > 0 {
-				//result.Warnings = binary.LittleEndian.Uint16(data[1:])
+				c.warnings = binary.LittleEndian.Uint16(data[1:])
 				//todo add strict_mode, warning will be treat as error
 				result.Status = binary.LittleEndian.Uint16(data[3:])
 				c.status = result.Status
@@ -590,9 +698,29 @@ func (c *Conn) readResultRows(result *mysql.Result, isBinary bool) (err error) {
 			break
 		}
 
+		if c.rowHandler != nil {
+			if err = c.handleResultRow(result, data, isBinary); err != nil {
+				c.discardResultRows(err)
+				return
+			}
+			continue
+		}
+
+		if c.memTracker != nil {
+			if err = c.memTracker.Consume(int64(len(data))); err != nil {
+				c.discardResultRows(err)
+				return
+			}
+		}
+
 		result.RowDatas = append(result.RowDatas, data)
 	}
 
+	//the rows are parsed when read if handler is set
+	if c.rowHandler != nil {
+		return nil
+	}
+
 	result.Values = make([][]interface{}, len(result.RowDatas))
 
 	for i := range result.Values {
@@ -606,6 +734,30 @@ func (c *Conn) readResultRows(result *mysql.Result, isBinary bool) (err error) {
 	return nil
 }
 
+//parse the row and pass it to the row handler, the row is kept in
+//result only if it is not handled
+func (c *Conn) handleResultRow(result *mysql.Result, data []byte, isBinary bool) error {
+	row := mysql.RowData(data)
+	value, err := row.Parse(result.Fields, isBinary)
+	if err != nil {
+		return err
+	}
+
+	handled, err := c.rowHandler(result, value, row)
+	if err != nil || handled {
+		return err
+	}
+
+	if c.memTracker != nil {
+		if err = c.memTracker.Consume(int64(len(data))); err != nil {
+			return err
+		}
+	}
+	result.RowDatas = append(result.RowDatas, row)
+	result.Values = append(result.Values, value)
+	return nil
+}
+
 func (c *Conn) readUntilEOF() (err error) {
 	var data []byte
 
@@ -624,6 +776,16 @@ func (c *Conn) readUntilEOF() (err error) {
 	return
 }
 
+//the rest rows of resultset will not be read, drain them if the conn
+//is in transaction, otherwise close the conn when pushed back to pool
+func (c *Conn) discardResultRows(err error) {
+	if c.IsInTransaction() {
+		c.readUntilEOF()
+	} else {
+		c.pkgErr = err
+	}
+}
+
 func (c *Conn) isEOFPacket(data []byte) bool {
 	return data[0] == mysql.EOF_HEADER && len(data) <= 5
 }
@@ -645,8 +807,8 @@ func (c *Conn) handleOKPacket(data []byte) (*mysql.Result, error) {
 		pos += 2
 
 		//todo:strict_mode, check warnings as error
-		//Warnings := binary.LittleEndian.Uint16(data[pos:])
-		//pos += 2
+		c.warnings = binary.LittleEndian.Uint16(data[pos:])
+		pos += 2
 	} else if c.capability&mysql.CLIENT_TRANSACTIONS > 0 {
 		r.Status = binary.LittleEndian.Uint16(data[pos:])
 		c.status = r.Status
@@ -693,6 +855,7 @@ func (c *Conn) readOK() (*mysql.Result, error) {
 }
 
 func (c *Conn) readResult(binary bool) (*mysql.Result, error) {
+	c.warnings = 0
 	data, err := c.readPacket()
 	if err != nil {
 		return nil, err
@@ -709,6 +872,11 @@ func (c *Conn) readResult(binary bool) (*mysql.Result, error) {
 	return c.readResultset(data, binary)
 }
 
+//Warnings return the warning count of last statement
+func (c *Conn) Warnings() uint16 {
+	return c.warnings
+}
+
 func (c *Conn) IsAutoCommit() bool {
 	return c.status&mysql.SERVER_STATUS_AUTOCOMMIT > 0
 }
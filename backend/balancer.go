@@ -80,23 +80,23 @@ func (n *Node) InitBalancer() {
 }
 
 func (n *Node) GetNextSlave() (*DB, error) {
-	var index int
 	queueLen := len(n.RoundRobinQ)
 	if queueLen == 0 {
 		return nil, errors.ErrNoDatabase
 	}
-	if queueLen == 1 {
-		index = n.RoundRobinQ[0]
-		return n.Slave[index], nil
-	}
 
-	n.LastSlaveIndex = n.LastSlaveIndex % queueLen
-	index = n.RoundRobinQ[n.LastSlaveIndex]
-	if len(n.Slave) <= index {
-		return nil, errors.ErrNoDatabase
+	//skip the slaves lagging behind master
+	for i := 0; i < queueLen; i++ {
+		n.LastSlaveIndex = n.LastSlaveIndex % queueLen
+		index := n.RoundRobinQ[n.LastSlaveIndex]
+		if len(n.Slave) <= index {
+			return nil, errors.ErrNoDatabase
+		}
+		n.LastSlaveIndex++
+		n.LastSlaveIndex = n.LastSlaveIndex % queueLen
+		if !n.isSlaveLagging(n.Slave[index]) {
+			return n.Slave[index], nil
+		}
 	}
-	db := n.Slave[index]
-	n.LastSlaveIndex++
-	n.LastSlaveIndex = n.LastSlaveIndex % queueLen
-	return db, nil
+	return nil, errors.ErrSlaveLagging
 }
@@ -0,0 +1,76 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"fmt"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//checkCompress check the compression algorithm of node
+func checkCompress(compress string) error {
+	switch compress {
+	case "", mysql.COMPRESS_ZLIB, mysql.COMPRESS_ZSTD:
+		return nil
+	}
+	return fmt.Errorf("invalid compress %s, must be zlib or zstd", compress)
+}
+
+//negotiateCompress return the capability of compression supported by
+//both mysql server and proxy, zstd falls back to zlib if not supported
+func (c *Conn) negotiateCompress() uint32 {
+	switch c.compress {
+	case mysql.COMPRESS_ZSTD:
+		if c.capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 &&
+			mysql.HasCompressCodec(mysql.COMPRESS_ZSTD) {
+			return mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM
+		}
+		fallthrough
+	case mysql.COMPRESS_ZLIB:
+		if c.capability&mysql.CLIENT_COMPRESS > 0 {
+			return mysql.CLIENT_COMPRESS
+		}
+	}
+	return 0
+}
+
+func (c *Conn) zstdLevel() byte {
+	if c.compressLevel <= 0 {
+		return mysql.DEFAULT_ZSTD_LEVEL
+	}
+	return byte(c.compressLevel)
+}
+
+//enableCompression switch to the compressed protocol after auth
+func (c *Conn) enableCompression() {
+	switch {
+	case c.capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0:
+		c.pkg.EnableCompression(mysql.NewCompressCodec(mysql.COMPRESS_ZSTD, int(c.zstdLevel())))
+	case c.capability&mysql.CLIENT_COMPRESS > 0:
+		c.pkg.EnableCompression(mysql.NewCompressCodec(mysql.COMPRESS_ZLIB, 0))
+	}
+}
+
+//Compression return the compression algorithm used by conn
+func (c *Conn) Compression() string {
+	if c.pkg == nil || !c.pkg.IsCompressed() {
+		return ""
+	}
+	if c.capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 {
+		return mysql.COMPRESS_ZSTD
+	}
+	return mysql.COMPRESS_ZLIB
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+func TestNegotiateCompress(t *testing.T) {
+	if err := checkCompress("gzip"); err == nil {
+		t.Fatal("gzip is not supported")
+	}
+
+	c := new(Conn)
+	c.capability = mysql.CLIENT_COMPRESS | mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM
+	if c.negotiateCompress() != 0 {
+		t.Fatal("compression is not enabled")
+	}
+
+	c.compress = mysql.COMPRESS_ZLIB
+	if c.negotiateCompress() != mysql.CLIENT_COMPRESS {
+		t.Fatal("zlib should be used")
+	}
+
+	//fall back to zlib if no zstd codec
+	c.compress = mysql.COMPRESS_ZSTD
+	expect := mysql.CLIENT_COMPRESS
+	if mysql.HasCompressCodec(mysql.COMPRESS_ZSTD) {
+		expect = mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM
+	}
+	if c.negotiateCompress() != expect {
+		t.Fatal("zstd is not negotiated")
+	}
+
+	c.capability = 0
+	if c.negotiateCompress() != 0 {
+		t.Fatal("server does not support compression")
+	}
+}
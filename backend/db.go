@@ -50,14 +50,59 @@ type DB struct {
 	cacheConns  chan *Conn
 	checkConn   *Conn
 	lastPing    int64
+	lag         int64 //seconds behind master of slave
+	readOnly    int32
 
 	idelTime      int64
 	idleNextCheck int64
+
+	waitTimeout time.Duration //0 means waiting until a conn is returned
+	maxLifetime int64         //second, 0 means no limit
+	minIdle     int
+	maxIdle     int //0 means no limit
+
+	inUse        int64
+	waitCount    int64
+	waitDuration int64
+	dialErrors   int64
+
+	opts ConnOptions
+}
+
+//ConnOptions is the options of conns to mysql server
+type ConnOptions struct {
+	TLS           *TLSConfig //nil means tls is disabled
+	Compress      string     //zlib or zstd, empty means no compression
+	CompressLevel int        //the level of zstd
+	StmtCacheSize int        //the prepared statements cached in a conn, 0 means default
+}
+
+//PoolOptions is the options of conn pool
+type PoolOptions struct {
+	WaitTimeout time.Duration
+	MaxLifetime int64
+	MinIdle     int
+	MaxIdle     int
+}
+
+//PoolStats is the metrics of conn pool
+type PoolStats struct {
+	InUse      int64
+	Idle       int
+	WaitCount  int64
+	WaitTime   time.Duration
+	DialErrors int64
 }
 
 func Open(addr string, user string, password string, dbName string, maxConnNum int, initConnNum int, idleTime int64) (*DB, error) {
+	return OpenWithOptions(addr, user, password, dbName, maxConnNum, initConnNum, idleTime, ConnOptions{})
+}
+
+//OpenWithOptions open db with the tls and compression of conns
+func OpenWithOptions(addr string, user string, password string, dbName string, maxConnNum int, initConnNum int, idleTime int64, opts ConnOptions) (*DB, error) {
 	var err error
 	db := new(DB)
+	db.opts = opts
 	db.addr = addr
 	db.user = user
 	db.password = password
@@ -110,11 +155,32 @@ func Open(addr string, user string, password string, dbName string, maxConnNum i
 		}
 	}
 	db.SetLastPing()
+	atomic.StoreInt64(&(db.lag), LagUnknown)
 	db.idleNextCheck = time.Now().Unix() + db.idelTime
 
 	return db, nil
 }
 
+func (db *DB) SetPoolOptions(opts PoolOptions) {
+	db.waitTimeout = opts.WaitTimeout
+	db.maxLifetime = opts.MaxLifetime
+	db.minIdle = opts.MinIdle
+	db.maxIdle = opts.MaxIdle
+	if db.maxConnNum < db.minIdle {
+		db.minIdle = db.maxConnNum
+	}
+}
+
+func (db *DB) PoolStats() PoolStats {
+	return PoolStats{
+		InUse:      atomic.LoadInt64(&db.inUse),
+		Idle:       db.IdleConnCount(),
+		WaitCount:  atomic.LoadInt64(&db.waitCount),
+		WaitTime:   time.Duration(atomic.LoadInt64(&db.waitDuration)),
+		DialErrors: atomic.LoadInt64(&db.dialErrors),
+	}
+}
+
 func (db *DB) Addr() string {
 	return db.addr
 }
@@ -200,6 +266,7 @@ func (db *DB) Ping() error {
 }
 
 func (db *DB) ClearIDLEConns() {
+	db.fillIdleConns()
 	if time.Now().Unix() < db.idleNextCheck {
 		return
 	}
@@ -223,7 +290,7 @@ func (db *DB) ClearIDLEConns() {
 	for i := 0; i < cacheLen; i++ {
 		select {
 		case co := <-cacheConns:
-			if db.idelTime <= time.Now().Unix()-co.pushTimestamp {
+			if db.idelTime <= time.Now().Unix()-co.pushTimestamp || db.isExpired(co) {
 				db.closeConn(co)
 			} else {
 				cacheConns <- co
@@ -242,16 +309,65 @@ func (db *DB) ClearIDLEConns() {
 	db.idleNextCheck = time.Now().Unix() + db.idelTime/2
 }
 
+//keep min_idle_conns conns in cache for the burst of requests
+func (db *DB) fillIdleConns() {
+	cacheConns, idleConns := db.getConns()
+	if nil == cacheConns || nil == idleConns {
+		return
+	}
+
+	for len(cacheConns) < db.minIdle {
+		var co *Conn
+		select {
+		case co = <-idleConns:
+		default:
+			return
+		}
+		if co == nil {
+			return
+		}
+		if err := db.connect(co); err != nil {
+			db.closeConn(co)
+			return
+		}
+		co.pushTimestamp = time.Now().Unix()
+		select {
+		case cacheConns <- co:
+		default:
+			db.closeConn(co)
+			return
+		}
+	}
+}
+
 func (db *DB) newConn() (*Conn, error) {
 	co := new(Conn)
 
-	if err := co.Connect(db.addr, db.user, db.password, db.db); err != nil {
+	if err := db.connect(co); err != nil {
 		return nil, err
 	}
 
 	return co, nil
 }
 
+func (db *DB) connect(co *Conn) error {
+	co.tls = db.opts.TLS
+	co.compress = db.opts.Compress
+	co.compressLevel = db.opts.CompressLevel
+	co.stmtCacheSize = db.opts.StmtCacheSize
+	if err := co.Connect(db.addr, db.user, db.password, db.db); err != nil {
+		atomic.AddInt64(&db.dialErrors, 1)
+		return err
+	}
+	co.createTimestamp = time.Now().Unix()
+	return nil
+}
+
+//the conn over max_conn_lifetime should be closed
+func (db *DB) isExpired(co *Conn) bool {
+	return 0 < db.maxLifetime && db.maxLifetime <= time.Now().Unix()-co.createTimestamp
+}
+
 func (db *DB) closeConn(co *Conn) error {
 	if co != nil {
 		co.Close()
@@ -300,6 +416,12 @@ func (db *DB) tryReuse(co *Conn) error {
 }
 
 func (db *DB) PopConn() (*Conn, error) {
+	return db.popConn(true)
+}
+
+//the conn is popped without waiting if wait is false, ErrPoolBusy is
+//returned when all the conns are in use
+func (db *DB) popConn(wait bool) (*Conn, error) {
 	var co *Conn
 	var err error
 
@@ -309,7 +431,7 @@ func (db *DB) PopConn() (*Conn, error) {
 	}
 	co = db.GetConnFromCache(cacheConns)
 	if co == nil {
-		co, err = db.GetConnFromIdle(cacheConns, idleConns)
+		co, err = db.getConnFromIdle(cacheConns, idleConns, wait)
 		if err != nil {
 			return nil, err
 		}
@@ -329,6 +451,10 @@ func (db *DB) GetConnFromCache(cacheConns chan *Conn) *Conn {
 	var err error
 	for 0 < len(cacheConns) {
 		co = <-cacheConns
+		if co != nil && db.isExpired(co) {
+			db.closeConn(co)
+			co = nil
+		}
 		if co != nil && PingPeroid < time.Now().Unix()-co.pushTimestamp {
 			err = co.Ping()
 			if err != nil {
@@ -344,31 +470,79 @@ func (db *DB) GetConnFromCache(cacheConns chan *Conn) *Conn {
 }
 
 func (db *DB) GetConnFromIdle(cacheConns, idleConns chan *Conn) (*Conn, error) {
+	return db.getConnFromIdle(cacheConns, idleConns, true)
+}
+
+func (db *DB) getConnFromIdle(cacheConns, idleConns chan *Conn, wait bool) (*Conn, error) {
 	var co *Conn
 	var err error
+	fromIdle := false
 	select {
 	case co = <-idleConns:
-		err = co.Connect(db.addr, db.user, db.password, db.db)
+		fromIdle = true
+	case co = <-cacheConns:
+	default:
+		if !wait {
+			return nil, errors.ErrPoolBusy
+		}
+		co, fromIdle, err = db.waitConn(cacheConns, idleConns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if co == nil {
+		return nil, errors.ErrConnIsNil
+	}
+	if !fromIdle && db.isExpired(co) {
+		co.Close()
+		fromIdle = true
+	}
+	if fromIdle {
+		err = db.connect(co)
 		if err != nil {
 			db.closeConn(co)
 			return nil, err
 		}
 		return co, nil
-	case co = <-cacheConns:
-		if co == nil {
-			return nil, errors.ErrConnIsNil
-		}
-		if co != nil && PingPeroid < time.Now().Unix()-co.pushTimestamp {
-			err = co.Ping()
-			if err != nil {
-				db.closeConn(co)
-				return nil, errors.ErrBadConn
-			}
+	}
+
+	if PingPeroid < time.Now().Unix()-co.pushTimestamp {
+		err = co.Ping()
+		if err != nil {
+			db.closeConn(co)
+			return nil, errors.ErrBadConn
 		}
 	}
 	return co, nil
 }
 
+//all the conns are in use, wait for a conn returned to pool in pool_wait_timeout.
+//The waiters are woken up in FIFO order by the channels.
+func (db *DB) waitConn(cacheConns, idleConns chan *Conn) (*Conn, bool, error) {
+	start := time.Now()
+	defer func() {
+		atomic.AddInt64(&db.waitCount, 1)
+		atomic.AddInt64(&db.waitDuration, int64(time.Since(start)))
+	}()
+
+	var timeout <-chan time.Time
+	if 0 < db.waitTimeout {
+		timer := time.NewTimer(db.waitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case co := <-idleConns:
+		return co, true, nil
+	case co := <-cacheConns:
+		return co, false, nil
+	case <-timeout:
+		return nil, false, errors.ErrPoolWaitTimeout
+	}
+}
+
 func (db *DB) PushConn(co *Conn, err error) {
 	if co == nil {
 		return
@@ -382,6 +556,15 @@ func (db *DB) PushConn(co *Conn, err error) {
 		db.closeConn(co)
 		return
 	}
+	//the variables set by client must not leak to others
+	if err = co.ResetSessionVars(); err != nil {
+		db.closeConn(co)
+		return
+	}
+	if db.isExpired(co) || (0 < db.maxIdle && db.maxIdle <= len(conns)) {
+		db.closeConn(co)
+		return
+	}
 	co.pushTimestamp = time.Now().Unix()
 	select {
 	case conns <- co:
@@ -399,7 +582,10 @@ type BackendConn struct {
 
 func (p *BackendConn) Close() {
 	if p != nil && p.Conn != nil {
-		if p.Conn.pkgErr != nil {
+		atomic.AddInt64(&p.db.inUse, -1)
+		p.Conn.memTracker = nil
+		p.Conn.rowHandler = nil
+		if p.Conn.pkgErr != nil || p.Conn.IsInterrupted() {
 			p.db.closeConn(p.Conn)
 		} else {
 			p.db.PushConn(p.Conn, nil)
@@ -408,11 +594,28 @@ func (p *BackendConn) Close() {
 	}
 }
 
+//TryGetSiblingConn get another conn from the same db without waiting,
+//ErrPoolBusy is returned when all the conns are in use
+func (p *BackendConn) TryGetSiblingConn() (*BackendConn, error) {
+	return p.db.TryGetConn()
+}
+
 func (db *DB) GetConn() (*BackendConn, error) {
 	c, err := db.PopConn()
 	if err != nil {
 		return nil, err
 	}
+	atomic.AddInt64(&db.inUse, 1)
+	return &BackendConn{c, db}, nil
+}
+
+//TryGetConn get a conn without waiting for the conns returned to pool
+func (db *DB) TryGetConn() (*BackendConn, error) {
+	c, err := db.popConn(false)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&db.inUse, 1)
 	return &BackendConn{c, db}, nil
 }
 
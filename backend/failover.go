@@ -0,0 +1,406 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/flike/kingshard/core/errors"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+const (
+	//the master is down and a slave is promoted
+	FailoverMasterDown = "master_down"
+	//the master is read only and a writable slave is found
+	FailoverReadOnly = "read_only"
+)
+
+//FailoverEvent describe a slave becomes the master of node
+type FailoverEvent struct {
+	Node      string `json:"node"`
+	Reason    string `json:"reason"`
+	OldMaster string `json:"old_master"`
+	NewMaster string `json:"new_master"`
+	Time      int64  `json:"time"`
+}
+
+//FailoverHook is called after the master of node is switched
+type FailoverHook func(e *FailoverEvent)
+
+//the replication position of slave, gtid is empty if gtid_mode is off
+type replicaPos struct {
+	gtid       string
+	masterUUID string //the server uuid of master replicated from
+	file       string
+	pos        int64
+}
+
+//getReplicaPos return the executed position of slave
+func (c *Conn) getReplicaPos() (*replicaPos, error) {
+	r, err := c.exec("show slave status")
+	if err != nil {
+		return nil, err
+	}
+	if r.RowNumber() == 0 {
+		return nil, fmt.Errorf("%s is not a slave", c.addr)
+	}
+
+	p := new(replicaPos)
+	if p.file, err = r.GetStringByName(0, "Relay_Master_Log_File"); err != nil {
+		return nil, err
+	}
+	if p.pos, err = r.GetIntByName(0, "Exec_Master_Log_Pos"); err != nil {
+		return nil, err
+	}
+	//Executed_Gtid_Set and Master_UUID are only in mysql 5.6 or later
+	if gtid, err := r.GetStringByName(0, "Executed_Gtid_Set"); err == nil {
+		p.gtid = strings.Replace(gtid, "\n", "", -1)
+	}
+	if uuid, err := r.GetStringByName(0, "Master_UUID"); err == nil {
+		p.masterUUID = strings.ToLower(uuid)
+	}
+	return p, nil
+}
+
+type gtidInterval struct {
+	start int64
+	end   int64
+}
+
+//gtidSet is the executed transactions of every server uuid,
+//the intervals of uuid are sorted and not overlapped
+type gtidSet map[string][]gtidInterval
+
+//parse the gtid set such as "uuid1:1-5:7,uuid2:1-3"
+func parseGtidSet(gtid string) (gtidSet, error) {
+	set := make(gtidSet)
+	if len(gtid) == 0 {
+		return set, nil
+	}
+	for _, s := range strings.Split(gtid, ",") {
+		intervals := strings.Split(strings.TrimSpace(s), ":")
+		uuid := strings.ToLower(intervals[0])
+		for _, interval := range intervals[1:] {
+			bounds := strings.SplitN(interval, "-", 2)
+			start, err := strconv.ParseInt(bounds[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gtid set %s", gtid)
+			}
+			end := start
+			if len(bounds) == 2 {
+				if end, err = strconv.ParseInt(bounds[1], 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid gtid set %s", gtid)
+				}
+			}
+			set[uuid] = append(set[uuid], gtidInterval{start, end})
+		}
+	}
+	for uuid, intervals := range set {
+		set[uuid] = mergeGtidIntervals(intervals)
+	}
+	return set, nil
+}
+
+func mergeGtidIntervals(intervals []gtidInterval) []gtidInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start < intervals[j].start
+	})
+	merged := intervals[:0]
+	for _, i := range intervals {
+		last := len(merged) - 1
+		if 0 <= last && i.start <= merged[last].end+1 {
+			if merged[last].end < i.end {
+				merged[last].end = i.end
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+//return true if all the transactions of uuid in other are in s
+func (s gtidSet) containsUUID(other gtidSet, uuid string) bool {
+	for _, i := range other[uuid] {
+		found := false
+		for _, j := range s[uuid] {
+			if j.start <= i.start && i.end <= j.end {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+//return true if all the transactions in other are in s
+func (s gtidSet) contains(other gtidSet) bool {
+	for uuid := range other {
+		if !s.containsUUID(other, uuid) {
+			return false
+		}
+	}
+	return true
+}
+
+//count the transactions of uuid
+func (s gtidSet) count(uuid string) int64 {
+	var count int64
+	for _, i := range s[uuid] {
+		count += i.end - i.start + 1
+	}
+	return count
+}
+
+//compare by gtid set, ok is false if not comparable
+func (p *replicaPos) afterGtid(other *replicaPos) (after bool, ok bool) {
+	s1, err1 := parseGtidSet(p.gtid)
+	s2, err2 := parseGtidSet(other.gtid)
+	if err1 != nil || err2 != nil {
+		return false, false
+	}
+
+	//one slave has all the transactions of the other
+	c1, c2 := s1.contains(s2), s2.contains(s1)
+	if c1 || c2 {
+		return c1 && !c2, true
+	}
+
+	//the slaves diverged, compare the transactions from the old master
+	uuid := p.masterUUID
+	if len(uuid) == 0 || uuid != other.masterUUID {
+		return false, false
+	}
+	c1, c2 = s1.containsUUID(s2, uuid), s2.containsUUID(s1, uuid)
+	if c1 || c2 {
+		return c1 && !c2, true
+	}
+	return s1.count(uuid) > s2.count(uuid), true
+}
+
+//return true if position p is more advanced than other
+func (p *replicaPos) after(other *replicaPos) bool {
+	if 0 < len(p.gtid) && 0 < len(other.gtid) {
+		if after, ok := p.afterGtid(other); ok {
+			return after
+		}
+	}
+	if p.file != other.file {
+		return p.file > other.file
+	}
+	return p.pos > other.pos
+}
+
+//PromoteToMaster stop the replication of slave and make it writable
+func (c *Conn) PromoteToMaster() error {
+	for _, sql := range []string{
+		"stop slave",
+		"reset slave all",
+		"set global read_only = 0",
+	} {
+		if _, err := c.exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//RepointSlave make the slave replicate from master by gtid auto position
+func (c *Conn) RepointSlave(master string) error {
+	host, port, err := net.SplitHostPort(master)
+	if err != nil {
+		return err
+	}
+	for _, sql := range []string{
+		"stop slave",
+		fmt.Sprintf("change master to master_host = '%s', master_port = %s, master_auto_position = 1",
+			mysql.Escape(host), port),
+		"start slave",
+	} {
+		if _, err := c.exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//pick the most advanced slave which is up, return nil if none
+func (n *Node) pickFailoverSlave() (*DB, *replicaPos) {
+	n.RLock()
+	slaves := make([]*DB, len(n.Slave))
+	copy(slaves, n.Slave)
+	n.RUnlock()
+
+	var candidate *DB
+	var candidatePos *replicaPos
+	for _, db := range slaves {
+		if atomic.LoadInt32(&(db.state)) != Up {
+			continue
+		}
+
+		co, err := db.newConn()
+		if err != nil {
+			golog.Error("Node", "pickFailoverSlave", err.Error(), 0, "db.Addr", db.Addr())
+			continue
+		}
+		pos, err := co.getReplicaPos()
+		co.Close()
+		if err != nil {
+			golog.Error("Node", "pickFailoverSlave", err.Error(), 0, "db.Addr", db.Addr())
+			continue
+		}
+
+		if candidate == nil || pos.after(candidatePos) {
+			candidate = db
+			candidatePos = pos
+		}
+	}
+	return candidate, candidatePos
+}
+
+//promote the most advanced slave to master when master is down
+func (n *Node) failover() error {
+	db, pos := n.pickFailoverSlave()
+	if db == nil {
+		return errors.ErrNoSlaveDB
+	}
+
+	co, err := db.newConn()
+	if err != nil {
+		return err
+	}
+	err = co.PromoteToMaster()
+	co.Close()
+	if err != nil {
+		return err
+	}
+
+	oldMaster := n.switchMaster(db, false)
+	golog.Info("Node", "failover", "Slave promoted to master", 0,
+		"node", n.Cfg.Name,
+		"old_master", oldMaster.Addr(),
+		"new_master", db.Addr())
+
+	if n.Cfg.RepointSlaves {
+		n.repointSlaves(db, pos)
+	}
+
+	n.notifyFailover(FailoverMasterDown, oldMaster, db)
+	//the old master is removed from node
+	oldMaster.Close()
+	return nil
+}
+
+//replace the master of node by the slave db, the old master
+//becomes a slave if demote is true, the old master is returned
+func (n *Node) switchMaster(db *DB, demote bool) *DB {
+	n.Lock()
+	defer n.Unlock()
+
+	oldMaster := n.Master
+	n.Master = db
+	atomic.StoreInt32(&(db.readOnly), 0)
+
+	s := make([]*DB, 0, len(n.Slave)+1)
+	sw := make([]int, 0, len(n.Slave)+1)
+	for i, slave := range n.Slave {
+		if slave != db {
+			s = append(s, slave)
+			sw = append(sw, n.SlaveWeights[i])
+		}
+	}
+	if demote && oldMaster != nil {
+		s = append(s, oldMaster)
+		sw = append(sw, 1)
+	}
+	if len(s) == 0 {
+		n.Slave = nil
+		n.SlaveWeights = nil
+		n.RoundRobinQ = nil
+		return oldMaster
+	}
+	n.Slave = s
+	n.SlaveWeights = sw
+	n.InitBalancer()
+	return oldMaster
+}
+
+func (n *Node) notifyFailover(reason string, oldMaster, newMaster *DB) {
+	if n.OnFailover == nil {
+		return
+	}
+	n.OnFailover(&FailoverEvent{
+		Node:      n.Cfg.Name,
+		Reason:    reason,
+		OldMaster: oldMaster.Addr(),
+		NewMaster: newMaster.Addr(),
+		Time:      time.Now().Unix(),
+	})
+}
+
+//SlaveConfig return the slaves of node in the format of config,
+//such as 127.0.0.1:3306@2,192.168.0.12:3306@3
+func (n *Node) SlaveConfig() string {
+	n.RLock()
+	defer n.RUnlock()
+
+	slaves := make([]string, 0, len(n.Slave))
+	for i, db := range n.Slave {
+		slaves = append(slaves, fmt.Sprintf("%s%s%d", db.Addr(), WeightSplit, n.SlaveWeights[i]))
+	}
+	return strings.Join(slaves, SlaveSplit)
+}
+
+//make the other slaves replicate from the new master,
+//only works with gtid auto position
+func (n *Node) repointSlaves(master *DB, pos *replicaPos) {
+	if len(pos.gtid) == 0 {
+		golog.Warn("Node", "repointSlaves", "gtid is off, slaves are not repointed", 0,
+			"node", n.Cfg.Name)
+		return
+	}
+
+	n.RLock()
+	slaves := make([]*DB, len(n.Slave))
+	copy(slaves, n.Slave)
+	n.RUnlock()
+
+	for _, db := range slaves {
+		if atomic.LoadInt32(&(db.state)) != Up {
+			continue
+		}
+
+		co, err := db.newConn()
+		if err == nil {
+			err = co.RepointSlave(master.Addr())
+			co.Close()
+		}
+		if err != nil {
+			golog.Error("Node", "repointSlaves", err.Error(), 0,
+				"db.Addr", db.Addr(), "master", master.Addr())
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"testing"
+)
+
+func TestParseGtidSet(t *testing.T) {
+	cases := map[string]int64{
+		"": 0,
+		"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5":           5,
+		"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:7:9-10":    8,
+		"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:3-6:7-8":   8,
+		"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5, 4E11:1-3": 5,
+	}
+	for gtid, expect := range cases {
+		set, err := parseGtidSet(gtid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := set.count("3e11fa47-71ca-11e1-9e33-c80aa9429562"); n != expect {
+			t.Fatalf("%s: expect %d, got %d", gtid, expect, n)
+		}
+	}
+
+	if _, err := parseGtidSet("3E11FA47:a-5"); err == nil {
+		t.Fatal("expect error")
+	}
+
+	s1, _ := parseGtidSet("a:1-10,b:1-3")
+	s2, _ := parseGtidSet("a:1-5:7-10")
+	if !s1.contains(s2) || s2.contains(s1) {
+		t.Fatal("contains failed")
+	}
+}
+
+func TestReplicaPosAfter(t *testing.T) {
+	p1 := &replicaPos{gtid: "uuid:1-10", file: "mysql-bin.000002", pos: 100}
+	p2 := &replicaPos{gtid: "uuid:1-9", file: "mysql-bin.000002", pos: 200}
+	if !p1.after(p2) || p2.after(p1) {
+		t.Fatal("compare by gtid failed")
+	}
+
+	p1.gtid = ""
+	if p1.after(p2) || !p2.after(p1) {
+		t.Fatal("compare by position failed")
+	}
+
+	p2.file = "mysql-bin.000001"
+	if !p1.after(p2) {
+		t.Fatal("compare by file failed")
+	}
+
+	//p2 has more transactions, but misses one of the old master
+	p1 = &replicaPos{gtid: "master:1-10,s1:1-2", masterUUID: "master"}
+	p2 = &replicaPos{gtid: "master:1-9,s2:1-20", masterUUID: "master"}
+	if !p1.after(p2) || p2.after(p1) {
+		t.Fatal("compare by gtid of old master failed")
+	}
+
+	//the same transactions
+	p2.gtid = "master:1-10,s1:1-2"
+	if p1.after(p2) || p2.after(p1) {
+		t.Fatal("compare the same gtid failed")
+	}
+}
+
+func TestSwitchMaster(t *testing.T) {
+	n := new(Node)
+	n.Master = &DB{addr: "127.0.0.1:3306", readOnly: 1}
+	n.Slave = []*DB{{addr: "127.0.0.1:3307"}, {addr: "127.0.0.1:3308", readOnly: 1}}
+	n.SlaveWeights = []int{2, 3}
+	n.InitBalancer()
+
+	if err := n.CheckMasterWritable(); err == nil {
+		t.Fatal("read only master must not be writable")
+	}
+
+	oldMaster := n.Master
+	newMaster := n.Slave[1]
+	if n.switchMaster(newMaster, true) != oldMaster {
+		t.Fatal("old master is not returned")
+	}
+	if n.Master != newMaster || n.Master.IsReadOnly() {
+		t.Fatal("switch master failed")
+	}
+	if err := n.CheckMasterWritable(); err != nil {
+		t.Fatal(err)
+	}
+	if slaves := n.SlaveConfig(); slaves != "127.0.0.1:3307@2,127.0.0.1:3306@1" {
+		t.Fatal(slaves)
+	}
+
+	n.switchMaster(n.Slave[0], false)
+	if slaves := n.SlaveConfig(); slaves != "127.0.0.1:3306@1" {
+		t.Fatal(slaves)
+	}
+	n.switchMaster(n.Slave[0], false)
+	if n.Slave != nil || n.RoundRobinQ != nil {
+		t.Fatal("slaves should be empty")
+	}
+}
+
+//run with -race, the master is read while it is switched by failover
+func TestSwitchMasterRead(t *testing.T) {
+	n := &Node{Master: &DB{addr: "127.0.0.1:3306"}}
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			n.switchMaster(&DB{addr: "127.0.0.1:3307"}, false)
+		}
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			if n.GetMaster().Addr() != "127.0.0.1:3307" {
+				t.Fatal("master is not switched")
+			}
+			return
+		default:
+			n.CheckMasterWritable()
+			n.GetMaster()
+		}
+	}
+}
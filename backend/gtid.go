@@ -0,0 +1,49 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//GetExecutedGtid return the gtid set executed by server,
+//it is empty if gtid_mode is off
+func (c *Conn) GetExecutedGtid() (string, error) {
+	r, err := c.exec("select @@global.gtid_executed")
+	if err != nil {
+		return "", err
+	}
+	return r.GetString(0, 0)
+}
+
+//WaitExecutedGtid wait the gtid set executed by server in timeout,
+//return false if timeout
+func (c *Conn) WaitExecutedGtid(gtid string, timeout time.Duration) (bool, error) {
+	sql := fmt.Sprintf("select wait_for_executed_gtid_set('%s', %.3f)",
+		mysql.Escape(gtid), timeout.Seconds())
+	r, err := c.exec(sql)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := r.GetInt(0, 0)
+	if err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//MemTracker account the bytes of results read from backends,
+//a query has a tracker and the trackers of all queries share a parent.
+type MemTracker struct {
+	used  int64
+	peak  int64
+	limit int64 //0 means no limit
+
+	parent *MemTracker
+}
+
+func NewMemTracker(limit int64, parent *MemTracker) *MemTracker {
+	t := new(MemTracker)
+	t.limit = limit
+	t.parent = parent
+	return t
+}
+
+//Consume add n bytes into tracker, return error if over the limit
+func (t *MemTracker) Consume(n int64) error {
+	used := atomic.AddInt64(&t.used, n)
+	t.updatePeak(used)
+	if t.parent != nil {
+		t.parent.Consume(n)
+	}
+
+	if 0 < t.limit && t.limit < used {
+		return mysql.NewError(mysql.ER_QUERY_INTERRUPTED,
+			fmt.Sprintf("Query execution was interrupted, result bytes exceed max_result_bytes %d", t.limit))
+	}
+	return nil
+}
+
+func (t *MemTracker) updatePeak(used int64) {
+	for {
+		peak := atomic.LoadInt64(&t.peak)
+		if used <= peak || atomic.CompareAndSwapInt64(&t.peak, peak, used) {
+			return
+		}
+	}
+}
+
+//Release give back all the bytes of tracker to parent
+func (t *MemTracker) Release() {
+	used := atomic.SwapInt64(&t.used, 0)
+	if t.parent != nil {
+		t.parent.release(used)
+	}
+}
+
+func (t *MemTracker) release(n int64) {
+	atomic.AddInt64(&t.used, -n)
+	if t.parent != nil {
+		t.parent.release(n)
+	}
+}
+
+//Exceeded return true if the bytes over the limit
+func (t *MemTracker) Exceeded() bool {
+	return 0 < t.limit && t.limit < t.Used()
+}
+
+func (t *MemTracker) Used() int64 {
+	return atomic.LoadInt64(&t.used)
+}
+
+func (t *MemTracker) Peak() int64 {
+	return atomic.LoadInt64(&t.peak)
+}
+
+func (t *MemTracker) Limit() int64 {
+	return t.limit
+}
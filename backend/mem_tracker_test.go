@@ -0,0 +1,54 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"testing"
+)
+
+func TestMemTracker(t *testing.T) {
+	root := NewMemTracker(0, nil)
+	q1 := NewMemTracker(100, root)
+	q2 := NewMemTracker(0, root)
+
+	if err := q1.Consume(60); err != nil {
+		t.Fatal(err)
+	}
+	if err := q2.Consume(200); err != nil {
+		t.Fatal(err)
+	}
+	if root.Used() != 260 || root.Peak() != 260 {
+		t.Fatalf("root used %d, peak %d", root.Used(), root.Peak())
+	}
+
+	if err := q1.Consume(60); err == nil {
+		t.Fatal("must over the limit")
+	}
+	if !q1.Exceeded() {
+		t.Fatal("must be exceeded")
+	}
+
+	q1.Release()
+	if root.Used() != 200 || root.Peak() != 320 {
+		t.Fatalf("root used %d, peak %d", root.Used(), root.Peak())
+	}
+	q2.Release()
+	if root.Used() != 0 {
+		t.Fatalf("root used %d", root.Used())
+	}
+	if q1.Peak() != 120 {
+		t.Fatalf("q1 peak %d", q1.Peak())
+	}
+}
@@ -47,6 +47,8 @@ type Node struct {
 	DownAfterNoAlive time.Duration
 
 	Online bool
+
+	OnFailover FailoverHook
 }
 
 func (n *Node) CheckNode() {
@@ -63,8 +65,15 @@ func (n *Node) String() string {
 	return n.Cfg.Name
 }
 
+//GetMaster returns the master, it may be switched by failover
+func (n *Node) GetMaster() *DB {
+	n.RLock()
+	defer n.RUnlock()
+	return n.Master
+}
+
 func (n *Node) GetMasterConn() (*BackendConn, error) {
-	db := n.Master
+	db := n.GetMaster()
 	if db == nil {
 		return nil, errors.ErrNoMasterConn
 	}
@@ -75,6 +84,16 @@ func (n *Node) GetMasterConn() (*BackendConn, error) {
 	return db.GetConn()
 }
 
+//CheckMasterWritable returns ErrMasterReadOnly if the master is read only,
+//it is checked before writing only, the reads in master are not affected
+func (n *Node) CheckMasterWritable() error {
+	db := n.GetMaster()
+	if db != nil && db.IsReadOnly() {
+		return errors.ErrMasterReadOnly
+	}
+	return nil
+}
+
 func (n *Node) GetSlaveConn() (*BackendConn, error) {
 	n.Lock()
 	db, err := n.GetNextSlave()
@@ -94,7 +113,7 @@ func (n *Node) GetSlaveConn() (*BackendConn, error) {
 }
 
 func (n *Node) checkMaster() {
-	db := n.Master
+	db := n.GetMaster()
 	if db == nil {
 		golog.Error("Node", "checkMaster", "Master is no alive", 0)
 		return
@@ -117,6 +136,7 @@ func (n *Node) checkMaster() {
 		}
 
 		db.ClearIDLEConns()
+		n.checkMasterReadOnly(db)
 		return
 	}
 
@@ -125,6 +145,12 @@ func (n *Node) checkMaster() {
 			"db.Addr", db.Addr(),
 			"Master_down_time", int64(n.DownAfterNoAlive/time.Second))
 		n.DownMaster(db.addr, Down)
+		if n.Cfg.AutoFailover {
+			if err := n.failover(); err != nil {
+				golog.Error("Node", "checkMaster", "failover", 0,
+					"node", n.Cfg.Name, "error", err.Error())
+			}
+		}
 	}
 }
 
@@ -150,6 +176,10 @@ func (n *Node) checkSlave() {
 			if atomic.LoadInt32(&(slaves[i].state)) != ManualDown {
 				atomic.StoreInt32(&(slaves[i].state), Up)
 			}
+			n.checkSlaveLag(slaves[i])
+			if _, err := slaves[i].CheckReadOnly(); err != nil {
+				golog.Error("Node", "checkSlave", "CheckReadOnly", 0, "db.Addr", slaves[i].Addr(), "error", err.Error())
+			}
 
 			slaves[i].ClearIDLEConns()
 			continue
@@ -166,6 +196,20 @@ func (n *Node) checkSlave() {
 
 }
 
+func (n *Node) checkSlaveLag(db *DB) {
+	lag, err := db.CheckLag()
+	if err != nil {
+		golog.Error("Node", "checkSlaveLag", err.Error(), 0, "db.Addr", db.Addr())
+		return
+	}
+	if n.isSlaveLagging(db) {
+		golog.Warn("Node", "checkSlaveLag", "Slave lagging", 0,
+			"db.Addr", db.Addr(),
+			"lag", lag,
+			"max_slave_lag", n.Cfg.MaxSlaveLag)
+	}
+}
+
 func (n *Node) AddSlave(addr string) error {
 	var db *DB
 	var weight int
@@ -238,8 +282,35 @@ func (n *Node) DeleteSlave(addr string) error {
 }
 
 func (n *Node) OpenDB(addr string) (*DB, error) {
-	db, err := Open(addr, n.Cfg.User, n.Cfg.Password, "", n.Cfg.MaxConnNum, n.Cfg.InitConnNum, n.Cfg.IdleTime)
-	return db, err
+	tlsConfig, err := NewTLSConfig(n.Cfg.TLSMode, addr, n.Cfg.TLSCA, n.Cfg.TLSCert,
+		n.Cfg.TLSKey, n.Cfg.TLSServerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCompress(n.Cfg.Compress); err != nil {
+		return nil, err
+	}
+	password, err := n.Cfg.GetPassword()
+	if err != nil {
+		return nil, err
+	}
+	db, err := OpenWithOptions(addr, n.Cfg.User, password, "", n.Cfg.MaxConnNum, n.Cfg.InitConnNum,
+		n.Cfg.IdleTime, ConnOptions{
+			TLS:           tlsConfig,
+			Compress:      n.Cfg.Compress,
+			CompressLevel: n.Cfg.CompressLevel,
+			StmtCacheSize: n.Cfg.StmtCacheSize,
+		})
+	if err != nil {
+		return nil, err
+	}
+	db.SetPoolOptions(PoolOptions{
+		WaitTimeout: time.Duration(n.Cfg.PoolWaitTimeout) * time.Millisecond,
+		MaxLifetime: n.Cfg.MaxConnLifetime,
+		MinIdle:     n.Cfg.MinIdleConns,
+		MaxIdle:     n.Cfg.MaxIdleConns,
+	})
+	return db, nil
 }
 
 func (n *Node) UpDB(addr string) (*DB, error) {
@@ -264,7 +335,9 @@ func (n *Node) UpMaster(addr string) error {
 		golog.Error("Node", "UpMaster", err.Error(), 0)
 		return err
 	}
+	n.Lock()
 	n.Master = db
+	n.Unlock()
 	return err
 }
 
@@ -289,7 +362,7 @@ func (n *Node) UpSlave(addr string) error {
 }
 
 func (n *Node) DownMaster(addr string, state int32) error {
-	db := n.Master
+	db := n.GetMaster()
 	if db == nil || db.addr != addr {
 		return errors.ErrNoMasterDB
 	}
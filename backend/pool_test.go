@@ -0,0 +1,94 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/core/errors"
+)
+
+func newPoolTestDB(maxConnNum int) *DB {
+	db := new(DB)
+	db.maxConnNum = maxConnNum
+	db.idleConns = make(chan *Conn, maxConnNum)
+	db.cacheConns = make(chan *Conn, maxConnNum)
+	return db
+}
+
+func TestPoolWaitTimeout(t *testing.T) {
+	db := newPoolTestDB(1)
+	db.SetPoolOptions(PoolOptions{WaitTimeout: 10 * time.Millisecond})
+
+	_, err := db.GetConnFromIdle(db.cacheConns, db.idleConns)
+	if err != errors.ErrPoolWaitTimeout {
+		t.Fatalf("expect ErrPoolWaitTimeout, got %v", err)
+	}
+	stats := db.PoolStats()
+	if stats.WaitCount != 1 || stats.WaitTime < 10*time.Millisecond {
+		t.Fatalf("%+v", stats)
+	}
+}
+
+func TestPoolTryGetConn(t *testing.T) {
+	db := newPoolTestDB(1)
+
+	//all the conns are in use, the conn is not waited for
+	if _, err := db.TryGetConn(); err != errors.ErrPoolBusy {
+		t.Fatalf("expect ErrPoolBusy, got %v", err)
+	}
+	if stats := db.PoolStats(); stats.WaitCount != 0 || stats.InUse != 0 {
+		t.Fatalf("%+v", stats)
+	}
+}
+
+func TestPoolWaitReturned(t *testing.T) {
+	db := newPoolTestDB(1)
+	db.SetPoolOptions(PoolOptions{WaitTimeout: time.Second})
+
+	returned := &Conn{pushTimestamp: time.Now().Unix()}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.cacheConns <- returned
+	}()
+
+	co, err := db.GetConnFromIdle(db.cacheConns, db.idleConns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co != returned {
+		t.Fatal("get wrong conn")
+	}
+}
+
+func TestPoolMaxIdleAndLifetime(t *testing.T) {
+	db := newPoolTestDB(4)
+	db.SetPoolOptions(PoolOptions{MaxIdle: 1, MaxLifetime: 60})
+
+	now := time.Now().Unix()
+	db.PushConn(&Conn{createTimestamp: now}, nil)
+	db.PushConn(&Conn{createTimestamp: now}, nil)
+	if len(db.cacheConns) != 1 || len(db.idleConns) != 1 {
+		t.Fatalf("cache %d, idle %d", len(db.cacheConns), len(db.idleConns))
+	}
+
+	//expired conn is closed
+	<-db.cacheConns
+	db.PushConn(&Conn{createTimestamp: now - 60}, nil)
+	if len(db.cacheConns) != 0 || len(db.idleConns) != 2 {
+		t.Fatalf("cache %d, idle %d", len(db.cacheConns), len(db.idleConns))
+	}
+}
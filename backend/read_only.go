@@ -0,0 +1,118 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"sync/atomic"
+
+	"github.com/flike/kingshard/core/errors"
+	"github.com/flike/kingshard/core/golog"
+)
+
+//IsReadOnly return true if @@read_only or @@super_read_only of server is on
+func (c *Conn) IsReadOnly() (bool, error) {
+	r, err := c.exec("show global variables where variable_name in ('read_only', 'super_read_only')")
+	if err != nil {
+		return false, err
+	}
+
+	for i := 0; i < r.RowNumber(); i++ {
+		value, err := r.GetString(i, 1)
+		if err != nil {
+			return false, err
+		}
+		if value == "ON" || value == "1" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//CheckReadOnly check the read only of db by the check conn
+func (db *DB) CheckReadOnly() (bool, error) {
+	if db.checkConn == nil {
+		return false, errors.ErrConnIsNil
+	}
+
+	readOnly, err := db.checkConn.IsReadOnly()
+	if err != nil {
+		return false, err
+	}
+	if readOnly {
+		atomic.StoreInt32(&(db.readOnly), 1)
+	} else {
+		atomic.StoreInt32(&(db.readOnly), 0)
+	}
+	return readOnly, nil
+}
+
+func (db *DB) IsReadOnly() bool {
+	return atomic.LoadInt32(&(db.readOnly)) == 1
+}
+
+//the writes to a read only master are blocked, the writable slave
+//becomes master if auto_discover_role is on
+func (n *Node) checkMasterReadOnly(db *DB) {
+	readOnly, err := db.CheckReadOnly()
+	if err != nil {
+		golog.Error("Node", "checkMasterReadOnly", err.Error(), 0, "db.Addr", db.Addr())
+		return
+	}
+	if !readOnly {
+		return
+	}
+
+	golog.Warn("Node", "checkMasterReadOnly", "Master is read only", 0, "db.Addr", db.Addr())
+	if n.Cfg.AutoDiscoverRole {
+		n.discoverMaster()
+	}
+}
+
+//find the writable slave and make it master, the old master becomes a slave
+func (n *Node) discoverMaster() {
+	n.RLock()
+	slaves := make([]*DB, len(n.Slave))
+	copy(slaves, n.Slave)
+	n.RUnlock()
+
+	for _, db := range slaves {
+		if atomic.LoadInt32(&(db.state)) != Up {
+			continue
+		}
+
+		co, err := db.newConn()
+		if err != nil {
+			golog.Error("Node", "discoverMaster", err.Error(), 0, "db.Addr", db.Addr())
+			continue
+		}
+		readOnly, err := co.IsReadOnly()
+		co.Close()
+		if err != nil {
+			golog.Error("Node", "discoverMaster", err.Error(), 0, "db.Addr", db.Addr())
+			continue
+		}
+		if readOnly {
+			continue
+		}
+
+		oldMaster := n.switchMaster(db, true)
+		golog.Info("Node", "discoverMaster", "Writable slave becomes master", 0,
+			"node", n.Cfg.Name,
+			"old_master", oldMaster.Addr(),
+			"new_master", db.Addr())
+		n.notifyFailover(FailoverReadOnly, oldMaster, db)
+		return
+	}
+}
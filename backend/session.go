@@ -0,0 +1,87 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"strings"
+)
+
+//SessionVar is a variable set by client in session, Name is "@name" for
+//user variable or the name of session system variable, Value is literal.
+type SessionVar struct {
+	Name  string
+	Value string
+}
+
+func (v SessionVar) IsUserVar() bool {
+	return strings.HasPrefix(v.Name, "@")
+}
+
+func (v SessionVar) setExpr() string {
+	if v.IsUserVar() {
+		return v.Name + " = " + v.Value
+	}
+	return "@@session." + v.Name + " = " + v.Value
+}
+
+func (v SessionVar) resetExpr() string {
+	if v.IsUserVar() {
+		return v.Name + " = NULL"
+	}
+	return "@@session." + v.Name + " = DEFAULT"
+}
+
+//diff the variables of conn with vars, return the exprs to make them same
+func diffSessionVars(current, vars []SessionVar) []string {
+	values := make(map[string]string, len(current))
+	for _, v := range current {
+		values[v.Name] = v.Value
+	}
+
+	var exprs []string
+	names := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		names[v.Name] = true
+		if value, ok := values[v.Name]; !ok || value != v.Value {
+			exprs = append(exprs, v.setExpr())
+		}
+	}
+	for _, v := range current {
+		if !names[v.Name] {
+			exprs = append(exprs, v.resetExpr())
+		}
+	}
+	return exprs
+}
+
+//SetSessionVars make the variables of conn same as vars, only the
+//different ones are set in backend
+func (c *Conn) SetSessionVars(vars []SessionVar) error {
+	exprs := diffSessionVars(c.sessionVars, vars)
+	if len(exprs) == 0 {
+		return nil
+	}
+	if _, err := c.exec("set " + strings.Join(exprs, ", ")); err != nil {
+		return err
+	}
+
+	c.sessionVars = append([]SessionVar(nil), vars...)
+	return nil
+}
+
+//ResetSessionVars reset the variables set by clients to default
+func (c *Conn) ResetSessionVars() error {
+	return c.SetSessionVars(nil)
+}
@@ -0,0 +1,57 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSessionVars(t *testing.T) {
+	current := []SessionVar{
+		{Name: "sql_mode", Value: "'ANSI'"},
+		{Name: "@a", Value: "1"},
+		{Name: "time_zone", Value: "'+08:00'"},
+	}
+	vars := []SessionVar{
+		{Name: "sql_mode", Value: "'ANSI'"},
+		{Name: "@a", Value: "2"},
+		{Name: "@b", Value: "'b'"},
+	}
+
+	exprs := diffSessionVars(current, vars)
+	expect := []string{
+		"@a = 2",
+		"@b = 'b'",
+		"@@session.time_zone = DEFAULT",
+	}
+	if !reflect.DeepEqual(exprs, expect) {
+		t.Fatal(exprs)
+	}
+
+	if exprs := diffSessionVars(vars, vars); len(exprs) != 0 {
+		t.Fatal(exprs)
+	}
+
+	exprs = diffSessionVars(vars, nil)
+	expect = []string{
+		"@@session.sql_mode = DEFAULT",
+		"@a = NULL",
+		"@b = NULL",
+	}
+	if !reflect.DeepEqual(exprs, expect) {
+		t.Fatal(exprs)
+	}
+}
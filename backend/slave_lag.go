@@ -0,0 +1,73 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"sync/atomic"
+
+	"github.com/flike/kingshard/core/errors"
+)
+
+const (
+	//the lag of slave is unknown, the replication is stopped or broken
+	LagUnknown int64 = -1
+)
+
+//GetSlaveLag return the Seconds_Behind_Master of slave,
+//return LagUnknown if the replication is not running
+func (c *Conn) GetSlaveLag() (int64, error) {
+	r, err := c.exec("show slave status")
+	if err != nil {
+		return LagUnknown, err
+	}
+	if r.RowNumber() == 0 {
+		return LagUnknown, nil
+	}
+
+	isNull, err := r.IsNullByName(0, "Seconds_Behind_Master")
+	if err != nil || isNull {
+		return LagUnknown, err
+	}
+	return r.GetIntByName(0, "Seconds_Behind_Master")
+}
+
+//CheckLag sample the replication lag of db by the check conn
+func (db *DB) CheckLag() (int64, error) {
+	if db.checkConn == nil {
+		atomic.StoreInt64(&db.lag, LagUnknown)
+		return LagUnknown, errors.ErrConnIsNil
+	}
+
+	lag, err := db.checkConn.GetSlaveLag()
+	if err != nil {
+		lag = LagUnknown
+	}
+	atomic.StoreInt64(&db.lag, lag)
+	return lag, err
+}
+
+func (db *DB) GetLag() int64 {
+	return atomic.LoadInt64(&db.lag)
+}
+
+//the slave lagging over max_slave_lag is out of rotation,
+//0 means the lag is not checked
+func (n *Node) isSlaveLagging(db *DB) bool {
+	if n.Cfg.MaxSlaveLag <= 0 {
+		return false
+	}
+	lag := db.GetLag()
+	return lag == LagUnknown || int64(n.Cfg.MaxSlaveLag) < lag
+}
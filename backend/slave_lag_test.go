@@ -0,0 +1,59 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/core/errors"
+)
+
+func newLagTestNode(maxSlaveLag int, lags ...int64) *Node {
+	n := new(Node)
+	n.Cfg = config.NodeConfig{MaxSlaveLag: maxSlaveLag}
+	for _, lag := range lags {
+		n.Slave = append(n.Slave, &DB{addr: "slave", lag: lag})
+		n.SlaveWeights = append(n.SlaveWeights, 1)
+	}
+	n.InitBalancer()
+	return n
+}
+
+func TestGetNextSlaveSkipLagging(t *testing.T) {
+	n := newLagTestNode(10, 20, 5, LagUnknown)
+	for i := 0; i < 6; i++ {
+		db, err := n.GetNextSlave()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if db != n.Slave[1] {
+			t.Fatalf("get lagging slave %d", db.GetLag())
+		}
+	}
+}
+
+func TestGetNextSlaveAllLagging(t *testing.T) {
+	n := newLagTestNode(10, 20, LagUnknown)
+	if _, err := n.GetNextSlave(); err != errors.ErrSlaveLagging {
+		t.Fatalf("expect ErrSlaveLagging, got %v", err)
+	}
+
+	//lag is not checked
+	n = newLagTestNode(0, 20, LagUnknown)
+	if _, err := n.GetNextSlave(); err != nil {
+		t.Fatal(err)
+	}
+}
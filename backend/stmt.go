@@ -22,6 +22,11 @@ import (
 	"github.com/flike/kingshard/mysql"
 )
 
+const (
+	//the default max number of prepared statements cached in a conn
+	DefaultStmtCacheSize = 64
+)
+
 type Stmt struct {
 	conn  *Conn
 	id    uint32
@@ -176,7 +181,7 @@ func (s *Stmt) write(args ...interface{}) error {
 		}
 	}
 
-	s.conn.pkg.Sequence = 0
+	s.conn.pkg.ResetSequence()
 
 	return s.conn.writePacket(data)
 }
@@ -231,3 +236,68 @@ func (c *Conn) Prepare(query string) (*Stmt, error) {
 
 	return s, nil
 }
+
+//PrepareCached returns the statement of query prepared in the current db,
+//the statement is cached in conn and reused until evicted or reconnected,
+//so it must not be closed by the caller.
+func (c *Conn) PrepareCached(query string) (*Stmt, error) {
+	key := c.db + "\x00" + query
+	if s, ok := c.stmts[key]; ok {
+		return s, nil
+	}
+
+	s, err := c.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.stmts == nil {
+		c.stmts = make(map[string]*Stmt)
+	}
+	size := c.stmtCacheSize
+	if size <= 0 {
+		size = DefaultStmtCacheSize
+	}
+	//evict the oldest statement
+	if size <= len(c.stmtKeys) {
+		oldest := c.stmts[c.stmtKeys[0]]
+		delete(c.stmts, c.stmtKeys[0])
+		c.stmtKeys = c.stmtKeys[1:]
+		if err := oldest.Close(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	c.stmts[key] = s
+	c.stmtKeys = append(c.stmtKeys, key)
+
+	return s, nil
+}
+
+//ExecutePrepared executes query as a prepared statement even without args,
+//so the rows are always in binary protocol.
+func (c *Conn) ExecutePrepared(query string, args ...interface{}) (*mysql.Result, error) {
+	s, err := c.PrepareCached(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Execute(args...)
+}
+
+//close the cached statements in server if the conn is still usable
+func (c *Conn) closeStmtCache() {
+	if c.pkgErr == nil && !c.IsInterrupted() {
+		for _, key := range c.stmtKeys {
+			if err := c.stmts[key].Close(); err != nil {
+				break
+			}
+		}
+	}
+	c.resetStmtCache()
+}
+
+func (c *Conn) resetStmtCache() {
+	c.stmts = nil
+	c.stmtKeys = nil
+}
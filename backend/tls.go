@@ -0,0 +1,183 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+const (
+	//the tls modes of conns to backend
+	TLSDisabled       = "disabled"
+	TLSPreferred      = "preferred"
+	TLSRequired       = "required"
+	TLSVerifyIdentity = "verify_identity"
+)
+
+//TLSConfig is the tls of conns from proxy to a backend
+type TLSConfig struct {
+	Mode   string
+	Config *tls.Config
+
+	//verify the certificate of server by ca without host name
+	verifyCA bool
+}
+
+//NewTLSConfig return nil if tls is disabled. The certificate of server is
+//verified by ca if set, and the host name is verified in verify_identity mode.
+func NewTLSConfig(mode, addr, ca, cert, key, serverName string) (*TLSConfig, error) {
+	switch mode {
+	case "", TLSDisabled:
+		return nil, nil
+	case TLSPreferred, TLSRequired, TLSVerifyIdentity:
+	default:
+		return nil, fmt.Errorf("invalid tls_mode %s", mode)
+	}
+
+	t := &TLSConfig{Mode: mode, Config: new(tls.Config)}
+	if len(ca) != 0 {
+		data, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("invalid tls_ca %s", ca)
+		}
+		t.Config.RootCAs = pool
+	}
+	if len(cert) != 0 || len(key) != 0 {
+		certificate, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		t.Config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if mode == TLSVerifyIdentity {
+		t.Config.ServerName = serverName
+		if len(serverName) == 0 {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			t.Config.ServerName = host
+		}
+		return t, nil
+	}
+
+	t.Config.InsecureSkipVerify = true
+	t.verifyCA = t.Config.RootCAs != nil
+	return t, nil
+}
+
+func (t *TLSConfig) verifyPeer(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("no server certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         t.Config.RootCAs,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+//send SSLRequest and upgrade the conn to tls after reading initial handshake
+func (c *Conn) startTLS() error {
+	if c.tls == nil {
+		return nil
+	}
+	if c.capability&mysql.CLIENT_SSL == 0 {
+		if c.tls.Mode == TLSPreferred {
+			return nil
+		}
+		return fmt.Errorf("%s does not support tls", c.addr)
+	}
+
+	//capability, max packet size, charset and reserved 23[00]
+	data := make([]byte, 4+4+4+1+23)
+	binary.LittleEndian.PutUint32(data[4:], clientCapability&c.capability|mysql.CLIENT_SSL)
+	data[12] = byte(c.collation)
+	if err := c.writePacket(data); err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, c.tls.Config)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	if c.tls.verifyCA {
+		if err := c.tls.verifyPeer(tlsConn.ConnectionState()); err != nil {
+			return err
+		}
+	}
+
+	sequence := c.pkg.Sequence
+	c.conn = tlsConn
+	c.pkg = mysql.NewPacketIO(tlsConn)
+	c.pkg.Sequence = sequence
+	return nil
+}
+
+func (c *Conn) IsTLS() bool {
+	_, ok := c.conn.(*tls.Conn)
+	return ok
+}
+
+//TLSVersion return the tls version of conn, empty if not using tls
+func (c *Conn) TLSVersion() string {
+	tlsConn, ok := c.conn.(*tls.Conn)
+	if !ok {
+		return ""
+	}
+	switch tlsConn.ConnectionState().Version {
+	case tls.VersionTLS10:
+		return "TLSv1"
+	case tls.VersionTLS11:
+		return "TLSv1.1"
+	case tls.VersionTLS12:
+		return "TLSv1.2"
+	case 0x0304: //tls.VersionTLS13 of go 1.12
+		return "TLSv1.3"
+	}
+	return "unknown"
+}
+
+//TLSState return the tls state of db checked by the check conn
+func (db *DB) TLSState() string {
+	if db.opts.TLS == nil {
+		return TLSDisabled
+	}
+	co := db.checkConn
+	if co == nil {
+		return "unknown"
+	}
+	if !co.IsTLS() {
+		return "none"
+	}
+	return co.TLSVersion()
+}
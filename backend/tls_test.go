@@ -0,0 +1,153 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//write a self signed certificate and key into dir
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mysql"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := path.Join(dir, "cert.pem")
+	keyFile := path.Join(dir, "key.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := ioutil.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	if c, err := NewTLSConfig(TLSDisabled, "127.0.0.1:3306", "", "", "", ""); c != nil || err != nil {
+		t.Fatal("tls should be disabled")
+	}
+	if _, err := NewTLSConfig("on", "127.0.0.1:3306", "", "", "", ""); err == nil {
+		t.Fatal("expect invalid tls_mode")
+	}
+
+	c, err := NewTLSConfig(TLSVerifyIdentity, "127.0.0.1:3306", "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Config.ServerName != "127.0.0.1" || c.Config.InsecureSkipVerify {
+		t.Fatalf("invalid verify_identity config %+v", c.Config)
+	}
+
+	c, err = NewTLSConfig(TLSRequired, "127.0.0.1:3306", "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Config.InsecureSkipVerify || c.verifyCA {
+		t.Fatal("required mode should not verify certificate without ca")
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kingshard_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCert(t, dir)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := NewTLSConfig(TLSRequired, "127.0.0.1:3306", certFile, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tlsConfig.verifyCA {
+		t.Fatal("certificate should be verified by ca")
+	}
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	go func() {
+		pkg := mysql.NewPacketIO(serverConn)
+		pkg.Sequence = 1
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(data) != 32 {
+			t.Errorf("invalid SSLRequest length %d", len(data))
+			return
+		}
+		tlsConn := tls.Server(serverConn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		if err := tlsConn.Handshake(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	c := new(Conn)
+	c.conn = clientConn
+	c.pkg = mysql.NewPacketIO(clientConn)
+	c.pkg.Sequence = 1
+	c.capability = clientCapability | mysql.CLIENT_SSL
+	c.tls = tlsConfig
+	if err := c.startTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsTLS() || c.pkg.Sequence != 2 {
+		t.Fatal("conn is not upgraded to tls")
+	}
+}
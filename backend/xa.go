@@ -0,0 +1,102 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package backend
+
+import (
+	"fmt"
+)
+
+const (
+	//the formatID of xid generated by kingshard, "ks"
+	XAFormatID = 0x6b73
+)
+
+//the xid of a xa branch, Gtrid is the global transaction id and
+//Bqual is the branch qualifier
+type XID struct {
+	Gtrid string
+	Bqual string
+}
+
+func (x XID) String() string {
+	return fmt.Sprintf("'%s','%s',%d", x.Gtrid, x.Bqual, XAFormatID)
+}
+
+func (c *Conn) XAStart(xid XID) error {
+	_, err := c.exec("xa start " + xid.String())
+	return err
+}
+
+func (c *Conn) XAEnd(xid XID) error {
+	_, err := c.exec("xa end " + xid.String())
+	return err
+}
+
+func (c *Conn) XAPrepare(xid XID) error {
+	_, err := c.exec("xa prepare " + xid.String())
+	return err
+}
+
+func (c *Conn) XACommit(xid XID, onePhase bool) error {
+	sql := "xa commit " + xid.String()
+	if onePhase {
+		sql += " one phase"
+	}
+	_, err := c.exec(sql)
+	return err
+}
+
+func (c *Conn) XARollback(xid XID) error {
+	_, err := c.exec("xa rollback " + xid.String())
+	return err
+}
+
+//XARecover return the prepared xa branches generated by kingshard
+func (c *Conn) XARecover() ([]XID, error) {
+	r, err := c.exec("xa recover")
+	if err != nil {
+		return nil, err
+	}
+
+	var xids []XID
+	for i := 0; i < r.RowNumber(); i++ {
+		formatID, err := r.GetInt(i, 0)
+		if err != nil {
+			return nil, err
+		}
+		if formatID != XAFormatID {
+			continue
+		}
+
+		gtridLen, err := r.GetInt(i, 1)
+		if err != nil {
+			return nil, err
+		}
+		data, err := r.GetString(i, 3)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) < gtridLen {
+			return nil, fmt.Errorf("invalid xid %s", data)
+		}
+
+		xids = append(xids, XID{
+			Gtrid: data[:gtridLen],
+			Bqual: data[gtridLen:],
+		})
+	}
+
+	return xids, nil
+}
@@ -15,7 +15,10 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -44,6 +47,22 @@ type Config struct {
 	Nodes       []NodeConfig `yaml:"nodes"`
 
 	SchemaList []SchemaConfig `yaml:"schema_list"`
+
+	QueryMemBudget int64  `yaml:"query_mem_budget"`
+	SpillDir       string `yaml:"spill_dir"`
+
+	MaxParallelPerNode int `yaml:"max_parallel_per_node"`
+	MaxParallelTotal   int `yaml:"max_parallel_total"`
+
+	XALogFile       string `yaml:"xa_log_file"`
+	IncidentLogFile string `yaml:"incident_log_file"`
+
+	FailoverWebhook string `yaml:"failover_webhook"`
+
+	TLSCert    string `yaml:"tls_cert"`
+	TLSKey     string `yaml:"tls_key"`
+	TLSCA      string `yaml:"tls_ca"` //verify client certificates if set
+	RequireTLS bool   `yaml:"require_tls"`
 }
 
 //sql_monitor对应的配置
@@ -59,7 +78,12 @@ type SqlMonitorConfig struct {
 //user_list对应的配置
 type UserConfig struct {
 	User     string `yaml:"user"`
-	Password string `yaml:"password"`
+	Password string `yaml:"password"` //plaintext, or the hash of mysql_native_password or caching_sha2_password
+
+	MaxResultBytes int64 `yaml:"max_result_bytes"`
+
+	RequireX509 bool   `yaml:"require_x509"` //the client must have a certificate verified by tls_ca
+	X509CN      string `yaml:"x509_cn"`      //the common name of client certificate
 }
 
 //node节点对应的配置
@@ -69,12 +93,35 @@ type NodeConfig struct {
 	MaxConnNum       int    `yaml:"max_conns_limit"`
 	InitConnNum      int    `yaml:"init_conns_limit"`
 	IdleTime         int64  `yaml:"idle_conns_time"`
+	MaxSlaveLag      int    `yaml:"max_slave_lag"`
 
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
+	PoolWaitTimeout int   `yaml:"pool_wait_timeout"` //ms
+	MaxConnLifetime int64 `yaml:"max_conn_lifetime"` //second
+	MinIdleConns    int   `yaml:"min_idle_conns"`
+	MaxIdleConns    int   `yaml:"max_idle_conns"`
+
+	TLSMode       string `yaml:"tls_mode"` //disabled,preferred,required or verify_identity
+	TLSCA         string `yaml:"tls_ca"`
+	TLSCert       string `yaml:"tls_cert"`
+	TLSKey        string `yaml:"tls_key"`
+	TLSServerName string `yaml:"tls_server_name"`
+
+	Compress      string `yaml:"compress"`       //zlib or zstd
+	CompressLevel int    `yaml:"compress_level"` //the level of zstd, default 3
+
+	StmtCacheSize int `yaml:"stmt_cache_size"` //the prepared statements cached in a conn, default 64
+
+	User         string `yaml:"user"`
+	Password     string `yaml:"password"`
+	PasswordEnv  string `yaml:"password_env"`  //read password from the env
+	PasswordFile string `yaml:"password_file"` //read password from the secrets file
 
 	Master string `yaml:"master"`
 	Slave  string `yaml:"slave"`
+
+	AutoFailover     bool `yaml:"auto_failover"`
+	RepointSlaves    bool `yaml:"repoint_slaves"`
+	AutoDiscoverRole bool `yaml:"auto_discover_role"`
 }
 
 //schema对应的结构体
@@ -83,6 +130,14 @@ type SchemaConfig struct {
 	Nodes     []string      `yaml:"nodes"`
 	Default   string        `yaml:"default"` //default node
 	ShardRule []ShardConfig `yaml:"shard"`   //route rule
+
+	MaxResultBytes int64  `yaml:"max_result_bytes"`
+	ScatterTimeout int    `yaml:"scatter_timeout"` //ms
+	PartialResults string `yaml:"partial_results"` //fail,warn or skip
+	TransMode      string `yaml:"trans_mode"`      //xa or best_effort
+
+	ReadMasterAfterWrite int `yaml:"read_master_after_write"` //second
+	WaitGtidTimeout      int `yaml:"wait_gtid_timeout"`       //ms
 }
 
 //range,hash or date
@@ -97,6 +152,26 @@ type ShardConfig struct {
 	DateRange     []string `yaml:"date_range"`
 }
 
+//GetPassword return the password of backend, the env and secrets file
+//are preferred to the plaintext in config
+func (cfg *NodeConfig) GetPassword() (string, error) {
+	if len(cfg.PasswordEnv) != 0 {
+		password, ok := os.LookupEnv(cfg.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("password env %s of node %s is not set", cfg.PasswordEnv, cfg.Name)
+		}
+		return password, nil
+	}
+	if len(cfg.PasswordFile) != 0 {
+		data, err := ioutil.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return cfg.Password, nil
+}
+
 func ParseConfigData(data []byte) (*Config, error) {
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
@@ -26,12 +26,14 @@ var (
 	ErrNoSlaveDB     = errors.New("no slave database")
 	ErrNoDatabase    = errors.New("no database")
 
-	ErrMasterDown    = errors.New("master is down")
-	ErrSlaveDown     = errors.New("slave is down")
-	ErrDatabaseClose = errors.New("database is close")
-	ErrConnIsNil     = errors.New("connection is nil")
-	ErrBadConn       = errors.New("connection was bad")
-	ErrIgnoreSQL     = errors.New("ignore this sql")
+	ErrMasterDown     = errors.New("master is down")
+	ErrMasterReadOnly = errors.New("master is read only")
+	ErrSlaveDown      = errors.New("slave is down")
+	ErrSlaveLagging   = errors.New("all slaves are lagging")
+	ErrDatabaseClose  = errors.New("database is close")
+	ErrConnIsNil      = errors.New("connection is nil")
+	ErrBadConn        = errors.New("connection was bad")
+	ErrIgnoreSQL      = errors.New("ignore this sql")
 
 	ErrAddressNull     = errors.New("address is nil")
 	ErrInvalidArgument = errors.New("argument is invalid")
@@ -70,7 +72,10 @@ var (
 	ErrBlackSqlExist    = errors.New("black sql has exist")
 	ErrBlackSqlNotExist = errors.New("black sql has not exist")
 	ErrInsertTooComplex = errors.New("insert is too complex")
+	ErrArgNotBound      = errors.New("sharding key arg is not bound")
 	ErrSQLNULL          = errors.New("sql is null")
+	ErrPoolWaitTimeout  = errors.New("wait for connection timeout")
+	ErrPoolBusy         = errors.New("no free connection in pool")
 
 	ErrInternalServer   = errors.New("internal server error")
 )
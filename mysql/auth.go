@@ -0,0 +1,299 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	AUTH_CACHING_SHA2_PASSWORD = "caching_sha2_password"
+	AUTH_SHA256_PASSWORD       = "sha256_password"
+)
+
+const (
+	AUTH_MORE_DATA_HEADER byte = 0x01
+	AUTH_SWITCH_HEADER    byte = 0xfe
+
+	//the data in AuthMoreData of caching_sha2_password
+	CACHING_SHA2_REQUEST_PUBLIC_KEY byte = 2
+	CACHING_SHA2_FAST_AUTH_SUCCESS  byte = 3
+	CACHING_SHA2_PERFORM_FULL_AUTH  byte = 4
+
+	//the auth data of sha256_password to request public key
+	SHA256_REQUEST_PUBLIC_KEY byte = 1
+)
+
+//CalcCachingSha2Password return the scramble of caching_sha2_password,
+//XOR(SHA256(password), SHA256(SHA256(SHA256(password)), scramble))
+func CalcCachingSha2Password(scramble, password []byte) []byte {
+	if len(password) == 0 {
+		return nil
+	}
+
+	crypt := sha256.New()
+	crypt.Write(password)
+	stage1 := crypt.Sum(nil)
+
+	crypt.Reset()
+	crypt.Write(stage1)
+	stage2 := crypt.Sum(nil)
+
+	crypt.Reset()
+	crypt.Write(stage2)
+	crypt.Write(scramble)
+	token := crypt.Sum(nil)
+
+	for i := range token {
+		token[i] ^= stage1[i]
+	}
+	return token
+}
+
+//ParsePublicKey parse the rsa public key in PEM sent by server
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not rsa")
+	}
+	return rsaPub, nil
+}
+
+//EncryptPassword encrypt the password xor scramble by the public key of server,
+//it is used by the full auth of caching_sha2_password and sha256_password
+func EncryptPassword(password, scramble []byte, pub *rsa.PublicKey) ([]byte, error) {
+	plain := make([]byte, len(password)+1)
+	copy(plain, password)
+	for i := range plain {
+		plain[i] ^= scramble[i%len(scramble)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, plain, nil)
+}
+
+//DecryptPassword decrypt the password sent by client in the full auth
+func DecryptPassword(data, scramble []byte, key *rsa.PrivateKey) ([]byte, error) {
+	plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plain {
+		plain[i] ^= scramble[i%len(scramble)]
+	}
+	//remove the trailing [00]
+	if 0 < len(plain) && plain[len(plain)-1] == 0 {
+		plain = plain[:len(plain)-1]
+	}
+	return plain, nil
+}
+
+//NativePasswordHash return the hash of mysql_native_password stored in mysql.user,
+//"*" + HEX(SHA1(SHA1(password)))
+func NativePasswordHash(password []byte) string {
+	stage1 := sha1.Sum(password)
+	stage2 := sha1.Sum(stage1[:])
+	return "*" + strings.ToUpper(hex.EncodeToString(stage2[:]))
+}
+
+//ParseNativePasswordHash return SHA1(SHA1(password)) if s is a hash
+//of mysql_native_password
+func ParseNativePasswordHash(s string) ([]byte, bool) {
+	if len(s) != 1+2*sha1.Size || s[0] != '*' {
+		return nil, false
+	}
+	hash, err := hex.DecodeString(s[1:])
+	if err != nil {
+		return nil, false
+	}
+	return hash, true
+}
+
+//CheckNativePassword check the scramble of mysql_native_password by the hash,
+//SHA1(XOR(token, SHA1(scramble, hash))) must equal to hash
+func CheckNativePassword(scramble, token, hash []byte) bool {
+	if len(token) != sha1.Size {
+		return false
+	}
+	crypt := sha1.New()
+	crypt.Write(scramble)
+	crypt.Write(hash)
+	stage1 := crypt.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= token[i]
+	}
+	stage2 := sha1.Sum(stage1)
+	return bytes.Equal(stage2[:], hash)
+}
+
+//CachingSha2Hash return SHA256(SHA256(password)), which is cached by proxy
+//after the full auth of caching_sha2_password
+func CachingSha2Hash(password []byte) []byte {
+	stage1 := sha256.Sum256(password)
+	stage2 := sha256.Sum256(stage1[:])
+	return stage2[:]
+}
+
+//CheckCachingSha2Password check the scramble of caching_sha2_password by the cached hash,
+//SHA256(XOR(token, SHA256(hash, scramble))) must equal to hash
+func CheckCachingSha2Password(scramble, token, hash []byte) bool {
+	if len(token) != sha256.Size {
+		return false
+	}
+	crypt := sha256.New()
+	crypt.Write(hash)
+	crypt.Write(scramble)
+	stage1 := crypt.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= token[i]
+	}
+	stage2 := sha256.Sum256(stage1)
+	return bytes.Equal(stage2[:], hash)
+}
+
+const (
+	cachingSha2HashPrefix = "$A$"
+	cachingSha2SaltLen    = 20
+	cachingSha2DigestLen  = 43
+	cachingSha2RoundsUnit = 1000
+	cachingSha2HashLen    = len(cachingSha2HashPrefix) + 3 + 1 + cachingSha2SaltLen + cachingSha2DigestLen
+	sha256CryptItoa64     = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+//IsCachingSha2PasswordHash check whether s is a hash of caching_sha2_password
+//stored in mysql.user, "$A$" + rounds[3] + "$" + salt[20] + digest[43]
+func IsCachingSha2PasswordHash(s string) bool {
+	if len(s) != cachingSha2HashLen || !strings.HasPrefix(s, cachingSha2HashPrefix) {
+		return false
+	}
+	_, err := strconv.ParseUint(s[3:6], 16, 32)
+	return err == nil && s[6] == '$'
+}
+
+//CachingSha2PasswordHash return the hash of caching_sha2_password with 5000 rounds,
+//the salt must be 20 printable characters except '$'
+func CachingSha2PasswordHash(password, salt []byte) string {
+	digest := sha256Crypt(password, salt, 5*cachingSha2RoundsUnit)
+	return cachingSha2HashPrefix + "005$" + string(salt) + string(digest)
+}
+
+//CheckCachingSha2PasswordHash check the password by the hash of caching_sha2_password
+func CheckCachingSha2PasswordHash(password []byte, s string) bool {
+	if !IsCachingSha2PasswordHash(s) {
+		return false
+	}
+	rounds, _ := strconv.ParseUint(s[3:6], 16, 32)
+	salt := []byte(s[7 : 7+cachingSha2SaltLen])
+	digest := sha256Crypt(password, salt, int(rounds)*cachingSha2RoundsUnit)
+	return string(digest) == s[7+cachingSha2SaltLen:]
+}
+
+//repeat the hash to the length n
+func repeatHash(hash []byte, n int) []byte {
+	buf := make([]byte, 0, n)
+	for ; n > len(hash); n -= len(hash) {
+		buf = append(buf, hash...)
+	}
+	return append(buf, hash[:n]...)
+}
+
+//sha256Crypt is the SHA-256 based crypt of Ulrich Drepper without the salt
+//length limit, it is used by caching_sha2_password
+func sha256Crypt(password, salt []byte, rounds int) []byte {
+	crypt := sha256.New()
+	crypt.Write(password)
+	crypt.Write(salt)
+	crypt.Write(password)
+	b := crypt.Sum(nil)
+
+	crypt.Reset()
+	crypt.Write(password)
+	crypt.Write(salt)
+	crypt.Write(repeatHash(b, len(password)))
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			crypt.Write(b)
+		} else {
+			crypt.Write(password)
+		}
+	}
+	a := crypt.Sum(nil)
+
+	crypt.Reset()
+	for i := 0; i < len(password); i++ {
+		crypt.Write(password)
+	}
+	p := repeatHash(crypt.Sum(nil), len(password))
+
+	crypt.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		crypt.Write(salt)
+	}
+	ss := repeatHash(crypt.Sum(nil), len(salt))
+
+	c := a
+	for r := 0; r < rounds; r++ {
+		crypt.Reset()
+		if r&1 != 0 {
+			crypt.Write(p)
+		} else {
+			crypt.Write(c)
+		}
+		if r%3 != 0 {
+			crypt.Write(ss)
+		}
+		if r%7 != 0 {
+			crypt.Write(p)
+		}
+		if r&1 != 0 {
+			crypt.Write(c)
+		} else {
+			crypt.Write(p)
+		}
+		c = crypt.Sum(c[:0])
+	}
+
+	out := make([]byte, 0, cachingSha2DigestLen)
+	encode := func(b2, b1, b0 byte, n int) {
+		w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+		for ; n > 0; n-- {
+			out = append(out, sha256CryptItoa64[w&0x3f])
+			w >>= 6
+		}
+	}
+	for i := 0; i < 10; i++ {
+		//the bytes are permuted as (0,10,20),(21,1,11),(12,22,2)...
+		j := i * 21
+		encode(c[j%30], c[(j+10)%30], c[(j+20)%30], 4)
+	}
+	encode(0, c[31], c[30], 3)
+	return out
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestCalcCachingSha2Password(t *testing.T) {
+	scramble, _ := RandomBuf(20)
+	password := []byte("kingshard")
+
+	if CalcCachingSha2Password(scramble, nil) != nil {
+		t.Fatal("empty password must have empty scramble")
+	}
+	token := CalcCachingSha2Password(scramble, password)
+	if len(token) != sha256.Size {
+		t.Fatalf("invalid scramble length %d", len(token))
+	}
+
+	//mysql server checks the scramble by SHA256(SHA256(password))
+	stage1 := sha256.Sum256(password)
+	stage2 := sha256.Sum256(stage1[:])
+	h := sha256.Sum256(append(stage2[:], scramble...))
+	for i := range h {
+		h[i] ^= token[i]
+	}
+	if check := sha256.Sum256(h[:]); !bytes.Equal(check[:], stage2[:]) {
+		t.Fatal("scramble check failed")
+	}
+}
+
+func TestEncryptPassword(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePublicKey([]byte("invalid")); err == nil {
+		t.Fatal("expect error of invalid public key")
+	}
+
+	scramble, _ := RandomBuf(20)
+	enc, err := EncryptPassword([]byte("kingshard"), scramble, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, enc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range plain {
+		plain[i] ^= scramble[i%len(scramble)]
+	}
+	if string(plain) != "kingshard\x00" {
+		t.Fatalf("decrypt password %q", plain)
+	}
+}
+
+func TestSha256Crypt(t *testing.T) {
+	//the test vectors of Ulrich Drepper
+	tests := []struct {
+		password, salt string
+		rounds         int
+		digest         string
+	}{
+		{"Hello world!", "saltstring", 5000, "5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{"Hello world!", "saltstringsaltst", 10000, "3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+	}
+	for _, test := range tests {
+		digest := sha256Crypt([]byte(test.password), []byte(test.salt), test.rounds)
+		if string(digest) != test.digest {
+			t.Fatalf("sha256 crypt %s: got %s, expect %s", test.password, digest, test.digest)
+		}
+	}
+}
+
+func TestNativePasswordHash(t *testing.T) {
+	hash := NativePasswordHash([]byte("root"))
+	if hash != "*81F5E21E35407D884A6CD4A731AEBFB6AF209E1B" {
+		t.Fatalf("invalid hash %s", hash)
+	}
+	hash2, ok := ParseNativePasswordHash(hash)
+	if !ok {
+		t.Fatal("parse hash failed")
+	}
+	if _, ok := ParseNativePasswordHash("root"); ok {
+		t.Fatal("plaintext is not a hash")
+	}
+
+	scramble, _ := RandomBuf(20)
+	if !CheckNativePassword(scramble, CalcPassword(scramble, []byte("root")), hash2) {
+		t.Fatal("check password failed")
+	}
+	if CheckNativePassword(scramble, CalcPassword(scramble, []byte("kingshard")), hash2) {
+		t.Fatal("check wrong password passed")
+	}
+}
+
+func TestCachingSha2PasswordHash(t *testing.T) {
+	salt := "abcdefghijklmnopqrst"
+	hash := CachingSha2PasswordHash([]byte("kingshard"), []byte(salt))
+	if hash != "$A$005$"+salt+string(sha256Crypt([]byte("kingshard"), []byte(salt), 5000)) {
+		t.Fatalf("invalid hash %s", hash)
+	}
+	if !IsCachingSha2PasswordHash(hash) || IsCachingSha2PasswordHash("kingshard") {
+		t.Fatal("check hash format failed")
+	}
+	if !CheckCachingSha2PasswordHash([]byte("kingshard"), hash) {
+		t.Fatal("check password failed")
+	}
+	if CheckCachingSha2PasswordHash([]byte("root"), hash) {
+		t.Fatal("check wrong password passed")
+	}
+
+	scramble, _ := RandomBuf(20)
+	cached := CachingSha2Hash([]byte("kingshard"))
+	if !CheckCachingSha2Password(scramble, CalcCachingSha2Password(scramble, []byte("kingshard")), cached) {
+		t.Fatal("check scramble by cached hash failed")
+	}
+}
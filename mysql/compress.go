@@ -0,0 +1,211 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+)
+
+const (
+	COMPRESS_ZLIB = "zlib"
+	COMPRESS_ZSTD = "zstd"
+
+	//the default level of zstd used by mysql
+	DEFAULT_ZSTD_LEVEL = 3
+
+	//the payload shorter than it is sent uncompressed
+	MinCompressLength = 50
+
+	//compressed length 3, sequence 1, uncompressed length 3
+	compressHeaderLen = 7
+)
+
+//CompressCodec compress and decompress the payload of compressed packets
+type CompressCodec interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte, length int) ([]byte, error)
+}
+
+var (
+	compressCodecsLock sync.RWMutex
+	compressCodecs     = map[string]func(level int) CompressCodec{
+		COMPRESS_ZLIB: newZlibCodec,
+	}
+)
+
+//RegisterCompressCodec register the codec of a compression algorithm,
+//zlib is builtin and zstd is enabled by registering a zstd codec
+func RegisterCompressCodec(name string, newCodec func(level int) CompressCodec) {
+	compressCodecsLock.Lock()
+	defer compressCodecsLock.Unlock()
+	compressCodecs[name] = newCodec
+}
+
+//NewCompressCodec return nil if the algorithm is not registered
+func NewCompressCodec(name string, level int) CompressCodec {
+	compressCodecsLock.RLock()
+	defer compressCodecsLock.RUnlock()
+	if newCodec, ok := compressCodecs[name]; ok {
+		return newCodec(level)
+	}
+	return nil
+}
+
+//HasCompressCodec check whether the algorithm is registered
+func HasCompressCodec(name string) bool {
+	compressCodecsLock.RLock()
+	defer compressCodecsLock.RUnlock()
+	_, ok := compressCodecs[name]
+	return ok
+}
+
+type zlibCodec struct {
+	level int
+}
+
+func newZlibCodec(level int) CompressCodec {
+	if level < zlib.BestSpeed || zlib.BestCompression < level {
+		level = zlib.DefaultCompression
+	}
+	return &zlibCodec{level: level}
+}
+
+func (z *zlibCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := zlib.NewWriterLevel(&buf, z.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (z *zlibCodec) Decompress(data []byte, length int) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+//compressIO is the compressed protocol under PacketIO, the packets
+//are compressed into one or more compressed packets when written
+type compressIO struct {
+	codec CompressCodec
+	r     io.Reader
+	w     io.Writer
+
+	sequence uint8
+	buf      []byte //the decompressed payload not consumed
+}
+
+func (c *compressIO) Read(b []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if err := c.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *compressIO) readCompressedPacket() error {
+	header := make([]byte, compressHeaderLen)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return err
+	}
+
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	//the sequence is not checked, the same as mysql client
+	c.sequence = header[3] + 1
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return err
+	}
+
+	//uncompressed length 0 means the payload is not compressed
+	if uncompressedLength == 0 {
+		c.buf = data
+		return nil
+	}
+	buf, err := c.codec.Decompress(data, uncompressedLength)
+	if err != nil {
+		return err
+	}
+	c.buf = buf
+	return nil
+}
+
+func (c *compressIO) Write(data []byte) (int, error) {
+	written := 0
+	for written < len(data) {
+		n := len(data) - written
+		if MaxPayloadLen < n {
+			n = MaxPayloadLen
+		}
+		if err := c.writeCompressedPacket(data[written : written+n]); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+func (c *compressIO) writeCompressedPacket(payload []byte) error {
+	uncompressedLength := 0
+	if MinCompressLength <= len(payload) {
+		compressed, err := c.codec.Compress(payload)
+		if err != nil {
+			return err
+		}
+		//send the payload uncompressed if it is not shorter after compressed
+		if len(compressed) < len(payload) {
+			uncompressedLength = len(payload)
+			payload = compressed
+		}
+	}
+
+	length := len(payload)
+	data := make([]byte, compressHeaderLen, compressHeaderLen+length)
+	data[0] = byte(length)
+	data[1] = byte(length >> 8)
+	data[2] = byte(length >> 16)
+	data[3] = c.sequence
+	data[4] = byte(uncompressedLength)
+	data[5] = byte(uncompressedLength >> 8)
+	data[6] = byte(uncompressedLength >> 16)
+	data = append(data, payload...)
+
+	c.sequence++
+	_, err := c.w.Write(data)
+	return err
+}
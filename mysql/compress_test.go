@@ -0,0 +1,97 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestCompressedPacket(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	server := NewPacketIO(serverConn)
+	server.EnableCompression(NewCompressCodec(COMPRESS_ZLIB, 0))
+	client := NewPacketIO(clientConn)
+	client.EnableCompression(NewCompressCodec(COMPRESS_ZLIB, 0))
+
+	payloads := [][]byte{
+		[]byte("select 1"),
+		bytes.Repeat([]byte("kingshard"), 1000),
+	}
+	go func() {
+		client.ResetSequence()
+		for _, payload := range payloads {
+			if err := client.WritePacket(append(make([]byte, 4), payload...)); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for _, payload := range payloads {
+		data, err := server.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, payload) {
+			t.Fatalf("read %d bytes, expect %d bytes", len(data), len(payload))
+		}
+	}
+	if server.compress.sequence != 2 {
+		t.Fatalf("invalid compressed sequence %d", server.compress.sequence)
+	}
+}
+
+func TestCompressIOWrite(t *testing.T) {
+	var buf bytes.Buffer
+	c := &compressIO{codec: NewCompressCodec(COMPRESS_ZLIB, 0), w: &buf}
+
+	//the short payload is not compressed
+	if _, err := c.Write([]byte("short")); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{5, 0, 0, 0, 0, 0, 0, 's', 'h', 'o', 'r', 't'}) {
+		t.Fatalf("invalid uncompressed packet %v", buf.Bytes())
+	}
+
+	buf.Reset()
+	payload := bytes.Repeat([]byte{'k'}, 1024)
+	if _, err := c.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if data[3] != 1 || int(data[4])|int(data[5])<<8 != len(payload) || len(payload) <= len(data) {
+		t.Fatalf("invalid compressed packet header %v", data[:compressHeaderLen])
+	}
+
+	c.r = bytes.NewReader(data)
+	read := make([]byte, len(payload))
+	if n, err := c.Read(read); err != nil || n != len(payload) || !bytes.Equal(read, payload) {
+		t.Fatalf("read compressed packet %d %v", n, err)
+	}
+}
+
+func TestCompressCodecRegistry(t *testing.T) {
+	if NewCompressCodec("unknown", 0) != nil || HasCompressCodec("unknown") {
+		t.Fatal("unknown codec should not exist")
+	}
+	if !HasCompressCodec(COMPRESS_ZLIB) {
+		t.Fatal("zlib is builtin")
+	}
+}
@@ -101,6 +101,11 @@ const (
 	CLIENT_PLUGIN_AUTH
 	CLIENT_CONNECT_ATTRS
 	CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA
+	CLIENT_CAN_HANDLE_EXPIRED_PASSWORDS
+	CLIENT_SESSION_TRACK
+	CLIENT_DEPRECATE_EOF
+	CLIENT_OPTIONAL_RESULTSET_METADATA
+	CLIENT_ZSTD_COMPRESSION_ALGORITHM
 )
 
 //https://dev.mysql.com/doc/internals/en/com-query-response.html#packet-Protocol::ColumnType
@@ -159,6 +164,20 @@ const (
 	AUTH_NAME = "mysql_native_password"
 )
 
+//the options of COM_SET_OPTION
+const (
+	MYSQL_OPTION_MULTI_STATEMENTS_ON uint16 = iota
+	MYSQL_OPTION_MULTI_STATEMENTS_OFF
+)
+
+//the cursor flags of COM_STMT_EXECUTE
+const (
+	CURSOR_TYPE_NO_CURSOR  byte = 0x00
+	CURSOR_TYPE_READ_ONLY  byte = 0x01
+	CURSOR_TYPE_FOR_UPDATE byte = 0x02
+	CURSOR_TYPE_SCROLLABLE byte = 0x04
+)
+
 var (
 	TK_ID_INSERT   = 1
 	TK_ID_UPDATE   = 2
@@ -176,6 +195,7 @@ var (
 	TK_ID_TRANSACTION = 13
 	TK_ID_SHOW        = 14
 	TK_ID_TRUNCATE    = 15
+	TK_ID_LOAD        = 16
 
 	PARSE_TOKEN_MAP = map[string]int{
 		"insert":      TK_ID_INSERT,
@@ -193,6 +213,7 @@ var (
 		"transaction": TK_ID_TRANSACTION,
 		"show":        TK_ID_SHOW,
 		"truncate":    TK_ID_TRUNCATE,
+		"load":        TK_ID_LOAD,
 	}
 	// '*'
 	COMMENT_PREFIX uint8 = 42
@@ -203,13 +224,18 @@ var (
 	TK_STR_FROM   = "from"
 	TK_STR_INTO   = "into"
 	TK_STR_SET    = "set"
+	TK_STR_SHOW   = "show"
 
 	TK_STR_TRANSACTION    = "transaction"
 	TK_STR_LAST_INSERT_ID = "last_insert_id()"
 	TK_STR_MASTER_HINT    = "*master*"
 	//show
-	TK_STR_COLUMNS = "columns"
-	TK_STR_FIELDS  = "fields"
+	TK_STR_COLUMNS  = "columns"
+	TK_STR_FIELDS   = "fields"
+	TK_STR_WARNINGS = "warnings"
+	TK_STR_ERRORS   = "errors"
+	TK_STR_COUNT    = "count(*)"
+	TK_STR_LIMIT    = "limit"
 
 	SET_KEY_WORDS = map[string]struct{}{
 		"names": struct{}{},
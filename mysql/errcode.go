@@ -882,3 +882,13 @@ const (
 	ER_ROW_IN_WRONG_PARTITION                                                  = 1863
 	ER_ERROR_LAST                                                              = 1863
 )
+
+//the error codes of mysql 5.7
+const (
+	ER_SECURE_TRANSPORT_REQUIRED = 3159
+)
+
+//the error codes of kingshard
+const (
+	ER_PARTIAL_COMMIT = 9001
+)
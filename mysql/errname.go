@@ -879,4 +879,10 @@ var MySQLErrName = map[uint16]string{
 	ER_ALTER_OPERATION_NOT_SUPPORTED_REASON_NOT_NULL:                    "cannot silently convert NULL values, as required in this SQL_MODE",
 	ER_MUST_CHANGE_PASSWORD_LOGIN:                                       "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ER_ROW_IN_WRONG_PARTITION:                                           "Found a row in wrong partition %s",
+
+	//the error names of mysql 5.7
+	ER_SECURE_TRANSPORT_REQUIRED: "Connections using insecure transport are prohibited while --require_secure_transport=ON.",
+
+	//the error names of kingshard
+	ER_PARTIAL_COMMIT: "Transaction %s is partially committed, failed nodes: %s",
 }
@@ -29,6 +29,8 @@ type PacketIO struct {
 	rb *bufio.Reader
 	wb io.Writer
 
+	compress *compressIO //not nil if the compressed protocol is used
+
 	Sequence uint8
 }
 
@@ -43,7 +45,42 @@ func NewPacketIO(conn net.Conn) *PacketIO {
 	return p
 }
 
+//Buffered return the bytes read from conn but not consumed by packets
+func (p *PacketIO) Buffered() []byte {
+	b, _ := p.rb.Peek(p.rb.Buffered())
+	return b
+}
+
+//EnableCompression switch to the compressed protocol, it is called after the handshake
+func (p *PacketIO) EnableCompression(codec CompressCodec) {
+	p.compress = &compressIO{codec: codec, r: p.rb, w: p.wb}
+	p.rb = bufio.NewReaderSize(p.compress, defaultReaderSize)
+	p.wb = p.compress
+}
+
+func (p *PacketIO) IsCompressed() bool {
+	return p.compress != nil
+}
+
+//ResetSequence reset the sequences of packets and compressed packets for a new command
+func (p *PacketIO) ResetSequence() {
+	p.Sequence = 0
+	if p.compress != nil {
+		p.compress.sequence = 0
+	}
+}
+
 func (p *PacketIO) ReadPacket() ([]byte, error) {
+	return p.readPacket(false)
+}
+
+//ReadFilePacket reads a packet of the file sent by client for LOAD DATA
+//LOCAL INFILE, the empty packet means the end of file.
+func (p *PacketIO) ReadFilePacket() ([]byte, error) {
+	return p.readPacket(true)
+}
+
+func (p *PacketIO) readPacket(allowEmpty bool) ([]byte, error) {
 	header := []byte{0, 0, 0, 0}
 
 	if _, err := io.ReadFull(p.rb, header); err != nil {
@@ -51,14 +88,18 @@ func (p *PacketIO) ReadPacket() ([]byte, error) {
 	}
 
 	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
-	if length < 1 {
+	if length < 1 && !allowEmpty {
 		return nil, fmt.Errorf("invalid payload length %d", length)
 	}
 
 	sequence := uint8(header[3])
 
 	if sequence != p.Sequence {
-		return nil, fmt.Errorf("invalid sequence %d != %d", sequence, p.Sequence)
+		//the sequence in compressed packets is not checked, the same as mysql client
+		if p.compress == nil {
+			return nil, fmt.Errorf("invalid sequence %d != %d", sequence, p.Sequence)
+		}
+		p.Sequence = sequence
 	}
 
 	p.Sequence++
@@ -71,8 +112,9 @@ func (p *PacketIO) ReadPacket() ([]byte, error) {
 			return data, nil
 		}
 
+		//the payload of max length is followed by a packet, which may be empty
 		var buf []byte
-		buf, err = p.ReadPacket()
+		buf, err = p.readPacket(true)
 		if err != nil {
 			return nil, ErrBadConn
 		} else {
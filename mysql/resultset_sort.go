@@ -48,12 +48,8 @@ func newResultsetSorter(r *Resultset, sk []SortKey) (*resultsetSorter, error) {
 
 	s.Resultset = r
 
-	for i, k := range sk {
-		if column, ok := r.FieldNames[k.Name]; ok {
-			sk[i].column = column
-		} else {
-			return nil, fmt.Errorf("key %s not in resultset fields, can not sort", k.Name)
-		}
+	if err := resolveSortKeys(r.FieldNames, sk); err != nil {
+		return nil, err
 	}
 
 	s.sk = sk
@@ -61,15 +57,28 @@ func newResultsetSorter(r *Resultset, sk []SortKey) (*resultsetSorter, error) {
 	return s, nil
 }
 
+//set the column index of every sort key
+func resolveSortKeys(fieldNames map[string]int, sk []SortKey) error {
+	for i, k := range sk {
+		if column, ok := fieldNames[k.Name]; ok {
+			sk[i].column = column
+		} else {
+			return fmt.Errorf("key %s not in resultset fields, can not sort", k.Name)
+		}
+	}
+	return nil
+}
+
 func (r *resultsetSorter) Len() int {
 	return r.RowNumber()
 }
 
 func (r *resultsetSorter) Less(i, j int) bool {
-	v1 := r.Values[i]
-	v2 := r.Values[j]
+	return lessRow(r.sk, r.Values[i], r.Values[j])
+}
 
-	for _, k := range r.sk {
+func lessRow(sk []SortKey, v1 []interface{}, v2 []interface{}) bool {
+	for _, k := range sk {
 		v := cmpValue(v1[k.column], v2[k.column])
 
 		if k.Direction == SortDesc {
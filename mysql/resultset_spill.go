@@ -0,0 +1,594 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+)
+
+const (
+	spillFilePrefix = "kingshard-spill-"
+	spillBufferSize = 64 * 1024
+
+	//the tag of value type in spill file
+	spillNull   byte = 0
+	spillInt    byte = 1
+	spillUint   byte = 2
+	spillFloat  byte = 3
+	spillBytes  byte = 4
+	spillString byte = 5
+)
+
+//RowMemSize estimate the memory used by one row of resultset
+func RowMemSize(value []interface{}, row RowData) int64 {
+	//slice header of value and row
+	size := int64(48 + len(row))
+	for _, v := range value {
+		//interface header
+		size += 16
+		switch x := v.(type) {
+		case []byte:
+			size += int64(24 + len(x))
+		case string:
+			size += int64(16 + len(x))
+		case nil:
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+//MemSize estimate the memory used by the rows of resultset
+func (r *Resultset) MemSize() int64 {
+	var size int64
+	for i := range r.Values {
+		var row RowData
+		if i < len(r.RowDatas) {
+			row = r.RowDatas[i]
+		}
+		size += RowMemSize(r.Values[i], row)
+	}
+	return size
+}
+
+//spillFile is a temp file which store rows of resultset,
+//the file is removed when closed
+type spillFile struct {
+	f    *os.File
+	w    *bufio.Writer
+	r    *bufio.Reader
+	rows int
+}
+
+func newSpillFile(dir string) (*spillFile, error) {
+	f, err := ioutil.TempFile(dir, spillFilePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(spillFile)
+	s.f = f
+	s.w = bufio.NewWriterSize(f, spillBufferSize)
+	return s, nil
+}
+
+func (s *spillFile) writeRow(value []interface{}, row RowData) error {
+	buf := make([]byte, 0, len(row)+8*len(value)+16)
+	buf = append(buf, PutLengthEncodedInt(uint64(len(row)))...)
+	buf = append(buf, row...)
+	buf = append(buf, PutLengthEncodedInt(uint64(len(value)))...)
+
+	for _, v := range value {
+		switch x := v.(type) {
+		case nil:
+			buf = append(buf, spillNull)
+		case int64:
+			buf = append(buf, spillInt)
+			buf = append(buf, Uint64ToBytes(uint64(x))...)
+		case uint64:
+			buf = append(buf, spillUint)
+			buf = append(buf, Uint64ToBytes(x)...)
+		case float64:
+			buf = append(buf, spillFloat)
+			buf = append(buf, Uint64ToBytes(math.Float64bits(x))...)
+		case []byte:
+			buf = append(buf, spillBytes)
+			buf = append(buf, PutLengthEncodedString(x)...)
+		case string:
+			buf = append(buf, spillString)
+			buf = append(buf, PutLengthEncodedString([]byte(x))...)
+		default:
+			return fmt.Errorf("can not spill value type %T", v)
+		}
+	}
+
+	if _, err := s.w.Write(buf); err != nil {
+		return err
+	}
+	s.rows++
+	return nil
+}
+
+//finish writing and prepare to read rows from the beginning
+func (s *spillFile) rewind() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.f.Seek(0, os.SEEK_SET); err != nil {
+		return err
+	}
+	s.r = bufio.NewReaderSize(s.f, spillBufferSize)
+	return nil
+}
+
+func (s *spillFile) readLength() (uint64, error) {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	switch b {
+	case 0xfc:
+		n = 2
+	case 0xfd:
+		n = 3
+	case 0xfe:
+		n = 8
+	default:
+		return uint64(b), nil
+	}
+
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(s.r, buf[:n]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func (s *spillFile) readBytes() ([]byte, error) {
+	n, err := s.readLength()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(s.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+//read the next row, return io.EOF if no more rows
+func (s *spillFile) readRow() ([]interface{}, RowData, error) {
+	row, err := s.readBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	count, err := s.readLength()
+	if err != nil {
+		return nil, nil, ErrMalformPacket
+	}
+
+	value := make([]interface{}, count)
+	buf := make([]byte, 8)
+	for i := range value {
+		tag, err := s.r.ReadByte()
+		if err != nil {
+			return nil, nil, ErrMalformPacket
+		}
+
+		switch tag {
+		case spillNull:
+			value[i] = nil
+		case spillInt, spillUint, spillFloat:
+			if _, err := io.ReadFull(s.r, buf); err != nil {
+				return nil, nil, ErrMalformPacket
+			}
+			n := binary.LittleEndian.Uint64(buf)
+			if tag == spillInt {
+				value[i] = int64(n)
+			} else if tag == spillUint {
+				value[i] = n
+			} else {
+				value[i] = math.Float64frombits(n)
+			}
+		case spillBytes, spillString:
+			b, err := s.readBytes()
+			if err != nil {
+				return nil, nil, ErrMalformPacket
+			}
+			if tag == spillBytes {
+				value[i] = b
+			} else {
+				value[i] = string(b)
+			}
+		default:
+			return nil, nil, ErrMalformPacket
+		}
+	}
+
+	return value, RowData(row), nil
+}
+
+func (s *spillFile) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	name := s.f.Name()
+	s.f.Close()
+	s.f = nil
+	return os.Remove(name)
+}
+
+//RowSorter sort rows in memory until the memory budget is exceeded,
+//then the sorted rows are spilled into a temp file as a run. All the
+//runs are merged when reading rows.
+type RowSorter struct {
+	sk     []SortKey
+	dir    string
+	budget int64
+
+	values   [][]interface{}
+	rowDatas []RowData
+	memSize  int64
+
+	runs []*spillFile
+}
+
+//NewRowSorter create a sorter, the column of sort key is found in fieldNames,
+//budget is the max bytes of rows kept in memory, 0 means no limit.
+func NewRowSorter(fieldNames map[string]int, sk []SortKey, budget int64, dir string) (*RowSorter, error) {
+	if err := resolveSortKeys(fieldNames, sk); err != nil {
+		return nil, err
+	}
+
+	s := new(RowSorter)
+	s.sk = sk
+	s.dir = dir
+	s.budget = budget
+	return s, nil
+}
+
+func (s *RowSorter) Len() int {
+	return len(s.values)
+}
+
+func (s *RowSorter) Less(i, j int) bool {
+	return lessRow(s.sk, s.values[i], s.values[j])
+}
+
+func (s *RowSorter) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.rowDatas[i], s.rowDatas[j] = s.rowDatas[j], s.rowDatas[i]
+}
+
+//Add append a row into sorter, the rows in memory are spilled first
+//if the row makes them over the budget
+func (s *RowSorter) Add(value []interface{}, row RowData) error {
+	size := RowMemSize(value, row)
+	if 0 < s.budget && 0 < len(s.values) && s.budget < s.memSize+size {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+
+	s.values = append(s.values, value)
+	s.rowDatas = append(s.rowDatas, row)
+	s.memSize += size
+	return nil
+}
+
+//MemSize return the bytes of rows kept in memory
+func (s *RowSorter) MemSize() int64 {
+	return s.memSize
+}
+
+//SpillCount return the number of runs spilled into temp files
+func (s *RowSorter) SpillCount() int {
+	return len(s.runs)
+}
+
+//sort rows in memory and write them into a new run
+func (s *RowSorter) spill() error {
+	sort.Sort(s)
+
+	run, err := newSpillFile(s.dir)
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+
+	for i := range s.values {
+		if err := run.writeRow(s.values[i], s.rowDatas[i]); err != nil {
+			return err
+		}
+	}
+
+	s.values = nil
+	s.rowDatas = nil
+	s.memSize = 0
+	return nil
+}
+
+//Iterator return a RowIterator which read all the rows in order,
+//no row can be added after Iterator called.
+func (s *RowSorter) Iterator() (*RowIterator, error) {
+	sort.Sort(s)
+
+	it := new(RowIterator)
+	it.sk = s.sk
+
+	for _, run := range s.runs {
+		if err := run.rewind(); err != nil {
+			return nil, err
+		}
+		it.sources = append(it.sources, &runSource{run: run})
+	}
+	if 0 < len(s.values) {
+		it.sources = append(it.sources, &runSource{values: s.values, rowDatas: s.rowDatas})
+	}
+	s.values = nil
+	s.rowDatas = nil
+
+	for _, src := range it.sources {
+		if err := src.next(); err != nil {
+			return nil, err
+		}
+		if !src.eof {
+			it.h = append(it.h, src)
+		}
+	}
+	heap.Init(it)
+
+	return it, nil
+}
+
+//Close remove all the temp files
+func (s *RowSorter) Close() error {
+	var err error
+	for _, run := range s.runs {
+		if e := run.Close(); e != nil {
+			err = e
+		}
+	}
+	s.runs = nil
+	s.values = nil
+	s.rowDatas = nil
+	return err
+}
+
+//runSource is a sorted run in temp file or in memory
+type runSource struct {
+	run *spillFile
+
+	values   [][]interface{}
+	rowDatas []RowData
+	pos      int
+
+	value []interface{}
+	row   RowData
+	eof   bool
+}
+
+func (src *runSource) next() error {
+	if src.run == nil {
+		if src.pos < len(src.values) {
+			src.value = src.values[src.pos]
+			src.row = src.rowDatas[src.pos]
+			src.values[src.pos] = nil
+			src.rowDatas[src.pos] = nil
+			src.pos++
+		} else {
+			src.eof = true
+		}
+		return nil
+	}
+
+	value, row, err := src.run.readRow()
+	if err == io.EOF {
+		src.eof = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+	src.value = value
+	src.row = row
+	return nil
+}
+
+//RowIterator merge the sorted runs of RowSorter
+type RowIterator struct {
+	sk      []SortKey
+	sources []*runSource
+	h       []*runSource
+}
+
+func (it *RowIterator) Len() int {
+	return len(it.h)
+}
+
+func (it *RowIterator) Less(i, j int) bool {
+	return lessRow(it.sk, it.h[i].value, it.h[j].value)
+}
+
+func (it *RowIterator) Swap(i, j int) {
+	it.h[i], it.h[j] = it.h[j], it.h[i]
+}
+
+func (it *RowIterator) Push(x interface{}) {
+	it.h = append(it.h, x.(*runSource))
+}
+
+func (it *RowIterator) Pop() interface{} {
+	n := len(it.h)
+	x := it.h[n-1]
+	it.h = it.h[:n-1]
+	return x
+}
+
+//Next return the next row in order, return io.EOF if no more rows
+func (it *RowIterator) Next() ([]interface{}, RowData, error) {
+	if len(it.h) == 0 {
+		return nil, nil, io.EOF
+	}
+
+	src := it.h[0]
+	value, row := src.value, src.row
+	if err := src.next(); err != nil {
+		return nil, nil, err
+	}
+	if src.eof {
+		heap.Pop(it)
+	} else {
+		heap.Fix(it, 0)
+	}
+
+	return value, row, nil
+}
+
+//RowPartitioner split rows into temp files by the hash of group key,
+//so every partition can be grouped in memory one by one.
+type RowPartitioner struct {
+	parts []*spillFile
+	sizes []int64 //the memory size of rows in partitions
+	level int     //the partitioner split from a partition has a higher level
+}
+
+func NewRowPartitioner(count int, dir string) (*RowPartitioner, error) {
+	if count < 1 {
+		count = 1
+	}
+
+	p := new(RowPartitioner)
+	p.parts = make([]*spillFile, 0, count)
+	p.sizes = make([]int64, count)
+	for i := 0; i < count; i++ {
+		f, err := newSpillFile(dir)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.parts = append(p.parts, f)
+	}
+	return p, nil
+}
+
+//Add write a row into the partition of key
+func (p *RowPartitioner) Add(key string, value []interface{}, row RowData) error {
+	h := fnv.New32a()
+	//the rows of a partition have the same hash at its level, so the
+	//hash of sub partitions must be different
+	if 0 < p.level {
+		h.Write([]byte{byte(p.level)})
+	}
+	h.Write([]byte(key))
+	index := int(h.Sum32() % uint32(len(p.parts)))
+
+	if err := p.parts[index].writeRow(value, row); err != nil {
+		return err
+	}
+	p.sizes[index] += RowMemSize(value, row)
+	return nil
+}
+
+func (p *RowPartitioner) Count() int {
+	return len(p.parts)
+}
+
+//Size return the memory size of rows in the partition index
+func (p *RowPartitioner) Size(index int) int64 {
+	return p.sizes[index]
+}
+
+//Split move the rows of partition index into a new partitioner of count
+//partitions, key returns the group key of row
+func (p *RowPartitioner) Split(index int, count int, dir string,
+	key func(value []interface{}) (string, error)) (*RowPartitioner, error) {
+	sub, err := NewRowPartitioner(count, dir)
+	if err != nil {
+		return nil, err
+	}
+	sub.level = p.level + 1
+
+	part := p.parts[index]
+	if err := part.rewind(); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	for {
+		value, row, err := part.readRow()
+		if err == io.EOF {
+			return sub, nil
+		} else if err != nil {
+			sub.Close()
+			return nil, err
+		}
+
+		k, err := key(value)
+		if err == nil {
+			err = sub.Add(k, value, row)
+		}
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+	}
+}
+
+//Partition read all the rows of the partition index into memory
+func (p *RowPartitioner) Partition(index int) ([][]interface{}, []RowData, error) {
+	part := p.parts[index]
+	if err := part.rewind(); err != nil {
+		return nil, nil, err
+	}
+
+	values := make([][]interface{}, 0, part.rows)
+	rowDatas := make([]RowData, 0, part.rows)
+	for {
+		value, row, err := part.readRow()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+		rowDatas = append(rowDatas, row)
+	}
+
+	return values, rowDatas, nil
+}
+
+//Close remove all the temp files
+func (p *RowPartitioner) Close() error {
+	var err error
+	for _, part := range p.parts {
+		if e := part.Close(); e != nil {
+			err = e
+		}
+	}
+	p.parts = nil
+	return err
+}
@@ -0,0 +1,218 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRowSorterSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fieldNames := map[string]int{"id": 0, "name": 1}
+	sk := []SortKey{SortKey{Name: "id", Direction: SortDesc}}
+
+	//spill every few rows
+	s, err := NewRowSorter(fieldNames, sk, 256, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int64{5, 3, 9, 1, 7, 2, 8, 6, 4, 0}
+	for _, id := range ids {
+		name := fmt.Sprintf("name%d", id)
+		value := []interface{}{id, name, nil, uint64(id), float64(id), []byte(name)}
+		if err := s.Add(value, RowData(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.SpillCount() == 0 {
+		t.Fatal("rows not spilled")
+	}
+
+	it, err := s.Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := int64(9); id >= 0; id-- {
+		value, row, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := fmt.Sprintf("name%d", id)
+		expect := []interface{}{id, name, nil, uint64(id), float64(id), []byte(name)}
+		if !reflect.DeepEqual(value, expect) {
+			t.Fatalf("value %v != %v", value, expect)
+		}
+		if string(row) != name {
+			t.Fatalf("row %s != %s", row, name)
+		}
+	}
+	if _, _, err := it.Next(); err != io.EOF {
+		t.Fatal(err)
+	}
+
+	s.Close()
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("temp files not removed: %d", len(files))
+	}
+}
+
+func TestRowPartitioner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := NewRowPartitioner(4, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i%10)
+		value := []interface{}{int64(i), key}
+		if err := p.Add(key, value, RowData(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	total := 0
+	for i := 0; i < p.Count(); i++ {
+		values, rows, err := p.Partition(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != len(rows) {
+			t.Fatalf("values %d != rows %d", len(values), len(rows))
+		}
+
+		//the same key must be in the same partition
+		for j := range values {
+			key := values[j][1].(string)
+			if string(rows[j]) != key {
+				t.Fatalf("row %s != %s", rows[j], key)
+			}
+			for k := 0; k < p.Count(); k++ {
+				if k == i {
+					continue
+				}
+				others, _, _ := p.Partition(k)
+				for _, v := range others {
+					if v[1].(string) == key {
+						t.Fatalf("key %s in partition %d and %d", key, i, k)
+					}
+				}
+			}
+		}
+		total += len(values)
+	}
+	if total != 100 {
+		t.Fatalf("total rows %d != 100", total)
+	}
+
+	p.Close()
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("temp files not removed: %d", len(files))
+	}
+}
+
+func TestRowPartitionerSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := NewRowPartitioner(4, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	var size int64
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i%20)
+		value := []interface{}{int64(i), key}
+		if err := p.Add(key, value, RowData(key)); err != nil {
+			t.Fatal(err)
+		}
+		size += RowMemSize(value, RowData(key))
+	}
+	var total int64
+	for i := 0; i < p.Count(); i++ {
+		total += p.Size(i)
+	}
+	if total != size {
+		t.Fatalf("size %d != %d", total, size)
+	}
+
+	//split the largest partition
+	index := 0
+	for i := 1; i < p.Count(); i++ {
+		if p.Size(index) < p.Size(i) {
+			index = i
+		}
+	}
+	rows, _, err := p.Partition(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := p.Split(index, 4, dir, func(value []interface{}) (string, error) {
+		return value[1].(string), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	count, parts := 0, 0
+	keys := make(map[string]int)
+	for i := 0; i < sub.Count(); i++ {
+		values, _, err := sub.Partition(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if 0 < len(values) {
+			parts++
+		}
+		for _, v := range values {
+			key := v[1].(string)
+			if k, ok := keys[key]; ok && k != i {
+				t.Fatalf("key %s in partition %d and %d", key, k, i)
+			}
+			keys[key] = i
+		}
+		count += len(values)
+	}
+	if count != len(rows) {
+		t.Fatalf("rows %d != %d", count, len(rows))
+	}
+	//the rows have the same hash in parent are spread in sub partitions
+	if parts < 2 {
+		t.Fatalf("rows of %d keys in %d sub partitions", len(keys), parts)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"io"
 	"math/rand"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
@@ -245,15 +247,15 @@ func FormatBinaryTime(n int, data []byte) ([]byte, error) {
 		return []byte("0000-00-00"), nil
 	}
 
-	var sign byte
+	var sign string
 	if data[0] == 1 {
-		sign = byte('-')
+		sign = "-"
 	}
 
 	switch n {
 	case 8:
 		return []byte(fmt.Sprintf(
-			"%c%02d:%02d:%02d",
+			"%s%02d:%02d:%02d",
 			sign,
 			uint16(data[1])*24+uint16(data[5]),
 			data[6],
@@ -261,7 +263,7 @@ func FormatBinaryTime(n int, data []byte) ([]byte, error) {
 		)), nil
 	case 12:
 		return []byte(fmt.Sprintf(
-			"%c%02d:%02d:%02d.%06d",
+			"%s%02d:%02d:%02d.%06d",
 			sign,
 			uint16(data[1])*24+uint16(data[5]),
 			data[6],
@@ -273,6 +275,129 @@ func FormatBinaryTime(n int, data []byte) ([]byte, error) {
 	}
 }
 
+//ParseBinaryDateTime converts the date or datetime formatted by
+//FormatBinaryDate or FormatBinaryDateTime back into binary protocol,
+//the length is included.
+func ParseBinaryDateTime(value []byte) ([]byte, error) {
+	var year, month, day, hour, minute, second, micro int
+	var err error
+
+	s := string(value)
+	switch {
+	case len(s) == 10:
+		_, err = fmt.Sscanf(s, "%4d-%2d-%2d", &year, &month, &day)
+	case len(s) == 19:
+		_, err = fmt.Sscanf(s, "%4d-%2d-%2d %2d:%2d:%2d",
+			&year, &month, &day, &hour, &minute, &second)
+	case 20 < len(s) && s[19] == '.':
+		_, err = fmt.Sscanf(s[:19], "%4d-%2d-%2d %2d:%2d:%2d",
+			&year, &month, &day, &hour, &minute, &second)
+		if err == nil {
+			micro, err = parseMicrosecond(s[20:])
+		}
+	default:
+		err = fmt.Errorf("invalid datetime %s", s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint16(data[1:3], uint16(year))
+	data[3] = byte(month)
+	data[4] = byte(day)
+	data[5] = byte(hour)
+	data[6] = byte(minute)
+	data[7] = byte(second)
+	binary.LittleEndian.PutUint32(data[8:12], uint32(micro))
+
+	switch {
+	case micro != 0:
+		data[0] = 11
+		return data, nil
+	case hour != 0 || minute != 0 || second != 0:
+		data[0] = 7
+		return data[:8], nil
+	case year != 0 || month != 0 || day != 0:
+		data[0] = 4
+		return data[:5], nil
+	default:
+		return []byte{0}, nil
+	}
+}
+
+//ParseBinaryTime converts the time formatted by FormatBinaryTime back
+//into binary protocol, the length is included.
+func ParseBinaryTime(value []byte) ([]byte, error) {
+	var hour, minute, second, micro int
+	var err error
+
+	s := string(value)
+	if s == "0000-00-00" {
+		return []byte{0}, nil
+	}
+
+	var neg byte
+	if strings.HasPrefix(s, "-") {
+		neg = 1
+		s = s[1:]
+	}
+	if i := strings.IndexByte(s, '.'); 0 <= i {
+		if micro, err = parseMicrosecond(s[i+1:]); err != nil {
+			return nil, err
+		}
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid time %s", value)
+	}
+	if hour, err = strconv.Atoi(parts[0]); err != nil {
+		return nil, err
+	}
+	if minute, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, err
+	}
+	if second, err = strconv.Atoi(parts[2]); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 13)
+	data[1] = neg
+	binary.LittleEndian.PutUint32(data[2:6], uint32(hour/24))
+	data[6] = byte(hour % 24)
+	data[7] = byte(minute)
+	data[8] = byte(second)
+	binary.LittleEndian.PutUint32(data[9:13], uint32(micro))
+
+	switch {
+	case micro != 0:
+		data[0] = 12
+		return data, nil
+	case hour != 0 || minute != 0 || second != 0:
+		data[0] = 8
+		return data[:9], nil
+	default:
+		return []byte{0}, nil
+	}
+}
+
+//parse the fractional part of seconds into microseconds
+func parseMicrosecond(frac string) (int, error) {
+	if 6 < len(frac) {
+		frac = frac[:6]
+	}
+	micro, err := strconv.Atoi(frac)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(frac); i < 6; i++ {
+		micro *= 10
+	}
+	return micro, nil
+}
+
 var (
 	DONTESCAPE = byte(255)
 
@@ -49,3 +49,52 @@ func TestCalcPassword(t *testing.T) {
 	hex_scramble := hex.EncodeToString(scramble)
 	t.Logf("scramble: %s equal %s, pass: %v", "fbc71db5ac3d7b51048d1a1d88c1677f34bcca11", hex_scramble, "fbc71db5ac3d7b51048d1a1d88c1677f34bcca11" == hex_scramble)
 }
+
+func TestParseBinaryDateTime(t *testing.T) {
+	tests := []string{
+		"0000-00-00 00:00:00",
+		"2016-08-02",
+		"2016-08-02 13:37:26",
+		"2016-08-02 13:37:26.000120",
+	}
+	for i, s := range tests {
+		data, err := ParseBinaryDateTime([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []byte
+		if i == 1 {
+			v, err = FormatBinaryDate(int(data[0]), data[1:])
+		} else {
+			v, err = FormatBinaryDateTime(int(data[0]), data[1:])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(v) != s {
+			t.Fatalf("expect %s, got %s", s, v)
+		}
+	}
+}
+
+func TestParseBinaryTime(t *testing.T) {
+	tests := []string{
+		"0000-00-00",
+		"13:37:26",
+		"-38:00:01",
+		"01:02:03.500000",
+	}
+	for _, s := range tests {
+		data, err := ParseBinaryTime([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := FormatBinaryTime(int(data[0]), data[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(v) != s {
+			t.Fatalf("expect %s, got %s", s, v)
+		}
+	}
+}
@@ -46,6 +46,14 @@ type Plan struct {
 	RouteTableIndexs    []int
 	RouteNodeIndexs     []int
 	RewrittenSqls       map[string][]string
+	RewrittenTables     map[string][]string //the sub table of every sql in RewrittenSqls
+
+	//the args of prepared statement, the positional arg :vN is bound to
+	//Args[N-1] when routing
+	Args []interface{}
+	//the indexes in Args of the '?' in every sql in RewrittenSqls,
+	//only set for prepared statement
+	RewrittenArgs map[string][][]int
 }
 
 func (plan *Plan) rewriteWhereIn(tableIndex int) (sqlparser.ValExpr, error) {
@@ -493,6 +501,11 @@ func (plan *Plan) GetIRKeyIndex(cols sqlparser.Columns) error {
 }
 
 func (plan *Plan) getTableIndexByValue(valExpr sqlparser.ValExpr) (int, error) {
+	if arg, ok := valExpr.(sqlparser.ValArg); ok {
+		if _, err := plan.getArgValue(arg); err != nil {
+			return -1, err
+		}
+	}
 	value := plan.getBoundValue(valExpr)
 	return plan.Rule.FindTableIndex(value)
 }
@@ -532,11 +545,50 @@ func (plan *Plan) getBoundValue(valExpr sqlparser.ValExpr) interface{} {
 		}
 		return val
 	case sqlparser.ValArg:
-		panic("Unexpected token")
+		value, err := plan.getArgValue(node)
+		if err != nil {
+			panic(sqlparser.NewParserError("%s", err.Error()))
+		}
+		return value
 	}
 	panic("Unexpected token")
 }
 
+//get the value bound to the positional arg :vN
+func (plan *Plan) getArgValue(arg sqlparser.ValArg) (interface{}, error) {
+	if len(arg) < 3 || arg[1] != 'v' {
+		return nil, errors.ErrArgNotBound
+	}
+	n, err := strconv.Atoi(string(arg[2:]))
+	if err != nil || n < 1 || len(plan.Args) < n {
+		return nil, errors.ErrArgNotBound
+	}
+
+	//the same types as the values parsed from sql
+	switch v := plan.Args[n-1].(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint:
+		return uint64(v), nil
+	case []byte:
+		return string(v), nil
+	default:
+		return v, nil
+	}
+}
+
 /*2,5 ==> [2,3,4]*/
 func makeList(start, end int) []int {
 	list := make([]int, end-start)
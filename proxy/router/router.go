@@ -277,26 +277,80 @@ func includeNode(nodes []string, node string) bool {
 
 //build a router plan
 func (r *Router) BuildPlan(db string, statement sqlparser.Statement) (*Plan, error) {
+	return r.buildPlan(db, statement, nil)
+}
+
+//build the router plan of prepared statement with the args bound, the
+//args in the rewritten sqls are replaced with '?', and their indexes in
+//args are in RewrittenArgs. errors.ErrArgNotBound is returned if the
+//route depends on the args not bound.
+func (r *Router) BuildPreparedPlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan, err := r.buildPlan(db, statement, args)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.RewrittenArgs = make(map[string][][]int, len(plan.RewrittenSqls))
+	for nodeName, sqls := range plan.RewrittenSqls {
+		argIndexs := make([][]int, len(sqls))
+		for i := range sqls {
+			sqls[i], argIndexs[i] = sqlparser.PositionalArgs(sqls[i])
+		}
+		plan.RewrittenArgs[nodeName] = argIndexs
+	}
+	return plan, nil
+}
+
+func (r *Router) buildPlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
 	//因为实现Statement接口的方法都是指针类型，所以type对应类型也是指针类型
+	var plan *Plan
+	var err error
 	switch stmt := statement.(type) {
 	case *sqlparser.Insert:
-		return r.buildInsertPlan(db, stmt)
+		plan, err = r.buildInsertPlan(db, stmt, args)
 	case *sqlparser.Replace:
-		return r.buildReplacePlan(db, stmt)
+		plan, err = r.buildReplacePlan(db, stmt, args)
 	case *sqlparser.Select:
-		return r.buildSelectPlan(db, stmt)
+		plan, err = r.buildSelectPlan(db, stmt, args)
 	case *sqlparser.Update:
-		return r.buildUpdatePlan(db, stmt)
+		plan, err = r.buildUpdatePlan(db, stmt, args)
 	case *sqlparser.Delete:
-		return r.buildDeletePlan(db, stmt)
+		plan, err = r.buildDeletePlan(db, stmt, args)
 	case *sqlparser.Truncate:
-		return r.buildTruncatePlan(db, stmt)
+		plan, err = r.buildTruncatePlan(db, stmt, args)
+	default:
+		return nil, errors.ErrNoPlan
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.generateRewrittenTables(plan)
+	return plan, nil
+}
+
+//the sub tables are in the same order as the sqls generated in every node
+func (r *Router) generateRewrittenTables(plan *Plan) {
+	tables := make(map[string][]string)
+	if len(plan.RouteTableIndexs) == 0 {
+		nodeName := r.Nodes[0]
+		if plan.Rule.Type == NormalRuleType {
+			nodeName = plan.Rule.Nodes[0]
+		}
+		tables[nodeName] = []string{plan.Rule.Table}
+	} else {
+		for _, tableIndex := range plan.RouteTableIndexs {
+			nodeIndex := plan.Rule.TableToNode[tableIndex]
+			nodeName := r.Nodes[nodeIndex]
+			tables[nodeName] = append(tables[nodeName],
+				fmt.Sprintf("%s_%04d", plan.Rule.Table, tableIndex))
+		}
 	}
-	return nil, errors.ErrNoPlan
+	plan.RewrittenTables = tables
 }
 
-func (r *Router) buildSelectPlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildSelectPlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	var where *sqlparser.Where
 	var err error
 	var tableName string
@@ -343,8 +397,8 @@ func (r *Router) buildSelectPlan(db string, statement sqlparser.Statement) (*Pla
 	return plan, nil
 }
 
-func (r *Router) buildInsertPlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildInsertPlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	plan.Rows = make(map[int]sqlparser.Values)
 	stmt := statement.(*sqlparser.Insert)
 	if _, ok := stmt.Rows.(sqlparser.SelectStatement); ok {
@@ -389,8 +443,8 @@ func (r *Router) buildInsertPlan(db string, statement sqlparser.Statement) (*Pla
 	return plan, nil
 }
 
-func (r *Router) buildUpdatePlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildUpdatePlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	var where *sqlparser.Where
 
 	stmt := statement.(*sqlparser.Update)
@@ -426,8 +480,8 @@ func (r *Router) buildUpdatePlan(db string, statement sqlparser.Statement) (*Pla
 	return plan, nil
 }
 
-func (r *Router) buildDeletePlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildDeletePlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	var where *sqlparser.Where
 	var err error
 
@@ -460,8 +514,8 @@ func (r *Router) buildDeletePlan(db string, statement sqlparser.Statement) (*Pla
 	return plan, nil
 }
 
-func (r *Router) buildTruncatePlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildTruncatePlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	var err error
 
 	stmt := statement.(*sqlparser.Truncate)
@@ -482,8 +536,8 @@ func (r *Router) buildTruncatePlan(db string, statement sqlparser.Statement) (*P
 	return plan, nil
 }
 
-func (r *Router) buildReplacePlan(db string, statement sqlparser.Statement) (*Plan, error) {
-	plan := &Plan{}
+func (r *Router) buildReplacePlan(db string, statement sqlparser.Statement, args []interface{}) (*Plan, error) {
+	plan := &Plan{Args: args}
 	plan.Rows = make(map[int]sqlparser.Values)
 
 	stmt := statement.(*sqlparser.Replace)
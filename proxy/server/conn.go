@@ -16,6 +16,7 @@ package server
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
 	"net"
@@ -36,6 +37,11 @@ type ClientConn struct {
 
 	c net.Conn
 
+	tlsConfig *tls.Config //nil if tls is not enabled
+	tlsConn   *tls.Conn   //not nil if client uses tls
+
+	zstdLevel byte //the level of zstd compression sent by client
+
 	proxy *Server
 
 	capability uint32
@@ -46,6 +52,8 @@ type ClientConn struct {
 	collation mysql.CollationId
 	charset   string
 
+	moreResults bool //the results of the rest statements in multi statements follow
+
 	user string
 	db   string
 
@@ -54,7 +62,19 @@ type ClientConn struct {
 	nodes  map[string]*backend.Node
 	schema *Schema
 
-	txConns map[*backend.Node]*backend.BackendConn
+	txConns    map[*backend.Node]*backend.BackendConn
+	transId    string              //the global id of transaction in multi nodes
+	txSqls     map[string][]string //node : sqls executed in best effort transaction
+	savepoints []string            //the savepoints in transaction, in created order
+
+	sessionTrans transOption  //the characteristics set by SET SESSION TRANSACTION
+	nextTrans    *transOption //the characteristics of next transaction
+	curTrans     *transOption //the characteristics of current transaction
+
+	sessionVars []backend.SessionVar //the variables set in session, in set order
+
+	txWrites   map[*backend.Node]bool //the nodes written in transaction
+	lastWrites map[string]*lastWrite  //node : the last write of session
 
 	closed bool
 
@@ -65,12 +85,23 @@ type ClientConn struct {
 
 	stmts map[uint32]*Stmt //prepare相关,client端到proxy的stmt
 
+	binaryRows bool //the rows are in binary protocol when executing stmt
+
 	configVer uint32 //check config version for reload online
+
+	queryMem *backend.MemTracker //bytes read from backends by current query
+	spiller  *rowSpiller         //spill the rows of current select, nil if not spilled
+
+	warnings     []*Warning //the warnings of last statement
+	warningCount uint16
 }
 
 var DEFAULT_CAPABILITY uint32 = mysql.CLIENT_LONG_PASSWORD | mysql.CLIENT_LONG_FLAG |
 	mysql.CLIENT_CONNECT_WITH_DB | mysql.CLIENT_PROTOCOL_41 |
-	mysql.CLIENT_TRANSACTIONS | mysql.CLIENT_SECURE_CONNECTION
+	mysql.CLIENT_TRANSACTIONS | mysql.CLIENT_SECURE_CONNECTION |
+	mysql.CLIENT_PLUGIN_AUTH | mysql.CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA |
+	mysql.CLIENT_MULTI_STATEMENTS | mysql.CLIENT_MULTI_RESULTS |
+	mysql.CLIENT_COMPRESS | mysql.CLIENT_LOCAL_FILES
 
 var baseConnId uint32 = 10000
 
@@ -116,6 +147,7 @@ func (c *ClientConn) Handshake() error {
 			c.connectionId, "error", err.Error())
 		return err
 	}
+	c.enableCompression()
 
 	c.pkg.Sequence = 0
 	return nil
@@ -127,6 +159,7 @@ func (c *ClientConn) Close() error {
 	}
 
 	c.c.Close()
+	c.closeCursors()
 
 	c.closed = true
 
@@ -153,7 +186,8 @@ func (c *ClientConn) writeInitialHandshake() error {
 	data = append(data, 0)
 
 	//capability flag lower 2 bytes, using default capability here
-	data = append(data, byte(DEFAULT_CAPABILITY), byte(DEFAULT_CAPABILITY>>8))
+	capability := c.serverCapability()
+	data = append(data, byte(capability), byte(capability>>8))
 
 	//charset, utf-8 default
 	data = append(data, uint8(mysql.DEFAULT_COLLATION_ID))
@@ -163,7 +197,7 @@ func (c *ClientConn) writeInitialHandshake() error {
 
 	//below 13 byte may not be used
 	//capability flag upper 2 bytes, using default capability here
-	data = append(data, byte(DEFAULT_CAPABILITY>>16), byte(DEFAULT_CAPABILITY>>24))
+	data = append(data, byte(capability>>16), byte(capability>>24))
 
 	//filter [0x15], for wireshark dump, value is 0x15
 	data = append(data, 0x15)
@@ -177,6 +211,10 @@ func (c *ClientConn) writeInitialHandshake() error {
 	//filter [00]
 	data = append(data, 0)
 
+	//auth-plugin name [00]
+	data = append(data, mysql.AUTH_CACHING_SHA2_PASSWORD...)
+	data = append(data, 0)
+
 	return c.writePacket(data)
 }
 
@@ -203,6 +241,17 @@ func (c *ClientConn) readHandshakeResponse() error {
 
 	//capability
 	c.capability = binary.LittleEndian.Uint32(data[:4])
+
+	//SSLRequest, the handshake response is sent after tls handshake
+	if c.capability&mysql.CLIENT_SSL > 0 && len(data) == sslRequestLen && c.tlsConfig != nil {
+		if err = c.upgradeTLS(); err != nil {
+			return err
+		}
+		if data, err = c.readPacket(); err != nil {
+			return err
+		}
+		c.capability = binary.LittleEndian.Uint32(data[:4])
+	}
 	pos += 4
 
 	//skip max packet size
@@ -221,43 +270,52 @@ func (c *ClientConn) readHandshakeResponse() error {
 	pos += len(c.user) + 1
 
 	//auth length and auth
-	authLen := int(data[pos])
-	pos++
+	var authLen int
+	if c.capability&mysql.CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA > 0 {
+		num, _, n := mysql.LengthEncodedInt(data[pos:])
+		authLen = int(num)
+		pos += n
+	} else {
+		authLen = int(data[pos])
+		pos++
+	}
 	auth := data[pos : pos+authLen]
+	pos += authLen
 
-	//check user
-	if _, ok := c.proxy.users[c.user]; !ok {
-		golog.Error("ClientConn", "readHandshakeResponse", "error", 0,
-			"auth", auth,
-			"client_user", c.user,
-			"config_set_user", c.user,
-			"passworld", c.proxy.users[c.user])
-		return mysql.NewDefaultError(mysql.ER_ACCESS_DENIED_ERROR, c.user, c.c.RemoteAddr().String(), "Yes")
+	var db string
+	if c.capability&mysql.CLIENT_CONNECT_WITH_DB > 0 && len(data[pos:]) > 0 {
+		db = string(data[pos : pos+bytes.IndexByte(data[pos:], 0)])
+		pos += len(db) + 1
 	}
 
-	//check password
-	checkAuth := mysql.CalcPassword(c.salt, []byte(c.proxy.users[c.user]))
-	if !bytes.Equal(auth, checkAuth) {
-		golog.Error("ClientConn", "readHandshakeResponse", "error", 0,
-			"auth", auth,
-			"checkAuth", checkAuth,
-			"client_user", c.user,
-			"config_set_user", c.user,
-			"passworld", c.proxy.users[c.user])
-		return mysql.NewDefaultError(mysql.ER_ACCESS_DENIED_ERROR, c.user, c.c.RemoteAddr().String(), "Yes")
+	//auth plugin name
+	var plugin string
+	if c.capability&mysql.CLIENT_PLUGIN_AUTH > 0 && len(data[pos:]) > 0 {
+		if end := bytes.IndexByte(data[pos:], 0); end >= 0 {
+			plugin = string(data[pos : pos+end])
+		} else {
+			plugin = string(data[pos:])
+		}
+		pos += len(plugin) + 1
 	}
 
-	pos += authLen
+	//skip connection attributes
+	if c.capability&mysql.CLIENT_CONNECT_ATTRS > 0 && pos < len(data) {
+		num, _, n := mysql.LengthEncodedInt(data[pos:])
+		pos += n + int(num)
+	}
 
-	var db string
-	if c.capability&mysql.CLIENT_CONNECT_WITH_DB > 0 {
-		if len(data[pos:]) == 0 {
-			return nil
-		}
+	//the level of zstd compression
+	if c.capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 && pos < len(data) {
+		c.zstdLevel = data[pos]
+	}
 
-		db = string(data[pos : pos+bytes.IndexByte(data[pos:], 0)])
-		pos += len(c.db) + 1
+	if err := c.checkAuth(plugin, auth); err != nil {
+		return err
+	}
 
+	if err := c.checkTLS(); err != nil {
+		return err
 	}
 	c.db = db
 
@@ -317,7 +375,7 @@ func (c *ClientConn) Run() {
 			return
 		}
 
-		c.pkg.Sequence = 0
+		c.pkg.ResetSequence()
 	}
 }
 
@@ -326,13 +384,16 @@ func (c *ClientConn) dispatch(data []byte) error {
 	cmd := data[0]
 	data = data[1:]
 
+	c.queryMem = backend.NewMemTracker(c.proxy.GetMaxResultBytes(c.user, c.schema), c.proxy.queryMem)
+	defer c.queryMem.Release()
+
 	switch cmd {
 	case mysql.COM_QUIT:
 		c.handleRollback()
 		c.Close()
 		return nil
 	case mysql.COM_QUERY:
-		return c.handleQuery(hack.String(data))
+		return c.handleMultiQuery(hack.String(data))
 	case mysql.COM_PING:
 		return c.writeOK(nil)
 	case mysql.COM_INIT_DB:
@@ -349,8 +410,14 @@ func (c *ClientConn) dispatch(data []byte) error {
 		return c.handleStmtSendLongData(data)
 	case mysql.COM_STMT_RESET:
 		return c.handleStmtReset(data)
+	case mysql.COM_STMT_FETCH:
+		return c.handleStmtFetch(data)
 	case mysql.COM_SET_OPTION:
-		return c.writeEOF(0)
+		return c.handleSetOption(data)
+	case mysql.COM_CHANGE_USER:
+		return c.handleChangeUser(data)
+	case mysql.COM_RESET_CONNECTION:
+		return c.handleResetConnection()
 	default:
 		msg := fmt.Sprintf("command %d not supported now", cmd)
 		golog.Error("ClientConn", "dispatch", msg, 0)
@@ -372,8 +439,9 @@ func (c *ClientConn) writeOK(r *mysql.Result) error {
 	data = append(data, mysql.PutLengthEncodedInt(r.InsertId)...)
 
 	if c.capability&mysql.CLIENT_PROTOCOL_41 > 0 {
-		data = append(data, byte(r.Status), byte(r.Status>>8))
-		data = append(data, 0, 0)
+		status := c.resultStatus(r.Status)
+		data = append(data, byte(status), byte(status>>8))
+		data = append(data, byte(c.warningCount), byte(c.warningCount>>8))
 	}
 
 	return c.writePacket(data)
@@ -406,7 +474,8 @@ func (c *ClientConn) writeEOF(status uint16) error {
 
 	data = append(data, mysql.EOF_HEADER)
 	if c.capability&mysql.CLIENT_PROTOCOL_41 > 0 {
-		data = append(data, 0, 0)
+		status = c.resultStatus(status)
+		data = append(data, byte(c.warningCount), byte(c.warningCount>>8))
 		data = append(data, byte(status), byte(status>>8))
 	}
 
@@ -418,7 +487,8 @@ func (c *ClientConn) writeEOFBatch(total []byte, status uint16, direct bool) ([]
 
 	data = append(data, mysql.EOF_HEADER)
 	if c.capability&mysql.CLIENT_PROTOCOL_41 > 0 {
-		data = append(data, 0, 0)
+		status = c.resultStatus(status)
+		data = append(data, byte(c.warningCount), byte(c.warningCount>>8))
 		data = append(data, byte(status), byte(status>>8))
 	}
 
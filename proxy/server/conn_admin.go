@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flike/kingshard/backend"
 	"github.com/flike/kingshard/core/errors"
 	"github.com/flike/kingshard/core/golog"
 	"github.com/flike/kingshard/core/hack"
@@ -411,6 +412,10 @@ func (c *ClientConn) handleAdminShow(k, v string) (*mysql.Resultset, error) {
 		return c.handleShowNodeConfig()
 	}
 
+	if k == ADMIN_NODE && v == ADMIN_STATUS {
+		return c.handleShowNodeStatus()
+	}
+
 	if k == ADMIN_SCHEMA && v == ADMIN_CONFIG {
 		return c.handleShowSchemaConfig()
 	}
@@ -503,6 +508,8 @@ func (c *ClientConn) handleShowProxyConfig() (*mysql.Resultset, error) {
 	rows = append(rows, []string{"ClientQPS", fmt.Sprintf("%d", c.proxy.counter.OldClientQPS)})
 	rows = append(rows, []string{"ErrLogTotal", fmt.Sprintf("%d", c.proxy.counter.OldErrLogTotal)})
 	rows = append(rows, []string{"SlowLogTotal", fmt.Sprintf("%d", c.proxy.counter.OldSlowLogTotal)})
+	rows = append(rows, []string{"QueryMemBytes", fmt.Sprintf("%d", c.proxy.queryMem.Used())})
+	rows = append(rows, []string{"PeakQueryMemBytes", fmt.Sprintf("%d", c.proxy.queryMem.Peak())})
 
 	var values [][]interface{} = make([][]interface{}, len(rows))
 	for i := range rows {
@@ -524,25 +531,28 @@ func (c *ClientConn) handleShowNodeConfig() (*mysql.Resultset, error) {
 		"LastPing",
 		"MaxConn",
 		"IdleConn",
+		"Lag",
 	}
 	var rows [][]string
 	const (
-		Column = 7
+		Column = 8
 	)
 
 	//var nodeRows [][]string
 	for name, node := range c.schema.nodes {
 		//"master"
+		master := node.GetMaster()
 		rows = append(
 			rows,
 			[]string{
 				name,
-				node.Master.Addr(),
+				master.Addr(),
 				"master",
-				node.Master.State(),
-				fmt.Sprintf("%v", time.Unix(node.Master.GetLastPing(), 0)),
+				master.State(),
+				fmt.Sprintf("%v", time.Unix(master.GetLastPing(), 0)),
 				strconv.Itoa(node.Cfg.MaxConnNum),
-				strconv.Itoa(node.Master.IdleConnCount()),
+				strconv.Itoa(master.IdleConnCount()),
+				"0",
 			})
 		//"slave"
 		for _, slave := range node.Slave {
@@ -557,6 +567,7 @@ func (c *ClientConn) handleShowNodeConfig() (*mysql.Resultset, error) {
 						fmt.Sprintf("%v", time.Unix(slave.GetLastPing(), 0)),
 						strconv.Itoa(node.Cfg.MaxConnNum),
 						strconv.Itoa(slave.IdleConnCount()),
+						formatSlaveLag(slave.GetLag()),
 					})
 			}
 		}
@@ -573,6 +584,55 @@ func (c *ClientConn) handleShowNodeConfig() (*mysql.Resultset, error) {
 	return c.buildResultset(nil, names, values)
 }
 
+//the metrics of conn pools and the tls state
+func (c *ClientConn) handleShowNodeStatus() (*mysql.Resultset, error) {
+	var names []string = []string{
+		"Node",
+		"Address",
+		"Type",
+		"InUse",
+		"IdleConn",
+		"WaitCount",
+		"WaitTime",
+		"DialErrors",
+		"TLS",
+	}
+	var rows [][]interface{}
+
+	appendRow := func(name, role string, db *backend.DB) {
+		stats := db.PoolStats()
+		rows = append(rows, []interface{}{
+			name,
+			db.Addr(),
+			role,
+			stats.InUse,
+			int64(stats.Idle),
+			stats.WaitCount,
+			int64(stats.WaitTime / time.Millisecond),
+			stats.DialErrors,
+			db.TLSState(),
+		})
+	}
+
+	for name, node := range c.schema.nodes {
+		appendRow(name, Master, node.GetMaster())
+		for _, slave := range node.Slave {
+			if slave != nil {
+				appendRow(name, Slave, slave)
+			}
+		}
+	}
+
+	return c.buildResultset(nil, names, rows)
+}
+
+func formatSlaveLag(lag int64) string {
+	if lag == backend.LagUnknown {
+		return "unknown"
+	}
+	return strconv.FormatInt(lag, 10)
+}
+
 func (c *ClientConn) handleShowSchemaConfig() (*mysql.Resultset, error) {
 	var rows [][]string
 	var names []string = []string{
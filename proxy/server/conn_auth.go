@@ -0,0 +1,196 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+//checkAuth check the auth data of client, mysql_native_password and
+//caching_sha2_password are supported. The password of user can be
+//plaintext or the hash stored in mysql.user, the client using other plugin
+//is asked to switch to the plugin of the hash
+func (c *ClientConn) checkAuth(plugin string, auth []byte) error {
+	//check user
+	password, ok := c.proxy.users[c.user]
+	if !ok {
+		return c.accessDenied()
+	}
+
+	nativeHash, isNativeHash := mysql.ParseNativePasswordHash(password)
+	isSha2Hash := mysql.IsCachingSha2PasswordHash(password)
+
+	var expect string
+	switch {
+	case isNativeHash:
+		expect = mysql.AUTH_NAME
+	case isSha2Hash:
+		expect = mysql.AUTH_CACHING_SHA2_PASSWORD
+	case plugin == "" || plugin == mysql.AUTH_NAME:
+		expect = mysql.AUTH_NAME
+	default:
+		expect = mysql.AUTH_CACHING_SHA2_PASSWORD
+	}
+	//the client of old version only uses mysql_native_password
+	if plugin == "" {
+		plugin = mysql.AUTH_NAME
+	}
+	if plugin != expect {
+		//the client without plugin auth can not parse the auth switch request
+		if c.capability&mysql.CLIENT_PLUGIN_AUTH == 0 {
+			return c.accessDenied()
+		}
+		var err error
+		plugin = expect
+		if auth, err = c.switchAuth(plugin); err != nil {
+			return err
+		}
+	}
+
+	//check password
+	var pass bool
+	switch {
+	case isNativeHash:
+		pass = mysql.CheckNativePassword(c.salt, auth, nativeHash)
+	case isSha2Hash:
+		return c.checkCachingSha2Hash(password, auth)
+	case plugin == mysql.AUTH_CACHING_SHA2_PASSWORD:
+		pass = bytes.Equal(auth, mysql.CalcCachingSha2Password(c.salt, []byte(password)))
+	default:
+		pass = bytes.Equal(auth, mysql.CalcPassword(c.salt, []byte(password)))
+	}
+	if !pass {
+		return c.accessDenied()
+	}
+
+	//proxy knows the password, so the full auth is never needed
+	if plugin == mysql.AUTH_CACHING_SHA2_PASSWORD && 0 < len(password) {
+		return c.writeAuthMoreData(mysql.CACHING_SHA2_FAST_AUTH_SUCCESS)
+	}
+	return nil
+}
+
+//the password and auth data are secrets, never log them
+func (c *ClientConn) accessDenied() error {
+	golog.Error("ClientConn", "readHandshakeResponse", "error", 0,
+		"client_user", c.user,
+		"client_addr", c.c.RemoteAddr().String())
+	return mysql.NewDefaultError(mysql.ER_ACCESS_DENIED_ERROR, c.user, c.c.RemoteAddr().String(), "Yes")
+}
+
+//checkCachingSha2Hash check the client by the hash of caching_sha2_password,
+//the fast auth is used if the password is cached by the previous full auth
+func (c *ClientConn) checkCachingSha2Hash(hash string, auth []byte) error {
+	if cached := c.proxy.getSha2Cache(hash); cached != nil {
+		if !mysql.CheckCachingSha2Password(c.salt, auth, cached) {
+			return c.accessDenied()
+		}
+		return c.writeAuthMoreData(mysql.CACHING_SHA2_FAST_AUTH_SUCCESS)
+	}
+
+	//full auth, the password is sent in plain text over tls,
+	//otherwise it is encrypted by the rsa public key of proxy
+	if err := c.writeAuthMoreData(mysql.CACHING_SHA2_PERFORM_FULL_AUTH); err != nil {
+		return err
+	}
+	data, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+
+	var password []byte
+	if c.tlsConn != nil {
+		password = bytes.TrimRight(data, "\x00")
+	} else {
+		if len(data) != 1 || data[0] != mysql.CACHING_SHA2_REQUEST_PUBLIC_KEY {
+			return c.accessDenied()
+		}
+		key, err := c.proxy.getRSAKey()
+		if err != nil {
+			return err
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			return err
+		}
+		pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		if err := c.writeAuthMoreData(pub...); err != nil {
+			return err
+		}
+		if data, err = c.readPacket(); err != nil {
+			return err
+		}
+		if password, err = mysql.DecryptPassword(data, c.salt, key); err != nil {
+			return c.accessDenied()
+		}
+	}
+
+	if !mysql.CheckCachingSha2PasswordHash(password, hash) {
+		return c.accessDenied()
+	}
+	c.proxy.setSha2Cache(hash, mysql.CachingSha2Hash(password))
+	return nil
+}
+
+func (c *ClientConn) writeAuthMoreData(more ...byte) error {
+	data := make([]byte, 4, 5+len(more))
+	data = append(data, mysql.AUTH_MORE_DATA_HEADER)
+	data = append(data, more...)
+	return c.writePacket(data)
+}
+
+//switchAuth send the auth switch request and read the new auth data
+func (c *ClientConn) switchAuth(plugin string) ([]byte, error) {
+	data := make([]byte, 4, 64)
+	data = append(data, mysql.AUTH_SWITCH_HEADER)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	data = append(data, c.salt...)
+	data = append(data, 0)
+	if err := c.writePacket(data); err != nil {
+		return nil, err
+	}
+	return c.readPacket()
+}
+
+func (s *Server) getSha2Cache(hash string) []byte {
+	s.sha2CacheMutex.RLock()
+	defer s.sha2CacheMutex.RUnlock()
+	return s.sha2Cache[hash]
+}
+
+func (s *Server) setSha2Cache(hash string, sha2 []byte) {
+	s.sha2CacheMutex.Lock()
+	defer s.sha2CacheMutex.Unlock()
+	if s.sha2Cache == nil {
+		s.sha2Cache = make(map[string][]byte)
+	}
+	s.sha2Cache[hash] = sha2
+}
+
+//the rsa key of proxy is generated when it is first used by the full auth
+func (s *Server) getRSAKey() (*rsa.PrivateKey, error) {
+	s.rsaKeyOnce.Do(func() {
+		s.rsaKey, s.rsaKeyErr = rsa.GenerateKey(rand.Reader, 2048)
+	})
+	return s.rsaKey, s.rsaKeyErr
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+func TestCheckAuthCachingSha2(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 2
+
+	done := make(chan []byte, 1)
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 2
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			t.Error(err)
+		}
+		done <- data
+	}()
+
+	auth := mysql.CalcCachingSha2Password(c.salt, []byte("kingshard"))
+	if err := c.checkAuth(mysql.AUTH_CACHING_SHA2_PASSWORD, auth); err != nil {
+		t.Fatal(err)
+	}
+	data := <-done
+	if !bytes.Equal(data, []byte{mysql.AUTH_MORE_DATA_HEADER, mysql.CACHING_SHA2_FAST_AUTH_SUCCESS}) {
+		t.Fatalf("expect fast auth success, got %v", data)
+	}
+
+	if err := c.checkAuth(mysql.AUTH_NAME, auth); err == nil {
+		t.Fatal("expect access denied")
+	}
+}
+
+func TestCheckAuthSwitch(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 2
+
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 2
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		plugin := append([]byte(mysql.AUTH_CACHING_SHA2_PASSWORD), 0)
+		if data[0] != mysql.AUTH_SWITCH_HEADER || !bytes.HasPrefix(data[1:], plugin) {
+			t.Errorf("invalid auth switch request %v", data)
+			return
+		}
+		salt := data[1+len(plugin) : len(data)-1]
+		auth := mysql.CalcCachingSha2Password(salt, []byte("kingshard"))
+		if err := pkg.WritePacket(append(make([]byte, 4), auth...)); err != nil {
+			t.Error(err)
+			return
+		}
+		pkg.ReadPacket()
+	}()
+
+	if err := c.checkAuth("mysql_clear_password", []byte("kingshard")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckAuthNativeHash(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 2
+	c.proxy.users["root"] = mysql.NativePasswordHash([]byte("kingshard"))
+
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 2
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//switch to mysql_native_password
+		plugin := append([]byte(mysql.AUTH_NAME), 0)
+		if data[0] != mysql.AUTH_SWITCH_HEADER || !bytes.HasPrefix(data[1:], plugin) {
+			t.Errorf("invalid auth switch request %v", data)
+			return
+		}
+		auth := mysql.CalcPassword(c.salt, []byte("kingshard"))
+		if err := pkg.WritePacket(append(make([]byte, 4), auth...)); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	auth := mysql.CalcCachingSha2Password(c.salt, []byte("kingshard"))
+	if err := c.checkAuth(mysql.AUTH_CACHING_SHA2_PASSWORD, auth); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckAuthCachingSha2Hash(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 2
+	c.proxy.users["root"] = mysql.CachingSha2PasswordHash([]byte("kingshard"), []byte("abcdefghijklmnopqrst"))
+
+	//full auth by the rsa public key of proxy
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 2
+		data, err := pkg.ReadPacket()
+		if err != nil || !bytes.Equal(data, []byte{mysql.AUTH_MORE_DATA_HEADER, mysql.CACHING_SHA2_PERFORM_FULL_AUTH}) {
+			t.Errorf("expect full auth, got %v %v", data, err)
+			return
+		}
+		pkg.WritePacket([]byte{0, 0, 0, 0, mysql.CACHING_SHA2_REQUEST_PUBLIC_KEY})
+		if data, err = pkg.ReadPacket(); err != nil {
+			t.Error(err)
+			return
+		}
+		pub, err := mysql.ParsePublicKey(data[1:])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		enc, err := mysql.EncryptPassword([]byte("kingshard"), c.salt, pub)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		pkg.WritePacket(append(make([]byte, 4), enc...))
+	}()
+
+	auth := mysql.CalcCachingSha2Password(c.salt, []byte("kingshard"))
+	if err := c.checkAuth(mysql.AUTH_CACHING_SHA2_PASSWORD, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	//fast auth by the cached hash
+	done := make(chan []byte, 1)
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 2
+		data, _ := pkg.ReadPacket()
+		done <- data
+	}()
+	c.pkg.Sequence = 2
+	if err := c.checkAuth(mysql.AUTH_CACHING_SHA2_PASSWORD, auth); err != nil {
+		t.Fatal(err)
+	}
+	if data := <-done; !bytes.Equal(data, []byte{mysql.AUTH_MORE_DATA_HEADER, mysql.CACHING_SHA2_FAST_AUTH_SUCCESS}) {
+		t.Fatalf("expect fast auth success, got %v", data)
+	}
+}
+
+func TestCheckAuthWithoutPluginAuth(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 2
+	c.capability &^= mysql.CLIENT_PLUGIN_AUTH
+	c.proxy.users["root"] = mysql.CachingSha2PasswordHash([]byte("kingshard"), []byte("abcdefghijklmnopqrst"))
+
+	//the old client is denied without auth switch request, which would
+	//block on the pipe
+	auth := mysql.CalcPassword(c.salt, []byte("kingshard"))
+	err := c.checkAuth("", auth)
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_ACCESS_DENIED_ERROR {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+const (
+	IncidentCommitted = "committed"
+	IncidentFailed    = "failed"
+)
+
+func (c *ClientConn) isBestEffortTrans() bool {
+	return c.schema.transMode == TransModeBestEffort
+}
+
+//record the sql executed successfully in best effort transaction,
+//they are written into incident log if the transaction partially committed
+func (c *ClientConn) recordTxSql(co *backend.BackendConn, sql string, args []interface{}) {
+	if !c.isBestEffortTrans() || !c.isInTransaction() {
+		return
+	}
+	if 0 < len(args) {
+		sql = fmt.Sprintf("%s %v", sql, args)
+	}
+
+	for n, txConn := range c.txConns {
+		if txConn != co {
+			continue
+		}
+		c.Lock()
+		if c.txSqls == nil {
+			c.txSqls = make(map[string][]string)
+		}
+		c.txSqls[n.Cfg.Name] = append(c.txSqls[n.Cfg.Name], sql)
+		c.Unlock()
+		return
+	}
+}
+
+//commit the nodes in turn, the transaction is rolled back if the first node
+//failed, otherwise the failed nodes are written into incident log and
+//ER_PARTIAL_COMMIT is returned
+func (c *ClientConn) bestEffortCommit() error {
+	nodes := make([]*backend.Node, 0, len(c.txConns))
+	for n := range c.txConns {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Cfg.Name < nodes[j].Cfg.Name
+	})
+
+	var committed bool
+	var failedNodes []string
+	records := make([]*incidentRecord, 0, len(nodes))
+	for i, n := range nodes {
+		err := c.txConns[n].Commit()
+		if err != nil && !committed {
+			//nothing committed, rollback the others
+			for _, other := range nodes[i+1:] {
+				c.txConns[other].Rollback()
+			}
+			return err
+		}
+
+		r := &incidentRecord{
+			node:  n.Cfg.Name,
+			state: IncidentCommitted,
+			err:   err,
+			sqls:  c.txSqls[n.Cfg.Name],
+		}
+		if err != nil {
+			r.state = IncidentFailed
+			failedNodes = append(failedNodes, n.Cfg.Name)
+		} else {
+			committed = true
+		}
+		records = append(records, r)
+	}
+
+	if len(failedNodes) == 0 {
+		return nil
+	}
+
+	id := c.getTransId()
+	for _, r := range records {
+		golog.Error("ClientConn", "bestEffortCommit", "transaction partially committed", c.connectionId,
+			"id", id, "node", r.node, "state", r.state, "sqls", strings.Join(r.sqls, ";"))
+	}
+	if c.proxy.incidentLog != nil {
+		if err := c.proxy.incidentLog.Write(id, records); err != nil {
+			golog.Error("ClientConn", "bestEffortCommit", err.Error(), c.connectionId, "id", id)
+		}
+	}
+
+	return mysql.NewError(mysql.ER_PARTIAL_COMMIT,
+		fmt.Sprintf(mysql.MySQLErrName[mysql.ER_PARTIAL_COMMIT], id, strings.Join(failedNodes, ",")))
+}
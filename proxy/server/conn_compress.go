@@ -0,0 +1,33 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"github.com/flike/kingshard/mysql"
+)
+
+//enableCompression switch to the compressed protocol negotiated in handshake
+func (c *ClientConn) enableCompression() {
+	switch {
+	case c.capability&mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM > 0 && mysql.HasCompressCodec(mysql.COMPRESS_ZSTD):
+		level := int(c.zstdLevel)
+		if level == 0 {
+			level = mysql.DEFAULT_ZSTD_LEVEL
+		}
+		c.pkg.EnableCompression(mysql.NewCompressCodec(mysql.COMPRESS_ZSTD, level))
+	case c.capability&mysql.CLIENT_COMPRESS > 0:
+		c.pkg.EnableCompression(mysql.NewCompressCodec(mysql.COMPRESS_ZLIB, 0))
+	}
+}
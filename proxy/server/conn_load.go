@@ -0,0 +1,506 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flike/kingshard/core/errors"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/proxy/router"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+const (
+	//the rows of LOAD DATA are inserted in batch, a batch is flushed when
+	//any of the limits is reached
+	LoadDataBatchRows  = 1000
+	LoadDataBatchBytes = 1024 * 1024
+)
+
+//loadDataParser splits the file of LOAD DATA into rows by the FIELDS and
+//LINES options, the file is fed by packets.
+type loadDataParser struct {
+	fieldTerm   []byte
+	enclosed    []byte //empty or one char
+	escaped     []byte //empty or one char
+	lineStart   []byte
+	lineTerm    []byte
+	ignoreLines int64
+
+	buf  []byte
+	rows int64 //the rows parsed, including the ignored lines
+}
+
+func newLoadDataParser(stmt *sqlparser.LoadData) (*loadDataParser, error) {
+	p := &loadDataParser{
+		fieldTerm: []byte("\t"),
+		escaped:   []byte("\\"),
+		lineTerm:  []byte("\n"),
+	}
+
+	if f := stmt.Fields; f != nil {
+		if f.Terminated != nil {
+			p.fieldTerm = []byte(f.Terminated)
+		}
+		if f.Enclosed != nil {
+			p.enclosed = []byte(f.Enclosed)
+		}
+		if f.Escaped != nil {
+			p.escaped = []byte(f.Escaped)
+		}
+	}
+	if l := stmt.Lines; l != nil {
+		if l.Starting != nil {
+			p.lineStart = []byte(l.Starting)
+		}
+		if l.Terminated != nil {
+			p.lineTerm = []byte(l.Terminated)
+		}
+	}
+	if stmt.IgnoreLines != nil {
+		n, err := strconv.ParseInt(string(stmt.IgnoreLines), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		p.ignoreLines = n
+	}
+
+	if 1 < len(p.enclosed) || 1 < len(p.escaped) {
+		return nil, mysql.NewDefaultError(mysql.ER_WRONG_FIELD_TERMINATORS)
+	}
+	//the fixed-row format is not supported
+	if len(p.fieldTerm) == 0 || len(p.lineTerm) == 0 {
+		return nil, mysql.NewDefaultError(mysql.ER_WRONG_FIELD_TERMINATORS)
+	}
+
+	return p, nil
+}
+
+//Feed appends the data of file and returns the rows completed, the field
+//of NULL is nil. The data left is parsed when more data is fed, eof means
+//that all the file is fed.
+func (p *loadDataParser) Feed(data []byte, eof bool) [][][]byte {
+	p.buf = append(p.buf, data...)
+
+	var rows [][][]byte
+	pos := 0
+	for pos < len(p.buf) {
+		row, n := p.parseRow(p.buf[pos:], eof)
+		if n == 0 {
+			break
+		}
+		pos += n
+		p.rows++
+		if p.rows <= p.ignoreLines || row == nil {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	//keep the partial row only
+	p.buf = append(p.buf[:0], p.buf[pos:]...)
+	return rows
+}
+
+//parseRow parses the first row in data, n is 0 if more data is needed.
+//The row is nil for the empty line and the line without starting prefix.
+func (p *loadDataParser) parseRow(data []byte, eof bool) (row [][]byte, n int) {
+	pos := 0
+	if 0 < len(p.lineStart) {
+		i := bytes.Index(data, p.lineStart)
+		if i < 0 {
+			//skip the data without starting prefix, a partial prefix
+			//may be at the end
+			if eof {
+				return nil, len(data)
+			}
+			return nil, 0
+		}
+		pos = i + len(p.lineStart)
+	}
+
+	if bytes.HasPrefix(data[pos:], p.lineTerm) {
+		return nil, pos + len(p.lineTerm)
+	}
+
+	for {
+		field, isNull, end, lineEnd, ok := p.parseField(data, pos, eof)
+		if !ok {
+			return nil, 0
+		}
+		if isNull {
+			row = append(row, nil)
+		} else {
+			row = append(row, field)
+		}
+		pos = end
+		if lineEnd {
+			return row, pos
+		}
+	}
+}
+
+//parseField parses the field begins at pos, end is the position after the
+//terminator, ok is false if more data is needed.
+func (p *loadDataParser) parseField(data []byte, pos int, eof bool) (field []byte, isNull bool, end int, lineEnd bool, ok bool) {
+	field = make([]byte, 0, 16)
+	quoted := 0 < len(p.enclosed) && pos < len(data) && data[pos] == p.enclosed[0]
+	if quoted {
+		pos++
+	}
+	start := pos
+
+	for {
+		if len(data) <= pos {
+			//the last line may have no terminator
+			if !eof {
+				return nil, false, 0, false, false
+			}
+			return field, p.isNull(data[start:pos], quoted), pos, true, true
+		}
+
+		c := data[pos]
+		if 0 < len(p.escaped) && c == p.escaped[0] {
+			if len(data) <= pos+1 {
+				if !eof {
+					return nil, false, 0, false, false
+				}
+				field = append(field, c)
+				pos++
+				continue
+			}
+			field = append(field, unescapeLoadChar(data[pos+1]))
+			pos += 2
+			continue
+		}
+
+		if quoted {
+			if c != p.enclosed[0] {
+				field = append(field, c)
+				pos++
+				continue
+			}
+			//the doubled enclosed char is the char itself
+			if pos+1 < len(data) && data[pos+1] == c {
+				field = append(field, c)
+				pos += 2
+				continue
+			}
+			if pos+1 == len(data) && !eof {
+				return nil, false, 0, false, false
+			}
+			next := data[pos+1:]
+			if bytes.HasPrefix(next, p.lineTerm) {
+				return field, false, pos + 1 + len(p.lineTerm), true, true
+			}
+			if bytes.HasPrefix(next, p.fieldTerm) {
+				return field, false, pos + 1 + len(p.fieldTerm), false, true
+			}
+			if len(next) == 0 {
+				return field, false, pos + 1, true, true
+			}
+			if !eof && (len(next) < len(p.lineTerm) || len(next) < len(p.fieldTerm)) {
+				return nil, false, 0, false, false
+			}
+			field = append(field, c)
+			pos++
+			continue
+		}
+
+		if bytes.HasPrefix(data[pos:], p.lineTerm) {
+			return field, p.isNull(data[start:pos], quoted), pos + len(p.lineTerm), true, true
+		}
+		if bytes.HasPrefix(data[pos:], p.fieldTerm) {
+			return field, p.isNull(data[start:pos], quoted), pos + len(p.fieldTerm), false, true
+		}
+		field = append(field, c)
+		pos++
+	}
+}
+
+//\N is NULL, and the unenclosed NULL is NULL if fields are enclosed
+func (p *loadDataParser) isNull(raw []byte, quoted bool) bool {
+	if quoted {
+		return false
+	}
+	if 0 < len(p.escaped) && len(raw) == 2 && raw[0] == p.escaped[0] && raw[1] == 'N' {
+		return true
+	}
+	return 0 < len(p.enclosed) && string(raw) == "NULL"
+}
+
+func unescapeLoadChar(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 26
+	}
+	return c
+}
+
+//handleLoadData requests the file of LOAD DATA LOCAL INFILE from client,
+//the rows are routed by the rule of table and inserted in batch. The batches
+//are loaded in a transaction if not in transaction, so that the file is
+//loaded entirely or not at all.
+func (c *ClientConn) handleLoadData(stmt *sqlparser.LoadData) error {
+	if c.capability&mysql.CLIENT_LOCAL_FILES == 0 {
+		return mysql.NewDefaultError(mysql.ER_NOT_ALLOWED_COMMAND)
+	}
+
+	p, err := newLoadDataParser(stmt)
+	if err != nil {
+		return err
+	}
+
+	rule := c.schema.rule.GetRule(c.db, sqlparser.String(stmt.Table))
+	//the rows of sharded table are routed by the shard key, so the columns
+	//are needed. The rows of table not sharded are inserted without columns.
+	columns := stmt.Columns
+	if columns == nil && rule != c.schema.rule.DefaultRule {
+		if columns, err = c.getLoadDataColumns(stmt); err != nil {
+			return err
+		}
+	}
+
+	keyIndex := -1
+	for i, expr := range columns {
+		if col, ok := expr.(*sqlparser.NonStarExpr).Expr.(*sqlparser.ColName); ok &&
+			strings.ToLower(string(col.Name)) == rule.Key {
+			keyIndex = i
+		}
+	}
+
+	//request the file
+	data := make([]byte, 4, 5+len(stmt.File))
+	data = append(data, mysql.LocalInFile_HEADER)
+	data = append(data, stmt.File...)
+	if err = c.writePacket(data); err != nil {
+		return err
+	}
+
+	inTrans := c.isInTransaction()
+	if !inTrans {
+		c.beginTransOption(nil)
+		c.status |= mysql.SERVER_STATUS_IN_TRANS
+	}
+
+	var loadErr error
+	var rows sqlparser.Values
+	var rowNum, batchBytes int
+	r := &mysql.Result{Status: c.status}
+
+	flush := func() {
+		if loadErr != nil || len(rows) == 0 {
+			return
+		}
+		var rs []*mysql.Result
+		rs, loadErr = c.executeLoadData(stmt, rule, columns, rows)
+		for _, v := range rs {
+			r.Status |= v.Status
+			r.AffectedRows += v.AffectedRows
+		}
+		rows = nil
+		batchBytes = 0
+	}
+	load := func(fields [][][]byte) {
+		for _, f := range fields {
+			if loadErr != nil {
+				return
+			}
+			rowNum++
+			//the columns of table not sharded are checked by the backend
+			if columns != nil && len(f) < len(columns) {
+				loadErr = mysql.NewError(mysql.ER_WARN_TOO_FEW_RECORDS,
+					fmt.Sprintf("Row %d doesn't contain data for all columns", rowNum))
+				return
+			}
+			if columns != nil && len(columns) < len(f) {
+				loadErr = mysql.NewError(mysql.ER_WARN_TOO_MANY_RECORDS,
+					fmt.Sprintf("Row %d was truncated; it contained more data than there were input columns", rowNum))
+				return
+			}
+
+			row := make(sqlparser.ValTuple, len(f))
+			for i, v := range f {
+				row[i] = loadDataValue(v, i == keyIndex)
+				batchBytes += len(v)
+			}
+			rows = append(rows, row)
+			if LoadDataBatchRows <= len(rows) || LoadDataBatchBytes <= batchBytes {
+				flush()
+			}
+		}
+	}
+
+	//the packets are read until the empty one even if error occurs
+	for {
+		data, err = c.pkg.ReadFilePacket()
+		if err != nil {
+			if !inTrans {
+				c.rollback()
+			}
+			return err
+		}
+		if len(data) == 0 {
+			break
+		}
+		if loadErr == nil {
+			load(p.Feed(data, false))
+		}
+	}
+	if loadErr == nil {
+		load(p.Feed(nil, true))
+		flush()
+	}
+	if !inTrans {
+		if loadErr == nil {
+			loadErr = c.commit()
+		} else {
+			c.rollback()
+		}
+		r.Status &^= mysql.SERVER_STATUS_IN_TRANS
+	}
+	if loadErr != nil {
+		golog.Error("ClientConn", "handleLoadData", loadErr.Error(), c.connectionId,
+			"sql", sqlparser.String(stmt))
+		return loadErr
+	}
+
+	c.affectedRows = int64(r.AffectedRows)
+	return c.writeOK(r)
+}
+
+//the value of shard key is a number if it is an integer, so that the row is
+//routed in the same way as the number in sql
+func loadDataValue(v []byte, isKey bool) sqlparser.ValExpr {
+	if v == nil {
+		return &sqlparser.NullVal{}
+	}
+	if isKey {
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil &&
+			strconv.FormatInt(n, 10) == string(v) {
+			return sqlparser.NumVal(v)
+		}
+	}
+	return sqlparser.StrVal(v)
+}
+
+//insert a batch of rows by the plan of insert or replace, the rows of the
+//table not sharded are inserted in the default node
+func (c *ClientConn) executeLoadData(stmt *sqlparser.LoadData, rule *router.Rule,
+	columns sqlparser.Columns, rows sqlparser.Values) ([]*mysql.Result, error) {
+	var insert sqlparser.Statement
+	if stmt.DupOpt == sqlparser.AST_LOAD_REPLACE {
+		insert = &sqlparser.Replace{
+			Comments: stmt.Comments,
+			Table:    stmt.Table,
+			Columns:  columns,
+			Rows:     rows,
+		}
+	} else {
+		insert = &sqlparser.Insert{
+			Comments: stmt.Comments,
+			Ignore:   stmt.DupOpt,
+			Table:    stmt.Table,
+			Columns:  columns,
+			Rows:     rows,
+		}
+	}
+
+	if rule != c.schema.rule.DefaultRule {
+		plan, err := c.schema.rule.BuildPlan(c.db, insert)
+		if err != nil {
+			return nil, err
+		}
+		return c.executeExecPlan(plan, nil)
+	}
+
+	if len(rule.Nodes) == 0 {
+		return nil, errors.ErrNoDefaultNode
+	}
+	n := c.proxy.GetNode(rule.Nodes[0])
+	if err := n.CheckMasterWritable(); err != nil {
+		return nil, err
+	}
+	conn, err := c.getBackendConn(n, false, rule.DB)
+	defer c.closeConn(conn, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := c.executeInNode(conn, sqlparser.String(insert), nil)
+	c.collectWarnings(conn, n.Cfg.Name, string(stmt.Table.Name), err)
+	if err != nil {
+		return nil, err
+	}
+	c.markWrite(n, conn)
+
+	return rs, nil
+}
+
+//getLoadDataColumns gets the columns of sharded table in order by the fields
+//of an empty select in one of the shards
+func (c *ClientConn) getLoadDataColumns(stmt *sqlparser.LoadData) (sqlparser.Columns, error) {
+	sel, err := sqlparser.Parse(fmt.Sprintf("select * from %s limit 0", sqlparser.String(stmt.Table)))
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.schema.rule.BuildPlan(c.db, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	for nodeName, sqls := range plan.RewrittenSqls {
+		if len(sqls) == 0 {
+			continue
+		}
+		n := c.proxy.GetNode(nodeName)
+		if n == nil {
+			return nil, errors.ErrNoRouteNode
+		}
+		conn, err := c.getBackendConn(n, false, "")
+		if err != nil {
+			return nil, err
+		}
+		rs, err := c.executeInNode(conn, sqls[0], nil)
+		c.closeConn(conn, false)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := make(sqlparser.Columns, 0, len(rs[0].Fields))
+		for _, f := range rs[0].Fields {
+			columns = append(columns, &sqlparser.NonStarExpr{
+				Expr: &sqlparser.ColName{Name: f.Name},
+			})
+		}
+		return columns, nil
+	}
+	return nil, errors.ErrNoPlan
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/proxy/router"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func newTestLoadDataParser(t *testing.T, sql string) *loadDataParser {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newLoadDataParser(stmt.(*sqlparser.LoadData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadDataParser(t *testing.T) {
+	tests := []struct {
+		sql  string
+		file string
+		rows [][][]byte
+	}{
+		{
+			"load data local infile 'a' into table t",
+			"1\ta\\tb\n2\t\\N\n\n3\t\n",
+			[][][]byte{
+				{[]byte("1"), []byte("a\tb")},
+				{[]byte("2"), nil},
+				{[]byte("3"), []byte("")},
+			},
+		},
+		{
+			`load data local infile 'a' into table t fields terminated by ',' optionally enclosed by '"' lines terminated by '\r\n' ignore 1 lines`,
+			"id,name\r\n1,\"a,\"\"b\"\"\"\r\n2,NULL\r\n3,\"NULL\"",
+			[][][]byte{
+				{[]byte("1"), []byte(`a,"b"`)},
+				{[]byte("2"), nil},
+				{[]byte("3"), []byte("NULL")},
+			},
+		},
+		{
+			"load data local infile 'a' into table t fields terminated by '||' escaped by '' lines starting by 'xx' terminated by ';'",
+			"xx1||a\\N;skipped;xx2||b;",
+			[][][]byte{
+				{[]byte("1"), []byte("a\\N")},
+				{[]byte("2"), []byte("b")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		//the file is fed in every size of packet
+		for size := 1; size <= len(test.file); size++ {
+			p := newTestLoadDataParser(t, test.sql)
+			var rows [][][]byte
+			for i := 0; i < len(test.file); i += size {
+				end := i + size
+				if len(test.file) < end {
+					end = len(test.file)
+				}
+				rows = append(rows, p.Feed([]byte(test.file[i:end]), false)...)
+			}
+			rows = append(rows, p.Feed(nil, true)...)
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Fatalf("%s: size %d, got %q", test.sql, size, rows)
+			}
+		}
+	}
+}
+
+func TestNewLoadDataParser(t *testing.T) {
+	for _, sql := range []string{
+		"load data local infile 'a' into table t fields enclosed by 'ab'",
+		"load data local infile 'a' into table t fields terminated by ''",
+	} {
+		stmt, err := sqlparser.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = newLoadDataParser(stmt.(*sqlparser.LoadData)); err == nil {
+			t.Fatal(sql, "must fail")
+		}
+	}
+}
+
+func TestHandleLoadData(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.capability |= mysql.CLIENT_LOCAL_FILES
+	c.schema = &Schema{rule: &router.Router{DefaultRule: router.NewDefaultRule("node1")}}
+
+	stmt, err := sqlparser.Parse("load data local infile '/tmp/a.txt' into table t (id, name)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.handleLoadData(stmt.(*sqlparser.LoadData))
+	}()
+
+	pkg := mysql.NewPacketIO(clientConn)
+	data, err := pkg.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != mysql.LocalInFile_HEADER || string(data[1:]) != "/tmp/a.txt" {
+		t.Fatalf("invalid request %q", data)
+	}
+
+	//the file is read until the empty packet though the row is invalid
+	for _, p := range []string{"1\n", "2\tb\n", ""} {
+		if err = pkg.WritePacket(append(make([]byte, 4), p...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = <-done
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_WARN_TOO_FEW_RECORDS {
+		t.Fatal(err)
+	}
+	//the transaction of load is rolled back
+	if c.status&mysql.SERVER_STATUS_IN_TRANS != 0 || c.curTrans != nil {
+		t.Fatal("transaction of load must be rolled back")
+	}
+
+	c.capability &^= mysql.CLIENT_LOCAL_FILES
+	err = c.handleLoadData(stmt.(*sqlparser.LoadData))
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_NOT_ALLOWED_COMMAND {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+//handleMultiQuery execute the statements in sql one by one, every statement
+//is routed independently. The rest statements are skipped if one fails.
+func (c *ClientConn) handleMultiQuery(sql string) error {
+	if c.capability&mysql.CLIENT_MULTI_STATEMENTS == 0 ||
+		strings.IndexByte(strings.TrimRight(sql, ";"), ';') < 0 {
+		return c.handleQuery(sql)
+	}
+
+	stmts := sqlparser.SplitStatements(sql)
+	if len(stmts) <= 1 {
+		return c.handleQuery(sql)
+	}
+	defer func() {
+		c.moreResults = false
+	}()
+	for i, stmt := range stmts {
+		c.moreResults = i < len(stmts)-1
+		if err := c.handleQuery(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//handleSetOption turn on or off CLIENT_MULTI_STATEMENTS
+func (c *ClientConn) handleSetOption(data []byte) error {
+	if len(data) < 2 {
+		return mysql.NewDefaultError(mysql.ER_MALFORMED_PACKET)
+	}
+	switch binary.LittleEndian.Uint16(data) {
+	case mysql.MYSQL_OPTION_MULTI_STATEMENTS_ON:
+		c.capability |= mysql.CLIENT_MULTI_STATEMENTS
+	case mysql.MYSQL_OPTION_MULTI_STATEMENTS_OFF:
+		c.capability &= ^mysql.CLIENT_MULTI_STATEMENTS
+	default:
+		return mysql.NewDefaultError(mysql.ER_UNKNOWN_COM_ERROR)
+	}
+	return c.writeEOF(c.status)
+}
+
+//set SERVER_MORE_RESULTS_EXISTS if it is not the last statement of multi statements
+func (c *ClientConn) resultStatus(status uint16) uint16 {
+	if c.moreResults {
+		return status | mysql.SERVER_MORE_RESULTS_EXISTS
+	}
+	return status & ^mysql.SERVER_MORE_RESULTS_EXISTS
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+)
+
+//read the status in the last eof packet of a resultset
+func readResultsetStatus(pkg *mysql.PacketIO) (uint16, error) {
+	eofs := 0
+	for {
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			return 0, err
+		}
+		if data[0] == mysql.EOF_HEADER && len(data) == 5 {
+			if eofs++; eofs == 2 {
+				return binary.LittleEndian.Uint16(data[3:]), nil
+			}
+		}
+	}
+}
+
+func TestHandleMultiQuery(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+
+	done := make(chan []uint16, 1)
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		var status []uint16
+		for i := 0; i < 2; i++ {
+			s, err := readResultsetStatus(pkg)
+			if err != nil {
+				t.Error(err)
+				break
+			}
+			status = append(status, s)
+		}
+		done <- status
+	}()
+
+	if err := c.handleMultiQuery("show warnings; show errors;"); err != nil {
+		t.Fatal(err)
+	}
+	status := <-done
+	if len(status) != 2 {
+		t.Fatalf("expect 2 resultsets, got %d", len(status))
+	}
+	if status[0]&mysql.SERVER_MORE_RESULTS_EXISTS == 0 {
+		t.Fatal("more results should be set in the first resultset")
+	}
+	if status[1]&mysql.SERVER_MORE_RESULTS_EXISTS != 0 || c.moreResults {
+		t.Fatal("more results should not be set in the last resultset")
+	}
+}
+
+func TestHandleSetOption(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.ReadPacket()
+	}()
+
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, mysql.MYSQL_OPTION_MULTI_STATEMENTS_OFF)
+	if err := c.handleSetOption(data); err != nil {
+		t.Fatal(err)
+	}
+	if c.capability&mysql.CLIENT_MULTI_STATEMENTS != 0 {
+		t.Fatal("multi statements should be turned off")
+	}
+	if err := c.handleSetOption([]byte{9, 0}); err == nil {
+		t.Fatal("expect error of unknown option")
+	}
+}
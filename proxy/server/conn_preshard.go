@@ -72,6 +72,12 @@ func (c *ClientConn) preHandleShard(sql string) (bool, error) {
 		return false, errors.ErrCmdUnsupport
 	}
 
+	//show warnings or errors of the last statement
+	if s, ok := parseShowWarnings(tokens); ok {
+		return true, c.handleShowWarnings(s)
+	}
+	c.clearWarnings()
+
 	if c.isInTransaction() {
 		executeDB, err = c.GetTransExecDB(tokens, sql)
 	} else {
@@ -93,6 +99,11 @@ func (c *ClientConn) preHandleShard(sql string) (bool, error) {
 	if executeDB == nil {
 		return false, nil
 	}
+	if !executeDB.IsSlave && isWriteSql(tokens) {
+		if err = executeDB.ExecNode.CheckMasterWritable(); err != nil {
+			return false, err
+		}
+	}
 	//get connection in DB
 	conn, err := c.getBackendConn(executeDB.ExecNode, executeDB.IsSlave, executeDB.DB)
 	defer c.closeConn(conn, false)
@@ -101,6 +112,7 @@ func (c *ClientConn) preHandleShard(sql string) (bool, error) {
 	}
 	//execute.sql may be rewritten in getShowExecDB
 	rs, err = c.executeInNode(conn, executeDB.sql, nil)
+	c.collectWarnings(conn, executeDB.ExecNode.Cfg.Name, executeDB.Table, err)
 	if err != nil {
 		return false, err
 	}
@@ -113,6 +125,9 @@ func (c *ClientConn) preHandleShard(sql string) (bool, error) {
 
 	c.lastInsertId = int64(rs[0].InsertId)
 	c.affectedRows = int64(rs[0].AffectedRows)
+	if !executeDB.IsSlave && rs[0].Resultset == nil {
+		c.markWrite(executeDB.ExecNode, conn)
+	}
 
 	if rs[0].Resultset != nil {
 		err = c.writeResultset(c.status, rs[0].Resultset)
@@ -127,6 +142,22 @@ func (c *ClientConn) preHandleShard(sql string) (bool, error) {
 	return true, nil
 }
 
+//the sqls except select, show and explain write in master
+func isWriteSql(tokens []string) bool {
+	for _, token := range tokens {
+		//skip the comments like /*node1*/
+		if token[0] == mysql.COMMENT_PREFIX {
+			continue
+		}
+		switch strings.ToLower(token) {
+		case mysql.TK_STR_SELECT, mysql.TK_STR_SHOW, "desc", "describe", "explain":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
 func (c *ClientConn) GetTransExecDB(tokens []string, sql string) (*ExecuteDB, error) {
 	var err error
 	tokensLen := len(tokens)
@@ -155,7 +186,7 @@ func (c *ClientConn) GetTransExecDB(tokens []string, sql string) (*ExecuteDB, er
 		}
 		return executeDB, nil
 	}
-	if len(c.txConns) == 1 && c.txConns[executeDB.ExecNode] == nil {
+	if len(c.txConns) == 1 && c.txConns[executeDB.ExecNode] == nil && !c.isMultiNodeTrans() {
 		return nil, errors.ErrTransInMulti
 	}
 	return executeDB, nil
@@ -182,6 +213,9 @@ func (c *ClientConn) GetExecDB(tokens []string, sql string) (*ExecuteDB, error)
 				return c.getShowExecDB(sql, tokens, tokensLen)
 			case mysql.TK_ID_TRUNCATE:
 				return c.getTruncateExecDB(sql, tokens, tokensLen)
+			case mysql.TK_ID_LOAD:
+				//the file is requested from client after parsed
+				return nil, nil
 			default:
 				return nil, nil
 			}
@@ -228,6 +262,35 @@ func (c *ClientConn) setExecuteNode(tokens []string, tokensLen int, executeDB *E
 	return nil
 }
 
+//get the execute database for set sql, the global variables are set in
+//the default node, the session variables are tracked after parsed
+func (c *ClientConn) getSetExecDB(sql string, tokens []string, tokensLen int) (*ExecuteDB, error) {
+	if tokensLen < 2 {
+		return nil, nil
+	}
+
+	//SET GLOBAL max_connections = 1000
+	//SET @@global.max_connections = 1000
+	scope := strings.ToLower(tokens[1])
+	if scope != "global" && !strings.HasPrefix(scope, "@@global.") {
+		return nil, nil
+	}
+	//SET GLOBAL TRANSACTION ISOLATION LEVEL SERIALIZABLE
+	//handle by parser
+	if 3 <= tokensLen && strings.ToLower(tokens[2]) == mysql.TK_STR_TRANSACTION {
+		return nil, nil
+	}
+
+	executeDB := new(ExecuteDB)
+	executeDB.sql = sql
+	err := c.setExecuteNode(tokens, tokensLen, executeDB)
+	if err != nil {
+		return nil, err
+	}
+
+	return executeDB, nil
+}
+
 //get the execute database for select sql
 func (c *ClientConn) getSelectExecDB(sql string, tokens []string, tokensLen int) (*ExecuteDB, error) {
 	var ruleDB string
@@ -407,42 +470,6 @@ func (c *ClientConn) getUpdateExecDB(sql string, tokens []string, tokensLen int)
 	return executeDB, nil
 }
 
-//get the execute database for set sql
-func (c *ClientConn) getSetExecDB(sql string, tokens []string, tokensLen int) (*ExecuteDB, error) {
-	executeDB := new(ExecuteDB)
-	executeDB.sql = sql
-
-	//handle three styles:
-	//set autocommit= 0
-	//set autocommit = 0
-	//set autocommit=0
-	if 2 <= len(tokens) {
-		before := strings.Split(sql, "=")
-		//uncleanWorld is 'autocommit' or 'autocommit '
-		uncleanWord := strings.Split(before[0], " ")
-		secondWord := strings.ToLower(uncleanWord[1])
-		if _, ok := mysql.SET_KEY_WORDS[secondWord]; ok {
-			return nil, nil
-		}
-
-		//SET [gobal/session] TRANSACTION ISOLATION LEVEL SERIALIZABLE
-		//ignore this sql
-		if 3 <= len(uncleanWord) {
-			if strings.ToLower(uncleanWord[1]) == mysql.TK_STR_TRANSACTION ||
-				strings.ToLower(uncleanWord[2]) == mysql.TK_STR_TRANSACTION {
-				return nil, errors.ErrIgnoreSQL
-			}
-		}
-	}
-
-	err := c.setExecuteNode(tokens, tokensLen, executeDB)
-	if err != nil {
-		return nil, err
-	}
-
-	return executeDB, nil
-}
-
 //get the execute database for show sql
 //choose slave preferentially
 //tokens[0] is show
@@ -0,0 +1,73 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/hack"
+	"github.com/flike/kingshard/proxy/router"
+)
+
+func TestIsWriteSql(t *testing.T) {
+	sqls := map[string]bool{
+		"select * from t":                false,
+		"/*node1*/select * from t":       false,
+		"SHOW TABLES":                    false,
+		"explain select * from t":        false,
+		"insert into t values(1)":        true,
+		"/*node1*/ update t set a = 1":   true,
+		"create table t(id int)":         true,
+		"alter table t add column a int": true,
+	}
+	for sql, write := range sqls {
+		if isWriteSql(strings.FieldsFunc(sql, hack.IsSqlSep)) != write {
+			t.Fatal(sql)
+		}
+	}
+}
+
+func TestGetSetExecDB(t *testing.T) {
+	c := new(ClientConn)
+	c.proxy = new(Server)
+	node := new(backend.Node)
+	c.proxy.nodes = map[string]*backend.Node{"node1": node}
+	c.schema = &Schema{rule: &router.Router{DefaultRule: router.NewDefaultRule("node1")}}
+
+	sqls := map[string]bool{
+		"set autocommit = 1":        false,
+		"set names utf8":            false,
+		"set session sql_mode = ''": false,
+		"set @a = 1":                false,
+		"set global transaction isolation level serializable": false,
+		"set global max_connections = 1000":                   true,
+		"SET @@GLOBAL.max_connections=1000":                   true,
+	}
+	for sql, forward := range sqls {
+		tokens := strings.FieldsFunc(sql, hack.IsSqlSep)
+		executeDB, err := c.getSetExecDB(sql, tokens, len(tokens))
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if (executeDB != nil) != forward {
+			t.Fatal(sql)
+		}
+		if executeDB != nil && (executeDB.ExecNode != node || executeDB.sql != sql) {
+			t.Fatal(sql, executeDB)
+		}
+	}
+}
@@ -85,12 +85,20 @@ func (c *ClientConn) handleQuery(sql string) (err error) {
 		return c.handleExec(stmt, nil)
 	case *sqlparser.Set:
 		return c.handleSet(v, sql)
+	case *sqlparser.SetTransaction:
+		return c.handleSetTransaction(v)
 	case *sqlparser.Begin:
-		return c.handleBegin()
+		return c.handleBegin(v.Characteristics)
 	case *sqlparser.Commit:
 		return c.handleCommit()
 	case *sqlparser.Rollback:
 		return c.handleRollback()
+	case *sqlparser.Savepoint:
+		return c.handleSavepoint(string(v.Name))
+	case *sqlparser.ReleaseSavepoint:
+		return c.handleReleaseSavepoint(string(v.Name))
+	case *sqlparser.RollbackSavepoint:
+		return c.handleRollbackSavepoint(string(v.Name))
 	case *sqlparser.Admin:
 		if c.user == "root" {
 			return c.handleAdmin(v)
@@ -107,6 +115,8 @@ func (c *ClientConn) handleQuery(sql string) (err error) {
 		return c.handleSimpleSelect(v)
 	case *sqlparser.Truncate:
 		return c.handleExec(stmt, nil)
+	case *sqlparser.LoadData:
+		return c.handleLoadData(v)
 	default:
 		return fmt.Errorf("statement %T not support now", stmt)
 	}
@@ -117,7 +127,7 @@ func (c *ClientConn) handleQuery(sql string) (err error) {
 func (c *ClientConn) getBackendConn(n *backend.Node, fromSlave bool, db string) (co *backend.BackendConn, err error) {
 	if !c.isInTransaction() {
 		if fromSlave {
-			co, err = n.GetSlaveConn()
+			co, err = c.getReadConn(n)
 			if err != nil {
 				co, err = n.GetMasterConn()
 			}
@@ -133,18 +143,24 @@ func (c *ClientConn) getBackendConn(n *backend.Node, fromSlave bool, db string)
 		co, ok = c.txConns[n]
 
 		if !ok {
-			if co, err = n.GetMasterConn(); err != nil {
+			//read only transaction can be executed in slave
+			if fromSlave && c.isReadOnlyTrans() && !c.isXATrans() {
+				co, err = n.GetSlaveConn()
+			} else {
+				co, err = n.GetMasterConn()
+			}
+			if err != nil {
 				return
 			}
 
-			if !c.isAutoCommit() {
-				if err = co.SetAutoCommit(0); err != nil {
-					return
-				}
-			} else {
-				if err = co.Begin(); err != nil {
-					return
-				}
+			if err = c.beginInConn(n, co); err != nil {
+				co.Close()
+				return
+			}
+			//the node joins after the savepoints created
+			if err = c.replaySavepoints(co); err != nil {
+				co.Close()
+				return
 			}
 
 			c.txConns[n] = co
@@ -166,10 +182,68 @@ func (c *ClientConn) getBackendConn(n *backend.Node, fromSlave bool, db string)
 	if err = co.SetCharset(c.charset, c.collation); err != nil {
 		return
 	}
+	if err = co.SetSessionVars(c.sessionVars); err != nil {
+		return
+	}
+	co.SetMemTracker(c.queryMem)
 
 	return
 }
 
+//get more conns of the same db as co to execute sqls in parallel,
+//the first one of the returned conns is co. The sibling conns are set
+//up as getBackendConn does, the characteristics of transaction need not
+//be replayed since no sibling conn is used in transaction.
+//The sibling conns are optional, they are not waited for when the pool is
+//busy, otherwise the queries holding conns may wait for each other.
+func (c *ClientConn) getParallelConns(co *backend.BackendConn, sqlCount int) []*backend.BackendConn {
+	conns := []*backend.BackendConn{co}
+	maxParallel := c.getParallelCount(sqlCount)
+
+	for len(conns) < maxParallel {
+		pc, err := co.TryGetSiblingConn()
+		if err == errors.ErrPoolBusy {
+			//the sqls are executed in the conns got
+			break
+		} else if err != nil {
+			golog.Warn("ClientConn", "getParallelConns", err.Error(), c.connectionId,
+				"addr", co.GetAddr())
+			break
+		}
+		if err = pc.UseDB(co.GetDB()); err != nil {
+			pc.Close()
+			break
+		}
+		if err = pc.SetCharset(c.charset, c.collation); err != nil {
+			pc.Close()
+			break
+		}
+		if err = pc.SetSessionVars(c.sessionVars); err != nil {
+			pc.Close()
+			break
+		}
+		pc.SetMemTracker(c.queryMem)
+		if c.spiller != nil {
+			pc.SetRowHandler(c.spiller.handleRow)
+		}
+		conns = append(conns, pc)
+	}
+
+	return conns
+}
+
+//the number of conns to execute the sqls of one node in parallel
+func (c *ClientConn) getParallelCount(sqlCount int) int {
+	maxParallel := c.proxy.cfg.MaxParallelPerNode
+	if c.isInTransaction() || maxParallel <= 1 || sqlCount <= 1 {
+		return 1
+	}
+	if sqlCount < maxParallel {
+		return sqlCount
+	}
+	return maxParallel
+}
+
 //获取shard的conn，第一个参数表示是不是select
 func (c *ClientConn) getShardConns(fromSlave bool, plan *router.Plan) (map[string]*backend.BackendConn, error) {
 	var err error
@@ -183,7 +257,7 @@ func (c *ClientConn) getShardConns(fromSlave bool, plan *router.Plan) (map[strin
 		nodeIndex := plan.RouteNodeIndexs[i]
 		nodes = append(nodes, c.proxy.GetNode(plan.Rule.Nodes[nodeIndex]))
 	}
-	if c.isInTransaction() {
+	if c.isInTransaction() && !c.isMultiNodeTrans() {
 		if 1 < len(nodes) {
 			return nil, errors.ErrTransInMulti
 		}
@@ -209,11 +283,12 @@ func (c *ClientConn) getShardConns(fromSlave bool, plan *router.Plan) (map[strin
 func (c *ClientConn) executeInNode(conn *backend.BackendConn, sql string, args []interface{}) ([]*mysql.Result, error) {
 	var state string
 	startTime := time.Now().UnixNano()
-	r, err := conn.Execute(sql, args...)
+	r, err := c.executeConn(conn, sql, args)
 	if err != nil {
 		state = "ERROR"
 	} else {
 		state = "OK"
+		c.recordTxSql(conn, sql, args)
 	}
 
 	execTime := float64(time.Now().UnixNano()-startTime) / float64(time.Millisecond)
@@ -255,7 +330,15 @@ func (c *ClientConn) executeInNode(conn *backend.BackendConn, sql string, args [
 	return []*mysql.Result{r}, err
 }
 
-func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn, sqls map[string][]string, args []interface{}) ([]*mysql.Result, error) {
+func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn, plan *router.Plan, args []interface{}) ([]*mysql.Result, error) {
+	return c.executeInMultiNodesWithPolicy(conns, plan, args, nil)
+}
+
+//execute sqls in multi nodes, if policy is not nil, the nodes failed or
+//not finished in the timeout of policy may be skipped
+func (c *ClientConn) executeInMultiNodesWithPolicy(conns map[string]*backend.BackendConn, plan *router.Plan,
+	args []interface{}, policy *ScatterPolicy) ([]*mysql.Result, error) {
+	sqls := plan.RewrittenSqls
 	if len(conns) != len(sqls) {
 		golog.Error("ClientConn", "executeInMultiNodes", errors.ErrConnNotEqual.Error(), c.connectionId,
 			"conns", conns,
@@ -270,8 +353,6 @@ func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn,
 		return nil, errors.ErrNoPlan
 	}
 
-	wg.Add(len(conns))
-
 	resultCount := 0
 	for _, sqlSlice := range sqls {
 		resultCount += len(sqlSlice)
@@ -279,17 +360,30 @@ func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn,
 
 	rs := make([]interface{}, resultCount)
 
-	f := func(rs []interface{}, i int, execSqls []string, co *backend.BackendConn) {
+	var tasks []*scatterTask
+	f := func(rs []interface{}, i int, execSqls []string, argIndexs [][]int, co *backend.BackendConn, t *scatterTask) {
 		var state string
-		for _, v := range execSqls {
+		for k, v := range execSqls {
+			//the query is aborted when results over max_result_bytes
+			if c.queryMem.Exceeded() {
+				break
+			}
+			execArgs := args
+			if argIndexs != nil {
+				execArgs = bindArgs(args, argIndexs[k])
+			}
 			startTime := time.Now().UnixNano()
-			r, err := co.Execute(v, args...)
+			sem := c.proxy.acquireParallel(resultCount)
+			r, err := c.executeConn(co, v, execArgs)
+			releaseParallel(sem)
+			c.collectWarnings(co, t.node, t.tables[k], err)
 			if err != nil {
 				state = "ERROR"
 				rs[i] = err
 			} else {
 				state = "OK"
 				rs[i] = r
+				c.recordTxSql(co, v, execArgs)
 			}
 			execTime := float64(time.Now().UnixNano()-startTime) / float64(time.Millisecond)
 			if c.proxy.logSql[c.proxy.logSqlIndex] != golog.LogSqlOff &&
@@ -325,17 +419,65 @@ func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn,
 
 			i++
 		}
+		t.finish()
 		wg.Done()
 	}
 
 	offset := 0
+	var parallelConns []*backend.BackendConn
+	nodeRanges := make(map[string][2]int, len(conns))
 	for nodeName, co := range conns {
 		s := sqls[nodeName] //[]string
-		go f(rs, offset, s, co)
+
+		tables := plan.RewrittenTables[nodeName]
+		if len(tables) != len(s) {
+			tables = make([]string, len(s))
+		}
+		//the args of every sql in prepared statement
+		argIndexs := plan.RewrittenArgs[nodeName]
+
+		//split the sqls of node into the parallel conns
+		pcs := c.getParallelConns(co, len(s))
+		step := (len(s) + len(pcs) - 1) / len(pcs)
+		for j, pc := range pcs {
+			start := j * step
+			if len(s) <= start {
+				break
+			}
+			end := start + step
+			if len(s) < end {
+				end = len(s)
+			}
+			t := &scatterTask{
+				node:   nodeName,
+				tables: tables[start:end],
+				conn:   pc,
+			}
+			tasks = append(tasks, t)
+			wg.Add(1)
+			if argIndexs != nil {
+				go f(rs, offset+start, s[start:end], argIndexs[start:end], pc, t)
+			} else {
+				go f(rs, offset+start, s[start:end], nil, pc, t)
+			}
+		}
+		parallelConns = append(parallelConns, pcs[1:]...)
+		nodeRanges[nodeName] = [2]int{offset, offset + len(s)}
 		offset += len(s)
 	}
 
-	wg.Wait()
+	timeoutNodes := c.waitScatterTasks(&wg, tasks, policy)
+	for _, pc := range parallelConns {
+		pc.Close()
+	}
+
+	//the query is aborted when results over max_result_bytes, no partial results
+	if policy != nil && policy.PartialResults != PartialResultsFail && !c.queryMem.Exceeded() {
+		return c.buildPartialResults(rs, nodeRanges, timeoutNodes, policy)
+	}
+	for node := range timeoutNodes {
+		return nil, fmt.Errorf("scatter query timeout after %v in node %s", policy.Timeout, node)
+	}
 
 	var err error
 	r := make([]*mysql.Result, resultCount)
@@ -352,6 +494,24 @@ func (c *ClientConn) executeInMultiNodes(conns map[string]*backend.BackendConn,
 	return r, err
 }
 
+//the sqls of COM_STMT_EXECUTE are executed as prepared statements in
+//backend, so that the rows are in binary protocol too
+func (c *ClientConn) executeConn(co *backend.BackendConn, sql string, args []interface{}) (*mysql.Result, error) {
+	if c.binaryRows {
+		return co.ExecutePrepared(sql, args...)
+	}
+	return co.Execute(sql, args...)
+}
+
+//get the args of a sql rewritten from prepared statement by the indexes
+func bindArgs(args []interface{}, indexs []int) []interface{} {
+	sqlArgs := make([]interface{}, len(indexs))
+	for i, index := range indexs {
+		sqlArgs[i] = args[index]
+	}
+	return sqlArgs
+}
+
 func (c *ClientConn) closeConn(conn *backend.BackendConn, rollback bool) {
 	if c.isInTransaction() {
 		return
@@ -409,24 +569,49 @@ func (c *ClientConn) handleExec(stmt sqlparser.Statement, args []interface{}) er
 	if err != nil {
 		return err
 	}
+
+	return c.handleExecPlan(plan, args)
+}
+
+func (c *ClientConn) handleExecPlan(plan *router.Plan, args []interface{}) error {
+	rs, err := c.executeExecPlan(plan, args)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return c.writeOK(nil)
+	}
+
+	return c.mergeExecResult(rs)
+}
+
+//execute the plan of insert, update, delete or replace in master DB,
+//the results are nil if no conn is got
+func (c *ClientConn) executeExecPlan(plan *router.Plan, args []interface{}) ([]*mysql.Result, error) {
 	conns, err := c.getShardConns(false, plan)
 	defer c.closeShardConns(conns, err != nil)
 	if err != nil {
 		golog.Error("ClientConn", "handleExec", err.Error(), c.connectionId)
-		return err
+		return nil, err
 	}
 	if conns == nil {
-		return c.writeOK(nil)
+		return nil, nil
+	}
+	for nodeName := range conns {
+		if err := c.proxy.GetNode(nodeName).CheckMasterWritable(); err != nil {
+			return nil, err
+		}
 	}
 
-	var rs []*mysql.Result
-
-	rs, err = c.executeInMultiNodes(conns, plan.RewrittenSqls, args)
-	if err == nil {
-		err = c.mergeExecResult(rs)
+	rs, err := c.executeInMultiNodes(conns, plan, args)
+	if err != nil {
+		return nil, err
+	}
+	for nodeName, co := range conns {
+		c.markWrite(c.proxy.GetNode(nodeName), co)
 	}
 
-	return err
+	return rs, nil
 }
 
 func (c *ClientConn) mergeExecResult(rs []*mysql.Result) error {
@@ -0,0 +1,142 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+//handleChangeUser re-authenticate the client with the new user of COM_CHANGE_USER,
+//the session is reset and the schema is switched to the new user's
+func (c *ClientConn) handleChangeUser(data []byte) error {
+	//user [null terminated string]
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return mysql.NewDefaultError(mysql.ER_MALFORMED_PACKET)
+	}
+	user := string(data[:end])
+	pos := end + 1
+
+	//auth length and auth
+	if len(data) <= pos || len(data) < pos+1+int(data[pos]) {
+		return mysql.NewDefaultError(mysql.ER_MALFORMED_PACKET)
+	}
+	auth := data[pos+1 : pos+1+int(data[pos])]
+	pos += 1 + len(auth)
+
+	//database [null terminated string]
+	var db string
+	if end = bytes.IndexByte(data[pos:], 0); end >= 0 {
+		db = string(data[pos : pos+end])
+		pos += end + 1
+	}
+
+	//charset [2 bytes], the collation id
+	var collation uint16
+	if pos+2 <= len(data) {
+		collation = binary.LittleEndian.Uint16(data[pos:])
+	}
+	pos += 2
+
+	//auth plugin name
+	var plugin string
+	if c.capability&mysql.CLIENT_PLUGIN_AUTH > 0 && pos < len(data) {
+		if end = bytes.IndexByte(data[pos:], 0); end >= 0 {
+			plugin = string(data[pos : pos+end])
+		} else {
+			plugin = string(data[pos:])
+		}
+	}
+
+	//the old user is kept if auth fails
+	oldUser := c.user
+	c.user = user
+	if err := c.checkAuth(plugin, auth); err != nil {
+		c.user = oldUser
+		return err
+	}
+	if err := c.checkTLS(); err != nil {
+		c.user = oldUser
+		return err
+	}
+
+	c.resetSession()
+	c.setCollation(collation)
+	c.schema = c.proxy.GetSchema(c.user)
+	c.db = ""
+	if 0 < len(db) {
+		if err := c.useDB(db); err != nil {
+			return err
+		}
+	}
+	return c.writeOK(nil)
+}
+
+//setCollation sets the charset and collation by the collation id of client,
+//the default is kept if the collation is unknown
+func (c *ClientConn) setCollation(id uint16) {
+	if math.MaxUint8 < id {
+		return
+	}
+	name, ok := mysql.Collations[mysql.CollationId(id)]
+	if !ok {
+		return
+	}
+	if i := strings.IndexByte(name, '_'); 0 < i {
+		name = name[:i]
+	}
+	c.charset = name
+	c.collation = mysql.CollationId(id)
+}
+
+//handleResetConnection reset the session without re-authentication,
+//the user and database are kept
+func (c *ClientConn) handleResetConnection() error {
+	c.resetSession()
+	return c.writeOK(nil)
+}
+
+//resetSession rollback the transaction and clear the state of session
+func (c *ClientConn) resetSession() {
+	if c.isInTransaction() || 0 < len(c.txConns) {
+		if err := c.rollback(); err != nil {
+			golog.Error("ClientConn", "resetSession", err.Error(), c.connectionId)
+		}
+	}
+
+	c.status = mysql.SERVER_STATUS_AUTOCOMMIT
+	c.charset = mysql.DEFAULT_CHARSET
+	c.collation = mysql.DEFAULT_COLLATION_ID
+
+	c.sessionTrans = transOption{}
+	c.nextTrans = nil
+	c.curTrans = nil
+	c.sessionVars = nil
+	c.txWrites = nil
+	c.lastWrites = nil
+
+	c.lastInsertId = 0
+	c.affectedRows = 0
+	c.clearWarnings()
+
+	c.closeCursors()
+	c.stmts = make(map[uint32]*Stmt)
+}
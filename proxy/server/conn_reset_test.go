@@ -0,0 +1,131 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/mysql"
+)
+
+//read the packets written by ClientConn and return the first byte of them
+func readTestPackets(t *testing.T, conn net.Conn, count int) <-chan []byte {
+	headers := make(chan []byte, 1)
+	go func() {
+		pkg := mysql.NewPacketIO(conn)
+		pkg.Sequence = 1
+		var h []byte
+		for i := 0; i < count; i++ {
+			data, err := pkg.ReadPacket()
+			if err != nil {
+				t.Error(err)
+				break
+			}
+			h = append(h, data[0])
+		}
+		headers <- h
+	}()
+	return headers
+}
+
+func changeUserData(user string, auth []byte, db string, collation mysql.CollationId) []byte {
+	data := append([]byte(user), 0)
+	data = append(data, byte(len(auth)))
+	data = append(data, auth...)
+	data = append(data, db...)
+	data = append(data, 0, byte(collation), 0)
+	data = append(data, mysql.AUTH_NAME...)
+	return append(data, 0)
+}
+
+func TestHandleChangeUser(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 1
+	c.schema = c.proxy.schemas["root"]
+	c.stmts = map[uint32]*Stmt{1: new(Stmt)}
+	c.sessionVars = []backend.SessionVar{{Name: "sql_mode", Value: "''"}}
+	c.lastInsertId = 1
+
+	//the old user is kept if auth fails
+	auth := mysql.CalcPassword(c.salt, []byte("wrong"))
+	if err := c.handleChangeUser(changeUserData("kingshard", auth, "kingshard", mysql.DEFAULT_COLLATION_ID)); err == nil {
+		t.Fatal("expect access denied")
+	}
+	if c.user != "root" || len(c.stmts) != 1 {
+		t.Fatalf("session should be kept, user %s", c.user)
+	}
+
+	headers := readTestPackets(t, clientConn, 1)
+	c.db = "root"
+	auth = mysql.CalcPassword(c.salt, []byte("kingshard"))
+	if err := c.handleChangeUser(changeUserData("kingshard", auth, "", 28)); err != nil {
+		t.Fatal(err)
+	}
+	if h := <-headers; len(h) != 1 || h[0] != mysql.OK_HEADER {
+		t.Fatalf("expect ok packet, got %v", h)
+	}
+	if c.user != "kingshard" || c.db != "" || c.schema != c.proxy.schemas["kingshard"] {
+		t.Fatalf("user %s, db %s is not changed", c.user, c.db)
+	}
+	if c.charset != "gbk" || c.collation != 28 {
+		t.Fatalf("charset %s, collation %d is not changed", c.charset, c.collation)
+	}
+	if len(c.stmts) != 0 || c.sessionVars != nil || c.lastInsertId != 0 {
+		t.Fatal("session is not reset")
+	}
+
+	//the database is checked as use
+	auth = mysql.CalcPassword(c.salt, []byte("nodb"))
+	err := c.handleChangeUser(changeUserData("nodb", auth, "kingshard", 0xff))
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_NO_DB_ERROR {
+		t.Fatal(err)
+	}
+	if c.db != "" || c.charset != mysql.DEFAULT_CHARSET {
+		t.Fatalf("db %s, charset %s", c.db, c.charset)
+	}
+}
+
+func TestHandleResetConnection(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 1
+	c.schema = c.proxy.schemas["root"]
+	c.stmts = map[uint32]*Stmt{1: new(Stmt)}
+	c.sessionVars = []backend.SessionVar{{Name: "sql_mode", Value: "''"}}
+	c.lastInsertId = 1
+	c.db = "kingshard"
+	c.charset = "gbk"
+
+	headers := readTestPackets(t, clientConn, 1)
+	if err := c.handleResetConnection(); err != nil {
+		t.Fatal(err)
+	}
+	if h := <-headers; len(h) != 1 || h[0] != mysql.OK_HEADER {
+		t.Fatalf("expect ok packet, got %v", h)
+	}
+	if c.user != "root" || c.db != "kingshard" {
+		t.Fatal("user and db should be kept")
+	}
+	if len(c.stmts) != 0 || c.sessionVars != nil || c.charset != mysql.DEFAULT_CHARSET {
+		t.Fatal("session is not reset")
+	}
+}
@@ -16,6 +16,7 @@ package server
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 
 	"github.com/flike/kingshard/core/errors"
@@ -61,6 +62,101 @@ func formatValue(value interface{}) ([]byte, error) {
 	}
 }
 
+//format the row in binary protocol, which is the protocol of the rows
+//returned by COM_STMT_EXECUTE
+func formatBinaryRow(fields []*mysql.Field, values []interface{}) ([]byte, error) {
+	nullBitmapLen := (len(fields) + 7 + 2) >> 3
+	row := make([]byte, 1+nullBitmapLen, 1+nullBitmapLen+len(fields)*8)
+	row[0] = mysql.OK_HEADER
+
+	for i, field := range fields {
+		if values[i] == nil || field.Type == mysql.MYSQL_TYPE_NULL {
+			row[1+(i+2)>>3] |= 1 << (uint(i+2) % 8)
+			continue
+		}
+
+		b, err := formatValue(values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		switch field.Type {
+		case mysql.MYSQL_TYPE_TINY:
+			n, err := parseBinaryInt(field, b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, byte(n))
+		case mysql.MYSQL_TYPE_SHORT, mysql.MYSQL_TYPE_YEAR:
+			n, err := parseBinaryInt(field, b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, mysql.Uint16ToBytes(uint16(n))...)
+		case mysql.MYSQL_TYPE_INT24, mysql.MYSQL_TYPE_LONG:
+			n, err := parseBinaryInt(field, b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, mysql.Uint32ToBytes(uint32(n))...)
+		case mysql.MYSQL_TYPE_LONGLONG:
+			n, err := parseBinaryInt(field, b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, mysql.Uint64ToBytes(n)...)
+		case mysql.MYSQL_TYPE_FLOAT:
+			f, err := strconv.ParseFloat(hack.String(b), 64)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, mysql.Uint32ToBytes(math.Float32bits(float32(f)))...)
+		case mysql.MYSQL_TYPE_DOUBLE:
+			f, err := strconv.ParseFloat(hack.String(b), 64)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, mysql.Uint64ToBytes(math.Float64bits(f))...)
+		case mysql.MYSQL_TYPE_DATE, mysql.MYSQL_TYPE_NEWDATE,
+			mysql.MYSQL_TYPE_TIMESTAMP, mysql.MYSQL_TYPE_DATETIME:
+			d, err := mysql.ParseBinaryDateTime(b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, d...)
+		case mysql.MYSQL_TYPE_TIME:
+			d, err := mysql.ParseBinaryTime(b)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, d...)
+		default:
+			row = append(row, mysql.PutLengthEncodedString(b)...)
+		}
+	}
+
+	return row, nil
+}
+
+func parseBinaryInt(field *mysql.Field, b []byte) (uint64, error) {
+	if field.Flag&mysql.UNSIGNED_FLAG != 0 {
+		if n, err := strconv.ParseUint(hack.String(b), 10, 64); err == nil {
+			return n, nil
+		}
+	} else {
+		if n, err := strconv.ParseInt(hack.String(b), 10, 64); err == nil {
+			return uint64(n), nil
+		}
+	}
+
+	//the value merged from the integers may be float, such as sum
+	f, err := strconv.ParseFloat(hack.String(b), 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(int64(f)), nil
+}
+
 func formatField(field *mysql.Field, value interface{}) error {
 	switch value.(type) {
 	case int8, int16, int32, int64, int:
@@ -134,6 +230,13 @@ func (c *ClientConn) buildResultset(fields []*mysql.Field, names []string, value
 			row = append(row, mysql.PutLengthEncodedString(b)...)
 		}
 
+		if c.binaryRows {
+			//the fields are assigned in the first row
+			if row, err = formatBinaryRow(r.Fields, vs); err != nil {
+				return nil, err
+			}
+		}
+
 		r.RowDatas = append(r.RowDatas, row)
 	}
 	//assign the values to the result
@@ -148,42 +251,51 @@ func (c *ClientConn) writeResultset(status uint16, r *mysql.Resultset) error {
 	data := make([]byte, 4, 512)
 	var err error
 
-	columnLen := mysql.PutLengthEncodedInt(uint64(len(r.Fields)))
-
-	data = append(data, columnLen...)
-	total, err = c.writePacketBatch(total, data, false)
+	total, err = c.writeFieldsBatch(total, status, r)
 	if err != nil {
 		return err
 	}
 
-	for _, v := range r.Fields {
+	for _, v := range r.RowDatas {
 		data = data[0:4]
-		data = append(data, v.Dump()...)
+		data = append(data, v...)
 		total, err = c.writePacketBatch(total, data, false)
 		if err != nil {
 			return err
 		}
 	}
 
-	total, err = c.writeEOFBatch(total, status, false)
+	total, err = c.writeEOFBatch(total, status, true)
+	total = nil
 	if err != nil {
 		return err
 	}
 
-	for _, v := range r.RowDatas {
+	return nil
+}
+
+//writeFieldsBatch writes the column count, the column definitions and the EOF
+//which close them
+func (c *ClientConn) writeFieldsBatch(total []byte, status uint16, r *mysql.Resultset) ([]byte, error) {
+	data := make([]byte, 4, 512)
+	var err error
+
+	columnLen := mysql.PutLengthEncodedInt(uint64(len(r.Fields)))
+
+	data = append(data, columnLen...)
+	total, err = c.writePacketBatch(total, data, false)
+	if err != nil {
+		return total, err
+	}
+
+	for _, v := range r.Fields {
 		data = data[0:4]
-		data = append(data, v...)
+		data = append(data, v.Dump()...)
 		total, err = c.writePacketBatch(total, data, false)
 		if err != nil {
-			return err
+			return total, err
 		}
 	}
 
-	total, err = c.writeEOFBatch(total, status, true)
-	total = nil
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return c.writeEOFBatch(total, status, false)
 }
@@ -0,0 +1,93 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+)
+
+//the last write of session in a node
+type lastWrite struct {
+	time time.Time
+	gtid string //the gtid set executed by master after the write
+}
+
+//mark the node is written by session, the reads after it are routed
+//to master. The write in transaction is recorded when committed.
+func (c *ClientConn) markWrite(n *backend.Node, co *backend.BackendConn) {
+	if c.schema.readMasterAfterWrite == 0 {
+		return
+	}
+	if c.isInTransaction() {
+		if c.txWrites == nil {
+			c.txWrites = make(map[*backend.Node]bool)
+		}
+		c.txWrites[n] = true
+		return
+	}
+	c.recordWrite(n, co)
+}
+
+func (c *ClientConn) recordWrite(n *backend.Node, co *backend.BackendConn) {
+	w := &lastWrite{time: time.Now()}
+	if 0 < c.schema.waitGtidTimeout {
+		gtid, err := co.GetExecutedGtid()
+		if err != nil {
+			golog.Warn("ClientConn", "recordWrite", err.Error(), c.connectionId,
+				"node", n.Cfg.Name)
+		}
+		w.gtid = gtid
+	}
+
+	if c.lastWrites == nil {
+		c.lastWrites = make(map[string]*lastWrite)
+	}
+	c.lastWrites[n.Cfg.Name] = w
+}
+
+//record the writes of transaction after committed
+func (c *ClientConn) recordTxWrites() {
+	for n := range c.txWrites {
+		if co, ok := c.txConns[n]; ok {
+			c.recordWrite(n, co)
+		}
+	}
+	c.txWrites = nil
+}
+
+//get the conn for read out of transaction, it is the master conn if the
+//node is written in read_master_after_write seconds, unless the slave has
+//executed the gtid of the write in wait_gtid_timeout
+func (c *ClientConn) getReadConn(n *backend.Node) (*backend.BackendConn, error) {
+	w := c.lastWrites[n.Cfg.Name]
+	if w == nil || c.schema.readMasterAfterWrite < time.Since(w.time) {
+		return n.GetSlaveConn()
+	}
+
+	if 0 < len(w.gtid) {
+		co, err := n.GetSlaveConn()
+		if err == nil {
+			ok, err := co.WaitExecutedGtid(w.gtid, c.schema.waitGtidTimeout)
+			if err == nil && ok {
+				return co, nil
+			}
+			co.Close()
+		}
+	}
+	return n.GetMasterConn()
+}
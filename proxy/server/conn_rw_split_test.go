@@ -0,0 +1,56 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/mysql"
+)
+
+func TestMarkWrite(t *testing.T) {
+	c := new(ClientConn)
+	c.schema = &Schema{}
+	c.status = mysql.SERVER_STATUS_AUTOCOMMIT
+	n := &backend.Node{Cfg: config.NodeConfig{Name: "node1"}}
+
+	//disabled
+	c.markWrite(n, nil)
+	if len(c.lastWrites) != 0 {
+		t.Fatal(c.lastWrites)
+	}
+
+	c.schema.readMasterAfterWrite = time.Second
+	c.markWrite(n, nil)
+	if w := c.lastWrites["node1"]; w == nil || time.Second < time.Since(w.time) {
+		t.Fatal(c.lastWrites)
+	}
+
+	//recorded after committed
+	c.lastWrites = nil
+	c.status |= mysql.SERVER_STATUS_IN_TRANS
+	c.txConns = map[*backend.Node]*backend.BackendConn{n: nil}
+	c.markWrite(n, nil)
+	if len(c.lastWrites) != 0 || !c.txWrites[n] {
+		t.Fatal(c.lastWrites, c.txWrites)
+	}
+	c.recordTxWrites()
+	if c.lastWrites["node1"] == nil || c.txWrites != nil {
+		t.Fatal(c.lastWrites, c.txWrites)
+	}
+}
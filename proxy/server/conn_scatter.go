@@ -0,0 +1,229 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+const (
+	//the query fails if any node fails or timeout
+	PartialResultsFail = "fail"
+	//skip the node and add a warning
+	PartialResultsWarn = "warn"
+	//skip the node and add a note
+	PartialResultsSkip = "skip"
+
+	//hint such as /*scatter_timeout=100,partial_results=warn*/
+	ScatterTimeoutHint = "scatter_timeout"
+	PartialResultsHint = "partial_results"
+)
+
+//the policy of scatter select when some nodes fail or timeout
+type ScatterPolicy struct {
+	Timeout        time.Duration //0 means no timeout
+	PartialResults string
+}
+
+func checkPartialResults(policy string) error {
+	switch policy {
+	case "", PartialResultsFail, PartialResultsWarn, PartialResultsSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid partial_results %s", policy)
+}
+
+//get the policy from the schema config and the hint of stmt,
+//return nil if the default policy is used
+func (c *ClientConn) getScatterPolicy(stmt *sqlparser.Select) (*ScatterPolicy, error) {
+	//the conns in transaction can not be interrupted
+	if c.isInTransaction() {
+		return nil, nil
+	}
+
+	policy := &ScatterPolicy{
+		Timeout:        c.schema.scatterTimeout,
+		PartialResults: c.schema.partialResults,
+	}
+
+	for _, comment := range stmt.Comments {
+		hint := strings.Trim(string(comment), "/* ")
+		for _, item := range strings.Split(hint, ",") {
+			kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			value := strings.ToLower(strings.TrimSpace(kv[1]))
+			switch key {
+			case ScatterTimeoutHint:
+				ms, err := strconv.Atoi(value)
+				if err != nil || ms < 0 {
+					return nil, fmt.Errorf("invalid %s hint %s", ScatterTimeoutHint, value)
+				}
+				policy.Timeout = time.Duration(ms) * time.Millisecond
+			case PartialResultsHint:
+				if err := checkPartialResults(value); err != nil {
+					return nil, err
+				}
+				policy.PartialResults = value
+			}
+		}
+	}
+
+	if len(policy.PartialResults) == 0 {
+		policy.PartialResults = PartialResultsFail
+	}
+	if policy.Timeout == 0 && policy.PartialResults == PartialResultsFail {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+//the sqls executed by one conn of a node
+type scatterTask struct {
+	sync.Mutex
+	node     string
+	tables   []string //the sub table of every sql
+	conn     *backend.BackendConn
+	finished bool
+}
+
+func (t *scatterTask) finish() {
+	t.Lock()
+	t.finished = true
+	t.Unlock()
+}
+
+//interrupt the conn if the task is not finished, return false if finished.
+//The check and the interrupt are in the lock, so the conn of a finished
+//task is never interrupted.
+func (t *scatterTask) interrupt() bool {
+	t.Lock()
+	defer t.Unlock()
+	if t.finished {
+		return false
+	}
+	t.conn.Interrupt()
+	return true
+}
+
+//wait all the tasks finish, the tasks not finished in the timeout of policy
+//will be interrupted, return the nodes of these tasks
+func (c *ClientConn) waitScatterTasks(wg *sync.WaitGroup, tasks []*scatterTask,
+	policy *ScatterPolicy) map[string]bool {
+	if policy == nil || policy.Timeout == 0 {
+		wg.Wait()
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(policy.Timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+	}
+
+	timeoutNodes := make(map[string]bool)
+	for _, t := range tasks {
+		if t.interrupt() {
+			timeoutNodes[t.node] = true
+		}
+	}
+	<-done
+
+	return timeoutNodes
+}
+
+//drop the results of nodes which failed or timeout, and add a warning for each
+//of them. Return the first error if all the nodes failed.
+func (c *ClientConn) buildPartialResults(rs []interface{}, nodeRanges map[string][2]int,
+	timeoutNodes map[string]bool, policy *ScatterPolicy) ([]*mysql.Result, error) {
+	level := WarnLevelWarning
+	if policy.PartialResults == PartialResultsSkip {
+		level = WarnLevelNote
+	}
+
+	//make the order of results and warnings stable
+	nodes := make([]string, 0, len(nodeRanges))
+	for node := range nodeRanges {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var firstErr error
+	r := make([]*mysql.Result, 0, len(rs))
+	for _, node := range nodes {
+		start, end := nodeRanges[node][0], nodeRanges[node][1]
+
+		var err error
+		for i := start; i < end; i++ {
+			if e, ok := rs[i].(error); ok {
+				err = e
+				break
+			}
+			if rs[i] == nil {
+				err = fmt.Errorf("result is empty")
+				break
+			}
+		}
+		if timeoutNodes[node] {
+			err = fmt.Errorf("scatter query timeout after %v", policy.Timeout)
+		}
+
+		if err == nil {
+			for i := start; i < end; i++ {
+				r = append(r, rs[i].(*mysql.Result))
+			}
+			continue
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+		msg := fmt.Sprintf("node %s is skipped: %s", node, err.Error())
+		golog.Warn("ClientConn", "buildPartialResults", msg, c.connectionId)
+		c.addWarning(&Warning{
+			Level:   level,
+			Code:    mysql.ER_UNKNOWN_ERROR,
+			Message: msg,
+			Node:    node,
+		})
+	}
+
+	if len(r) == 0 {
+		return nil, firstErr
+	}
+	return r, nil
+}
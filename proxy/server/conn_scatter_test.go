@@ -0,0 +1,93 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func TestScatterPolicyHint(t *testing.T) {
+	c := new(ClientConn)
+	c.status = mysql.SERVER_STATUS_AUTOCOMMIT
+	c.schema = &Schema{scatterTimeout: time.Second}
+
+	sql := "select /*scatter_timeout=100,partial_results=warn*/ * from t"
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := c.getScatterPolicy(stmt.(*sqlparser.Select))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Timeout != 100*time.Millisecond || policy.PartialResults != PartialResultsWarn {
+		t.Fatalf("invalid policy %v", policy)
+	}
+
+	//the schema config
+	stmt, _ = sqlparser.Parse("select * from t")
+	policy, err = c.getScatterPolicy(stmt.(*sqlparser.Select))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Timeout != time.Second || policy.PartialResults != PartialResultsFail {
+		t.Fatalf("invalid policy %v", policy)
+	}
+
+	stmt, _ = sqlparser.Parse("select /*partial_results=none*/ * from t")
+	if _, err = c.getScatterPolicy(stmt.(*sqlparser.Select)); err == nil {
+		t.Fatal("must be invalid partial_results")
+	}
+}
+
+func TestBuildPartialResults(t *testing.T) {
+	c := new(ClientConn)
+	r1 := &mysql.Result{}
+	r2 := &mysql.Result{}
+	rs := []interface{}{r1, r2, fmt.Errorf("node2 error"), nil}
+	nodeRanges := map[string][2]int{
+		"node1": [2]int{0, 2},
+		"node2": [2]int{2, 3},
+		"node3": [2]int{3, 4},
+	}
+	timeoutNodes := map[string]bool{"node3": true}
+
+	policy := &ScatterPolicy{Timeout: time.Second, PartialResults: PartialResultsSkip}
+	r, err := c.buildPartialResults(rs, nodeRanges, timeoutNodes, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r) != 2 || r[0] != r1 || r[1] != r2 {
+		t.Fatalf("invalid results %v", r)
+	}
+	if len(c.warnings) != 2 || c.warnings[0].Level != WarnLevelNote {
+		t.Fatalf("invalid warnings %v", c.warnings)
+	}
+}
+
+func TestScatterTaskInterrupt(t *testing.T) {
+	//the conn of a finished task is never touched
+	task := &scatterTask{node: "node1"}
+	task.finish()
+	if task.interrupt() {
+		t.Fatal("finished task interrupted")
+	}
+}
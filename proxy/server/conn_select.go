@@ -24,6 +24,7 @@ import (
 	"github.com/flike/kingshard/core/golog"
 	"github.com/flike/kingshard/core/hack"
 	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/proxy/router"
 	"github.com/flike/kingshard/sqlparser"
 )
 
@@ -97,11 +98,45 @@ func (c *ClientConn) writeFieldList(status uint16, fs []*mysql.Field) error {
 
 //处理select语句
 func (c *ClientConn) handleSelect(stmt *sqlparser.Select, args []interface{}) error {
-	var fromSlave bool = true
 	plan, err := c.schema.rule.BuildPlan(c.db, stmt)
 	if err != nil {
 		return err
 	}
+
+	return c.handleSelectPlan(stmt, plan, args)
+}
+
+func (c *ClientConn) handleSelectPlan(stmt *sqlparser.Select, plan *router.Plan, args []interface{}) error {
+	//spill the rows into temp files while reading if over the budget
+	c.spiller = c.newRowSpiller(stmt)
+	if c.spiller != nil {
+		defer func() {
+			c.spiller.Close()
+			c.spiller = nil
+		}()
+	}
+
+	rs, err := c.executeSelectPlan(stmt, plan, args)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		r := c.newEmptyResultset(stmt)
+		return c.writeResultset(c.status, r)
+	}
+
+	err = c.mergeSelectResult(rs, stmt)
+	if err != nil {
+		golog.Error("ClientConn", "handleSelect", err.Error(), c.connectionId)
+	}
+
+	return err
+}
+
+//execute the select in shards, return nil if no shard to execute
+func (c *ClientConn) executeSelectPlan(stmt *sqlparser.Select, plan *router.Plan,
+	args []interface{}) ([]*mysql.Result, error) {
+	var fromSlave bool = true
 	if 0 < len(stmt.Comments) {
 		comment := string(stmt.Comments[0])
 		if 0 < len(comment) && strings.ToLower(comment) == MasterComment {
@@ -109,46 +144,59 @@ func (c *ClientConn) handleSelect(stmt *sqlparser.Select, args []interface{}) er
 		}
 	}
 
+	policy, err := c.getScatterPolicy(stmt)
+	if err != nil {
+		return nil, err
+	}
+
 	conns, err := c.getShardConns(fromSlave, plan)
 	if err != nil {
 		golog.Error("ClientConn", "handleSelect", err.Error(), c.connectionId)
-		return err
+		return nil, err
 	}
 	if conns == nil {
-		r := c.newEmptyResultset(stmt)
-		return c.writeResultset(c.status, r)
+		return nil, nil
 	}
 
-	var rs []*mysql.Result
-	rs, err = c.executeInMultiNodes(conns, plan.RewrittenSqls, args)
-	c.closeShardConns(conns, false)
-	if err != nil {
-		golog.Error("ClientConn", "handleSelect", err.Error(), c.connectionId)
-		return err
+	if c.spiller != nil {
+		for _, co := range conns {
+			co.SetRowHandler(c.spiller.handleRow)
+		}
 	}
 
-	err = c.mergeSelectResult(rs, stmt)
+	var rs []*mysql.Result
+	rs, err = c.executeInMultiNodesWithPolicy(conns, plan, args, policy)
+	for _, co := range conns {
+		co.SetRowHandler(nil)
+	}
+	c.closeShardConns(conns, false)
 	if err != nil {
 		golog.Error("ClientConn", "handleSelect", err.Error(), c.connectionId)
+		return nil, err
 	}
 
-	return err
+	return rs, nil
 }
 
 func (c *ClientConn) mergeSelectResult(rs []*mysql.Result, stmt *sqlparser.Select) error {
-	var r *mysql.Result
-	var err error
-
-	if len(stmt.GroupBy) == 0 {
-		r, err = c.buildSelectOnlyResult(rs, stmt)
-	} else {
-		//group by
-		r, err = c.buildSelectGroupByResult(rs, stmt)
+	//the rows are spilled while reading
+	if c.spiller != nil && c.spiller.sorter != nil {
+		return c.writeSpilledRows(rs, stmt)
 	}
+
+	r, err := c.buildSelectResult(rs, stmt)
 	if err != nil {
 		return err
 	}
 
+	//sort the rows with temp files if over the memory budget
+	if stmt.OrderBy != nil && 0 < c.queryMemBudget() &&
+		c.queryMemBudget() < r.Resultset.MemSize() {
+		if spilled, err := c.writeSpilledResultset(r, stmt); spilled {
+			return err
+		}
+	}
+
 	c.sortSelectResult(r.Resultset, stmt)
 	//to do, add log here, sort may error because order by key not exist in resultset fields
 
@@ -159,6 +207,28 @@ func (c *ClientConn) mergeSelectResult(rs []*mysql.Result, stmt *sqlparser.Selec
 	return c.writeResultset(r.Status, r.Resultset)
 }
 
+//merge the results of shards in memory, the rows are sorted and limited
+func (c *ClientConn) buildMergedSelectResult(rs []*mysql.Result, stmt *sqlparser.Select) (*mysql.Result, error) {
+	r, err := c.buildSelectResult(rs, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sortSelectResult(r.Resultset, stmt)
+	if err := c.limitSelectResult(r.Resultset, stmt); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *ClientConn) buildSelectResult(rs []*mysql.Result, stmt *sqlparser.Select) (*mysql.Result, error) {
+	if len(stmt.GroupBy) == 0 {
+		return c.buildSelectOnlyResult(rs, stmt)
+	}
+	//group by
+	return c.buildSelectGroupByResult(rs, stmt)
+}
+
 //only process last_inser_id
 func (c *ClientConn) handleSimpleSelect(stmt *sqlparser.SimpleSelect) error {
 	nonStarExpr, _ := stmt.SelectExprs[0].(*sqlparser.NonStarExpr)
@@ -238,6 +308,16 @@ func (c *ClientConn) buildSelectGroupByResult(rs []*mysql.Result,
 //only merge result with aggregate function in group by opt
 func (c *ClientConn) mergeGroupByWithFunc(rs []*mysql.Result, groupByIndexs []int,
 	funcExprs map[int]string) (*mysql.Result, error) {
+	//group by with temp files if over the memory budget
+	if c.spiller != nil && c.spiller.partitioner != nil {
+		return c.groupByPartitions(rs, groupByIndexs, funcExprs, c.spiller.partitioner)
+	}
+	if budget := c.queryMemBudget(); 0 < budget {
+		if memSize := resultsMemSize(rs); budget < memSize {
+			return c.spillGroupBy(rs, groupByIndexs, funcExprs, memSize)
+		}
+	}
+
 	r := rs[0]
 	//load rs into a map, in order to make group
 	resultMap, err := c.loadResultWithFuncIntoMap(rs, groupByIndexs, funcExprs)
@@ -266,6 +346,16 @@ func (c *ClientConn) mergeGroupByWithFunc(rs []*mysql.Result, groupByIndexs []in
 //only merge result without aggregate function in group by opt
 func (c *ClientConn) mergeGroupByWithoutFunc(rs []*mysql.Result,
 	groupByIndexs []int) (*mysql.Result, error) {
+	//group by with temp files if over the memory budget
+	if c.spiller != nil && c.spiller.partitioner != nil {
+		return c.groupByPartitions(rs, groupByIndexs, nil, c.spiller.partitioner)
+	}
+	if budget := c.queryMemBudget(); 0 < budget {
+		if memSize := resultsMemSize(rs); budget < memSize {
+			return c.spillGroupBy(rs, groupByIndexs, nil, memSize)
+		}
+	}
+
 	r := rs[0]
 	//load rs into a map
 	resultMap, err := c.loadResultIntoMap(rs, groupByIndexs)
@@ -433,43 +523,55 @@ func (c *ClientConn) limitSelectResult(r *mysql.Resultset, stmt *sqlparser.Selec
 		return nil
 	}
 
-	var offset, count int64
-	var err error
+	offset, count, err := c.getSelectLimit(stmt)
+	if err != nil {
+		return err
+	}
+	if offset > int64(len(r.Values)) {
+		r.Values = nil
+		r.RowDatas = nil
+		return nil
+	}
+
+	if offset+count > int64(len(r.Values)) {
+		count = int64(len(r.Values)) - offset
+	}
+
+	r.Values = r.Values[offset : offset+count]
+	r.RowDatas = r.RowDatas[offset : offset+count]
+
+	return nil
+}
+
+//get the offset and count of limit, count is -1 if no limit
+func (c *ClientConn) getSelectLimit(stmt *sqlparser.Select) (offset int64, count int64, err error) {
+	if stmt.Limit == nil {
+		return 0, -1, nil
+	}
+
 	if stmt.Limit.Offset == nil {
 		offset = 0
 	} else {
 		if o, ok := stmt.Limit.Offset.(sqlparser.NumVal); !ok {
-			return fmt.Errorf("invalid select limit %s", nstring(stmt.Limit))
+			return 0, 0, fmt.Errorf("invalid select limit %s", nstring(stmt.Limit))
 		} else {
 			if offset, err = strconv.ParseInt(hack.String([]byte(o)), 10, 64); err != nil {
-				return err
+				return 0, 0, err
 			}
 		}
 	}
 
 	if o, ok := stmt.Limit.Rowcount.(sqlparser.NumVal); !ok {
-		return fmt.Errorf("invalid limit %s", nstring(stmt.Limit))
+		return 0, 0, fmt.Errorf("invalid limit %s", nstring(stmt.Limit))
 	} else {
 		if count, err = strconv.ParseInt(hack.String([]byte(o)), 10, 64); err != nil {
-			return err
+			return 0, 0, err
 		} else if count < 0 {
-			return fmt.Errorf("invalid limit %s", nstring(stmt.Limit))
+			return 0, 0, fmt.Errorf("invalid limit %s", nstring(stmt.Limit))
 		}
 	}
-	if offset > int64(len(r.Values)) {
-		r.Values = nil
-		r.RowDatas = nil
-		return nil
-	}
-
-	if offset+count > int64(len(r.Values)) {
-		count = int64(len(r.Values)) - offset
-	}
 
-	r.Values = r.Values[offset : offset+count]
-	r.RowDatas = r.RowDatas[offset : offset+count]
-
-	return nil
+	return offset, count, nil
 }
 
 func (c *ClientConn) buildFuncExprResult(stmt *sqlparser.Select,
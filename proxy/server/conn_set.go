@@ -16,10 +16,12 @@ package server
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/errors"
 	"github.com/flike/kingshard/core/golog"
 	"github.com/flike/kingshard/mysql"
 	"github.com/flike/kingshard/sqlparser"
@@ -28,7 +30,7 @@ import (
 var nstring = sqlparser.String
 
 func (c *ClientConn) handleSet(stmt *sqlparser.Set, sql string) (err error) {
-	if len(stmt.Exprs) != 1 && len(stmt.Exprs) != 2 {
+	if len(stmt.Exprs) == 0 {
 		return fmt.Errorf("must set one item once, not %s", nstring(stmt))
 	}
 
@@ -58,6 +60,9 @@ func (c *ClientConn) handleSet(stmt *sqlparser.Set, sql string) (err error) {
 	k := string(stmt.Exprs[0].Name.Name)
 	switch strings.ToUpper(k) {
 	case `AUTOCOMMIT`, `@@AUTOCOMMIT`, `@@SESSION.AUTOCOMMIT`:
+		if len(stmt.Exprs) != 1 {
+			return fmt.Errorf("must set one item once, not %s", nstring(stmt))
+		}
 		return c.handleSetAutoCommit(stmt.Exprs[0].Expr)
 	case `NAMES`,
 		`CHARACTER_SET_RESULTS`, `@@CHARACTER_SET_RESULTS`, `@@SESSION.CHARACTER_SET_RESULTS`,
@@ -66,13 +71,154 @@ func (c *ClientConn) handleSet(stmt *sqlparser.Set, sql string) (err error) {
 		if len(stmt.Exprs) == 2 {
 			//SET NAMES 'charset_name' COLLATE 'collation_name'
 			return c.handleSetNames(stmt.Exprs[0].Expr, stmt.Exprs[1].Expr)
+		} else if len(stmt.Exprs) != 1 {
+			return fmt.Errorf("must set one item once, not %s", nstring(stmt))
 		}
 		return c.handleSetNames(stmt.Exprs[0].Expr, nil)
 	default:
-		golog.Error("ClientConn", "handleSet", "command not supported",
-			c.connectionId, "sql", sql)
-		return c.writeOK(nil)
+		return c.handleSetSessionVars(stmt)
+	}
+}
+
+//get the name of session variable in SET, "@name" for user variable
+func getSessionVarName(scope string, col *sqlparser.ColName) (string, error) {
+	name := string(col.Name)
+	qualifier := strings.ToLower(string(col.Qualifier))
+	scope = strings.ToLower(scope)
+
+	switch scope {
+	case "", "session", "local":
+	default:
+		return "", fmt.Errorf("set %s variable not supported", scope)
 	}
+
+	switch qualifier {
+	case "":
+		if strings.HasPrefix(name, "@@") {
+			name = name[2:]
+		} else if strings.HasPrefix(name, "@") && 0 < len(scope) {
+			return "", fmt.Errorf("invalid user variable %s", name)
+		}
+	case "@@session", "@@local":
+	default:
+		return "", fmt.Errorf("set variable %s.%s not supported", qualifier, name)
+	}
+
+	//these variables are managed by kingshard
+	if _, ok := mysql.SET_KEY_WORDS[name]; ok || name == "autocommit" {
+		return "", fmt.Errorf("must set %s in a single statement", name)
+	}
+	return name, nil
+}
+
+//the literal of value selected from backend
+func sessionVarValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return "'" + mysql.Escape(string(value)) + "'"
+	case string:
+		return "'" + mysql.Escape(value) + "'"
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func setSessionVar(vars []backend.SessionVar, name string, value string) []backend.SessionVar {
+	for i := range vars {
+		if vars[i].Name == name {
+			vars[i].Value = value
+			return vars
+		}
+	}
+	return append(vars, backend.SessionVar{Name: name, Value: value})
+}
+
+func removeSessionVar(vars []backend.SessionVar, name string) []backend.SessionVar {
+	for i := range vars {
+		if vars[i].Name == name {
+			return append(vars[:i], vars[i+1:]...)
+		}
+	}
+	return vars
+}
+
+//get a conn to evaluate the values of session variables
+func (c *ClientConn) getSessionConn() (*backend.BackendConn, error) {
+	for _, co := range c.txConns {
+		return co, nil
+	}
+
+	rule := c.schema.rule.GetRule(c.db, "*")
+	if len(rule.Nodes) == 0 {
+		return nil, errors.ErrNoDefaultNode
+	}
+	return c.getBackendConn(c.proxy.GetNode(rule.Nodes[0]), false, "")
+}
+
+//the values of session variables are evaluated in backend and recorded
+//as literals, they are replayed in the conns got by getBackendConn
+func (c *ClientConn) handleSetSessionVars(stmt *sqlparser.Set) error {
+	vars := append([]backend.SessionVar(nil), c.sessionVars...)
+	var names, exprs []string
+	for _, e := range stmt.Exprs {
+		name, err := getSessionVarName(stmt.Scope, e.Name)
+		if err != nil {
+			return err
+		}
+
+		switch v := e.Expr.(type) {
+		case *sqlparser.DefaultVal:
+			if strings.HasPrefix(name, "@") {
+				return fmt.Errorf("invalid default value of %s", name)
+			}
+			vars = removeSessionVar(vars, name)
+			continue
+		case *sqlparser.ColName:
+			//SET foreign_key_checks = OFF
+			if len(v.Qualifier) == 0 && !strings.HasPrefix(string(v.Name), "@") {
+				names = append(names, name)
+				exprs = append(exprs, sessionVarValue(v.Name))
+				continue
+			}
+		}
+		names = append(names, name)
+		exprs = append(exprs, nstring(e.Expr))
+	}
+
+	co, err := c.getSessionConn()
+	defer c.closeConn(co, false)
+	if err != nil {
+		return err
+	}
+
+	if 0 < len(exprs) {
+		r, err := co.Execute("select " + strings.Join(exprs, ", "))
+		if err != nil {
+			return err
+		}
+		if r.Resultset == nil || r.RowNumber() != 1 {
+			return fmt.Errorf("invalid values of %s", nstring(stmt))
+		}
+		for i, name := range names {
+			v, err := r.GetValue(0, i)
+			if err != nil {
+				return err
+			}
+			vars = setSessionVar(vars, name, sessionVarValue(v))
+		}
+	}
+
+	//check the variables in backend
+	if err := co.SetSessionVars(vars); err != nil {
+		return err
+	}
+	c.sessionVars = vars
+
+	return c.writeOK(nil)
 }
 
 func (c *ClientConn) handleSetAutoCommit(val sqlparser.ValExpr) error {
@@ -88,18 +234,10 @@ func (c *ClientConn) handleSetAutoCommit(val sqlparser.ValExpr) error {
 	switch strings.ToUpper(flag) {
 	case `1`, `ON`:
 		c.status |= mysql.SERVER_STATUS_AUTOCOMMIT
-		if c.status&mysql.SERVER_STATUS_IN_TRANS > 0 {
-			c.status &= ^mysql.SERVER_STATUS_IN_TRANS
-		}
-		for _, co := range c.txConns {
-			if e := co.SetAutoCommit(1); e != nil {
-				co.Close()
-				c.txConns = make(map[*backend.Node]*backend.BackendConn)
-				return fmt.Errorf("set autocommit error, %v", e)
-			}
-			co.Close()
+		//the transaction is committed implicitly
+		if err := c.commit(); err != nil {
+			return err
 		}
-		c.txConns = make(map[*backend.Node]*backend.BackendConn)
 	case `0`, `OFF`:
 		c.status &= ^mysql.SERVER_STATUS_AUTOCOMMIT
 	default:
@@ -0,0 +1,213 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func TestGetSessionVarName(t *testing.T) {
+	names := map[string]string{
+		"set sql_mode = ''":                 "sql_mode",
+		"set @@sql_mode = ''":               "sql_mode",
+		"set @@session.time_zone = '+8:00'": "time_zone",
+		"set session time_zone = '+8:00'":   "time_zone",
+		"set @A = 1":                        "@a",
+	}
+	for sql, expect := range names {
+		stmt, err := sqlparser.Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		set := stmt.(*sqlparser.Set)
+		name, err := getSessionVarName(set.Scope, set.Exprs[0].Name)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if name != expect {
+			t.Fatalf("%s: expect %s, got %s", sql, expect, name)
+		}
+	}
+
+	invalids := []string{
+		"set global sql_mode = ''",
+		"set @@global.sql_mode = ''",
+		"set session @a = 1",
+		"set sql_mode = '', autocommit = 1",
+	}
+	for _, sql := range invalids {
+		stmt, err := sqlparser.Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		set := stmt.(*sqlparser.Set)
+		ok := true
+		for _, e := range set.Exprs {
+			if _, err := getSessionVarName(set.Scope, e.Name); err != nil {
+				ok = false
+			}
+		}
+		if ok {
+			t.Fatal(sql)
+		}
+	}
+}
+
+func TestSessionVarValue(t *testing.T) {
+	values := map[string]interface{}{
+		"NULL":      nil,
+		"'it\\'s'":  []byte("it's"),
+		"-3":        int64(-3),
+		"0.1":       float64(0.1),
+		"'+08:00'":  "+08:00",
+		"123456789": uint64(123456789),
+	}
+	for expect, v := range values {
+		if s := sessionVarValue(v); s != expect {
+			t.Fatalf("expect %s, got %s", expect, s)
+		}
+	}
+}
+
+func TestSetAutoCommitInXATrans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xa_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	xaLog, err := OpenXALog(path.Join(dir, "xa.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer xaLog.Close()
+
+	b := newFakeBackend(t, nil)
+	defer b.Close()
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newBackendTestConn(serverConn)
+	c.pkg.Sequence = 1
+	c.proxy.xaLog = xaLog
+	c.schema = &Schema{transMode: TransModeXA}
+
+	//the nodes join the transaction after set autocommit = 0
+	c.status &= ^mysql.SERVER_STATUS_AUTOCOMMIT
+	for _, name := range []string{"node1", "node2"} {
+		if _, err := c.getBackendConn(b.newNode(t, name, 2), false, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	xid := c.getTransId()
+
+	headers := readTestPackets(t, clientConn, 1)
+	if err := c.handleSetAutoCommit(sqlparser.NumVal("1")); err != nil {
+		t.Fatal(err)
+	}
+	if h := <-headers; len(h) != 1 || h[0] != mysql.OK_HEADER {
+		t.Fatalf("expect ok packet, got %v", h)
+	}
+	if len(c.txConns) != 0 || c.transId != "" || c.isInTransaction() {
+		t.Fatal("the transaction is not committed")
+	}
+
+	//the branches are committed by two phase commit
+	var prepared, committed int
+	for _, q := range b.Queries() {
+		if strings.HasPrefix(q, "xa prepare '"+xid+"'") {
+			prepared++
+		}
+		if strings.HasPrefix(q, "xa commit '"+xid+"'") {
+			committed++
+		}
+	}
+	if prepared != 2 || committed != 2 {
+		t.Fatalf("queries %v", b.Queries())
+	}
+}
+
+func TestSetAutoCommitInBestEffortTrans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "incident_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "incident.log")
+	incidentLog, err := OpenIncidentLog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer incidentLog.Close()
+
+	b1 := newFakeBackend(t, nil)
+	defer b1.Close()
+	//node2 fails to commit
+	b2 := newFakeBackend(t, func(query string) (*fakeReply, error) {
+		if query == "commit" {
+			return nil, mysql.NewError(mysql.ER_UNKNOWN_ERROR, "connection was bad")
+		}
+		return nil, nil
+	})
+	defer b2.Close()
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newBackendTestConn(serverConn)
+	c.proxy.incidentLog = incidentLog
+	c.schema = &Schema{transMode: TransModeBestEffort}
+
+	c.status &= ^mysql.SERVER_STATUS_AUTOCOMMIT
+	for i, b := range []*fakeBackend{b1, b2} {
+		co, err := c.getBackendConn(b.newNode(t, fmt.Sprintf("node%d", i+1), 2), false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sql := fmt.Sprintf("update t set a=%d", i+1)
+		if _, err := co.Execute(sql); err != nil {
+			t.Fatal(err)
+		}
+		c.recordTxSql(co, sql, nil)
+	}
+
+	err = c.handleSetAutoCommit(sqlparser.NumVal("1"))
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_PARTIAL_COMMIT {
+		t.Fatalf("expect partial commit error, got %v", err)
+	}
+	if len(c.txConns) != 0 || c.txSqls != nil || c.isInTransaction() {
+		t.Fatal("the transaction is not finished")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 ||
+		!strings.HasSuffix(lines[0], `node1 committed "" ["update t set a=1"]`) ||
+		!strings.Contains(lines[1], `node2 failed`) {
+		t.Fatal(lines)
+	}
+}
@@ -0,0 +1,474 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+const (
+	//the max partitions of spilling group by
+	MaxSpillPartitions = 256
+	//the partitions of group by spilled while reading rows, the total
+	//size of rows is unknown then, the partitions over budget are split
+	//when they are grouped
+	StreamSpillPartitions = 32
+	//the max times a partition over budget is split, the rows of the same
+	//group key can not be split
+	MaxSpillSplits = 3
+	//flush the rows to client when buffered bytes over this value
+	spillFlushSize = 64 * 1024
+)
+
+//the max bytes of rows a query can hold in memory, 0 means no limit
+func (c *ClientConn) queryMemBudget() int64 {
+	return c.proxy.cfg.QueryMemBudget
+}
+
+func (c *ClientConn) spillDir() string {
+	if len(c.proxy.cfg.SpillDir) == 0 {
+		return os.TempDir()
+	}
+	return c.proxy.cfg.SpillDir
+}
+
+func resultsMemSize(rs []*mysql.Result) int64 {
+	var size int64
+	for _, r := range rs {
+		if r != nil && r.Resultset != nil {
+			size += r.Resultset.MemSize()
+		}
+	}
+	return size
+}
+
+//rowSpiller spill the rows of a select into temp files while reading
+//them from backends. The rows are kept in results until they are over
+//half of the budget, the other half is left for sorting rows in memory.
+type rowSpiller struct {
+	sync.Mutex
+
+	c      *ClientConn
+	stmt   *sqlparser.Select
+	budget int64
+
+	buffered int64 //the bytes of rows kept in results
+	peak     int64 //the max bytes of rows in memory
+	disabled bool  //the rows can not be spilled
+
+	sorter      *mysql.RowSorter      //order by without group by
+	partitioner *mysql.RowPartitioner //group by
+}
+
+//return nil if the rows of stmt need not be spilled
+func (c *ClientConn) newRowSpiller(stmt *sqlparser.Select) *rowSpiller {
+	budget := c.queryMemBudget()
+	if budget <= 0 {
+		return nil
+	}
+	//the rows of aggregate function without group by are merged into one
+	if len(stmt.GroupBy) == 0 && (stmt.OrderBy == nil || 0 < len(c.getFuncExprs(stmt))) {
+		return nil
+	}
+
+	return &rowSpiller{
+		c:      c,
+		stmt:   stmt,
+		budget: budget,
+	}
+}
+
+func (s *rowSpiller) spilling() bool {
+	return s.sorter != nil || s.partitioner != nil
+}
+
+//handleRow is the backend.RowHandler of the conns of select
+func (s *rowSpiller) handleRow(result *mysql.Result, value []interface{}, row mysql.RowData) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	size := mysql.RowMemSize(value, row)
+	if !s.spilling() && !s.disabled && s.budget/2 < s.buffered+size {
+		if err := s.open(result); err != nil {
+			return false, err
+		}
+	}
+
+	if !s.spilling() {
+		s.buffered += size
+		s.updatePeak()
+		return false, nil
+	}
+
+	if err := s.add(result, value, row); err != nil {
+		return false, err
+	}
+	s.updatePeak()
+	return true, nil
+}
+
+func (s *rowSpiller) open(result *mysql.Result) error {
+	var err error
+	c := s.c
+	if 0 < len(s.stmt.GroupBy) {
+		s.partitioner, err = mysql.NewRowPartitioner(StreamSpillPartitions, c.spillDir())
+		if err != nil {
+			return err
+		}
+		golog.Warn("ClientConn", "spillRows", "spill group by rows", c.connectionId,
+			"budget", s.budget, "partitions", StreamSpillPartitions)
+		return nil
+	}
+
+	sk := make([]mysql.SortKey, len(s.stmt.OrderBy))
+	for i, o := range s.stmt.OrderBy {
+		sk[i].Name = nstring(o.Expr)
+		sk[i].Direction = o.Direction
+	}
+	s.sorter, err = mysql.NewRowSorter(result.FieldNames, sk, s.budget/2, c.spillDir())
+	if err != nil {
+		//order by key not in resultset fields, keep the rows in memory
+		s.disabled = true
+		return nil
+	}
+	golog.Warn("ClientConn", "spillRows", "spill sort rows", c.connectionId,
+		"budget", s.budget)
+	return nil
+}
+
+func (s *rowSpiller) add(result *mysql.Result, value []interface{}, row mysql.RowData) error {
+	if s.sorter != nil {
+		return s.sorter.Add(value, row)
+	}
+
+	//the group by columns are at the end of fields
+	mk, err := s.c.generateMapKey(value[len(result.Fields)-len(s.stmt.GroupBy):])
+	if err != nil {
+		return err
+	}
+	return s.partitioner.Add(mk, value, row)
+}
+
+func (s *rowSpiller) updatePeak() {
+	size := s.buffered
+	if s.sorter != nil {
+		size += s.sorter.MemSize()
+	}
+	if s.peak < size {
+		s.peak = size
+	}
+}
+
+//move the rows kept in results into temp files, and release them
+func (s *rowSpiller) spillResults(rs []*mysql.Result) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, r := range rs {
+		if r == nil || r.Resultset == nil {
+			continue
+		}
+		for i := range r.Values {
+			size := mysql.RowMemSize(r.Values[i], r.RowDatas[i])
+			if err := s.add(r, r.Values[i], r.RowDatas[i]); err != nil {
+				return err
+			}
+			//release the row in memory
+			r.Values[i] = nil
+			r.RowDatas[i] = nil
+			s.buffered -= size
+		}
+		r.Values = nil
+		r.RowDatas = nil
+	}
+	return nil
+}
+
+//sort all the rows of results, the rows are read from iterator in order
+func (s *rowSpiller) sortResults(rs []*mysql.Result) (*mysql.RowIterator, error) {
+	if err := s.spillResults(rs); err != nil {
+		return nil, err
+	}
+	return s.sorter.Iterator()
+}
+
+//Close remove the temp files
+func (s *rowSpiller) Close() {
+	if s.sorter != nil {
+		s.sorter.Close()
+	}
+	if s.partitioner != nil {
+		s.partitioner.Close()
+	}
+}
+
+//write the rows sorted by spiller to client
+func (c *ClientConn) writeSpilledRows(rs []*mysql.Result, stmt *sqlparser.Select) error {
+	offset, count, err := c.getSelectLimit(stmt)
+	if err != nil {
+		return err
+	}
+
+	status := c.status
+	for _, r := range rs {
+		status |= r.Status
+	}
+
+	it, err := c.spiller.sortResults(rs)
+	if err != nil {
+		return err
+	}
+	return c.writeRowIterator(status, rs[0].Fields, it, offset, count)
+}
+
+//group by the results partition by partition, the rows of results are
+//spilled into temp files by the hash of group key first.
+func (c *ClientConn) spillGroupBy(rs []*mysql.Result, groupByIndexs []int,
+	funcExprs map[int]string, memSize int64) (*mysql.Result, error) {
+	budget := c.queryMemBudget()
+	count := spillPartitionCount(memSize, budget)
+
+	p, err := mysql.NewRowPartitioner(count, c.spillDir())
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	golog.Warn("ClientConn", "spillGroupBy", "spill group by rows", c.connectionId,
+		"mem_size", memSize, "budget", budget, "partitions", count)
+
+	return c.groupByPartitions(rs, groupByIndexs, funcExprs, p)
+}
+
+//move the rows of results into partitioner, then group by the rows
+//partition by partition
+func (c *ClientConn) groupByPartitions(rs []*mysql.Result, groupByIndexs []int,
+	funcExprs map[int]string, p *mysql.RowPartitioner) (*mysql.Result, error) {
+	var err error
+	//set status
+	status := c.status
+	for _, r := range rs {
+		status = status | r.Status
+		for i := 0; i < len(r.Values); i++ {
+			mk, err := c.generateMapKey(r.Values[i][groupByIndexs[0]:])
+			if err != nil {
+				return nil, err
+			}
+			if err := p.Add(mk, r.Values[i], r.RowDatas[i]); err != nil {
+				return nil, err
+			}
+			//release the row in memory
+			r.Values[i] = nil
+			r.RowDatas[i] = nil
+		}
+		r.Values = nil
+		r.RowDatas = nil
+	}
+
+	r := rs[0]
+	if err = c.groupPartitions(r, p, groupByIndexs, funcExprs, 0); err != nil {
+		return nil, err
+	}
+	r.Status = status
+
+	return r, nil
+}
+
+//the partitions needed to group rows of size in budget
+func spillPartitionCount(size int64, budget int64) int {
+	count := int(size/budget) + 1
+	if MaxSpillPartitions < count {
+		count = MaxSpillPartitions
+	}
+	return count
+}
+
+//group by the rows of partitioner into r partition by partition, the
+//partition over budget is split into smaller partitions first
+func (c *ClientConn) groupPartitions(r *mysql.Result, p *mysql.RowPartitioner, groupByIndexs []int,
+	funcExprs map[int]string, splits int) error {
+	var err error
+	budget := c.queryMemBudget()
+	part := &mysql.Result{Resultset: &mysql.Resultset{Fields: r.Fields}}
+	for i := 0; i < p.Count(); i++ {
+		if size := p.Size(i); 0 < budget && budget < size && splits < MaxSpillSplits {
+			if err = c.splitPartition(r, p, i, groupByIndexs, funcExprs, splits); err != nil {
+				return err
+			}
+			continue
+		}
+
+		part.Values, part.RowDatas, err = p.Partition(i)
+		if err != nil {
+			return err
+		}
+
+		var resultMap map[string]*ResultRow
+		if len(funcExprs) == 0 {
+			resultMap, err = c.loadResultIntoMap([]*mysql.Result{part}, groupByIndexs)
+		} else {
+			resultMap, err = c.loadResultWithFuncIntoMap([]*mysql.Result{part}, groupByIndexs, funcExprs)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, v := range resultMap {
+			r.Values = append(r.Values, v.Value)
+			r.RowDatas = append(r.RowDatas, v.RowData)
+		}
+	}
+	return nil
+}
+
+func (c *ClientConn) splitPartition(r *mysql.Result, p *mysql.RowPartitioner, index int,
+	groupByIndexs []int, funcExprs map[int]string, splits int) error {
+	budget := c.queryMemBudget()
+	size := p.Size(index)
+	count := spillPartitionCount(size, budget)
+	sub, err := p.Split(index, count, c.spillDir(), func(value []interface{}) (string, error) {
+		return c.generateMapKey(value[groupByIndexs[0]:])
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	golog.Warn("ClientConn", "splitPartition", "split group by partition", c.connectionId,
+		"mem_size", size, "budget", budget, "partitions", count)
+
+	splits++
+	for i := 0; i < sub.Count(); i++ {
+		//the rows are of the same group key, no need to split again
+		if sub.Size(i) == size {
+			splits = MaxSpillSplits
+		}
+	}
+	return c.groupPartitions(r, sub, groupByIndexs, funcExprs, splits)
+}
+
+//sort the rows with temp files and write them to client, return false
+//if the rows can not be sorted by RowSorter
+func (c *ClientConn) writeSpilledResultset(r *mysql.Result, stmt *sqlparser.Select) (bool, error) {
+	sk := make([]mysql.SortKey, len(stmt.OrderBy))
+	for i, o := range stmt.OrderBy {
+		sk[i].Name = nstring(o.Expr)
+		sk[i].Direction = o.Direction
+	}
+
+	offset, count, err := c.getSelectLimit(stmt)
+	if err != nil {
+		return true, err
+	}
+
+	sorter, err := mysql.NewRowSorter(r.FieldNames, sk, c.queryMemBudget(), c.spillDir())
+	if err != nil {
+		//order by key not in resultset fields
+		return false, nil
+	}
+	defer sorter.Close()
+
+	for i := range r.Values {
+		if err := sorter.Add(r.Values[i], r.RowDatas[i]); err != nil {
+			return true, err
+		}
+		//release the row in memory
+		r.Values[i] = nil
+		r.RowDatas[i] = nil
+	}
+	r.Values = nil
+	r.RowDatas = nil
+
+	golog.Warn("ClientConn", "writeSpilledResultset", "spill sort rows", c.connectionId,
+		"runs", sorter.SpillCount(), "budget", c.queryMemBudget())
+
+	it, err := sorter.Iterator()
+	if err != nil {
+		return true, err
+	}
+
+	return true, c.writeRowIterator(r.Status, r.Fields, it, offset, count)
+}
+
+//write the rows from offset in iterator, count < 0 means all the rows
+func (c *ClientConn) writeRowIterator(status uint16, fields []*mysql.Field,
+	it *mysql.RowIterator, offset int64, count int64) error {
+	c.affectedRows = int64(-1)
+	total := make([]byte, 0, 4096)
+	data := make([]byte, 4, 512)
+	var err error
+
+	columnLen := mysql.PutLengthEncodedInt(uint64(len(fields)))
+
+	data = append(data, columnLen...)
+	total, err = c.writePacketBatch(total, data, false)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range fields {
+		data = data[0:4]
+		data = append(data, v.Dump()...)
+		total, err = c.writePacketBatch(total, data, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	total, err = c.writeEOFBatch(total, status, false)
+	if err != nil {
+		return err
+	}
+
+	for i := int64(0); count < 0 || i < offset+count; i++ {
+		_, row, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if i < offset {
+			continue
+		}
+
+		data = data[0:4]
+		data = append(data, row...)
+		total, err = c.writePacketBatch(total, data, false)
+		if err != nil {
+			return err
+		}
+
+		if spillFlushSize < len(total) {
+			if _, err = c.writePacketBatch(total, nil, true); err != nil {
+				return err
+			}
+			total = total[0:0]
+		}
+	}
+
+	total, err = c.writeEOFBatch(total, status, true)
+	total = nil
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func newSpillTestResult() *mysql.Result {
+	r := &mysql.Result{Resultset: &mysql.Resultset{}}
+	r.Fields = []*mysql.Field{{Name: []byte("id")}, {Name: []byte("name")}}
+	r.FieldNames = map[string]int{"id": 0, "name": 1}
+	return r
+}
+
+func TestRowSpillerPeak(t *testing.T) {
+	dir, err := ioutil.TempDir("", "row_spiller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var budget int64 = 4096
+	c := new(ClientConn)
+	c.proxy = new(Server)
+	c.proxy.cfg = &config.Config{QueryMemBudget: budget, SpillDir: dir}
+
+	stmt, err := sqlparser.Parse("select id, name from t order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.newRowSpiller(stmt.(*sqlparser.Select))
+	if s == nil {
+		t.Fatal("spiller not created")
+	}
+	defer s.Close()
+
+	//the rows of two shards are read in turn
+	rs := []*mysql.Result{newSpillTestResult(), newSpillTestResult()}
+	rows := 1000
+	for i := 0; i < rows; i++ {
+		r := rs[i%2]
+		value := []interface{}{int64(rows - i), []byte(fmt.Sprintf("name_%d", i))}
+		row := mysql.RowData(fmt.Sprintf("row_%d", i))
+		handled, err := s.handleRow(r, value, row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !handled {
+			r.Values = append(r.Values, value)
+			r.RowDatas = append(r.RowDatas, row)
+		}
+	}
+
+	if budget < s.peak {
+		t.Fatalf("peak %d over budget %d", s.peak, budget)
+	}
+	if s.sorter == nil || s.sorter.SpillCount() == 0 {
+		t.Fatal("rows not spilled")
+	}
+
+	it, err := s.sortResults(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rs {
+		if r.Values != nil || r.RowDatas != nil {
+			t.Fatal("rows of result not released")
+		}
+	}
+
+	var count int
+	var last int64
+	for {
+		value, _, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if id := value[0].(int64); id < last {
+			t.Fatalf("rows not sorted, %d after %d", id, last)
+		} else {
+			last = id
+		}
+		count++
+	}
+	if count != rows {
+		t.Fatalf("read %d rows, want %d", count, rows)
+	}
+}
+
+func TestSpillWithWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "row_spiller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sql := "select id, name from t order by id"
+	rows := 100
+	b := newFakeBackend(t, func(query string) (*fakeReply, error) {
+		if query == "show warnings" {
+			return &fakeReply{
+				names:  []string{"Level", "Code", "Message"},
+				values: [][]interface{}{{"Warning", int64(1366), "Incorrect integer value"}},
+			}, nil
+		}
+		reply := &fakeReply{names: []string{"id", "name"}, warnings: 1}
+		for i := 0; i < rows; i++ {
+			reply.values = append(reply.values, []interface{}{int64(rows - i), fmt.Sprintf("name_%d", i)})
+		}
+		return reply, nil
+	})
+	defer b.Close()
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newBackendTestConn(serverConn)
+	c.proxy.cfg.QueryMemBudget = 512
+	c.proxy.cfg.SpillDir = dir
+	c.queryMem = backend.NewMemTracker(0, nil)
+
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.spiller = c.newRowSpiller(stmt.(*sqlparser.Select))
+	defer c.spiller.Close()
+
+	co, err := c.getBackendConn(b.newNode(t, "node1", 2), false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer co.Close()
+	co.SetRowHandler(c.spiller.handleRow)
+	defer co.SetRowHandler(nil)
+
+	r, err := c.executeConn(co, sql, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := c.queryMem.Used()
+	c.collectWarnings(co, "node1", "t", nil)
+	if len(c.warnings) != 1 || c.warnings[0].Code != 1366 || c.warnings[0].Node != "node1" {
+		t.Fatalf("warnings %v", c.warnings)
+	}
+	if c.queryMem.Used() != used {
+		t.Fatalf("the warnings are charged to the query, %d after %d", c.queryMem.Used(), used)
+	}
+	if co.GetRowHandler() == nil || co.GetMemTracker() != c.queryMem {
+		t.Fatal("the row handler and mem tracker are not restored")
+	}
+
+	//the warnings are not spilled as rows
+	if !c.spiller.spilling() {
+		t.Fatal("rows not spilled")
+	}
+	it, err := c.spiller.sortResults([]*mysql.Result{r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for {
+		if _, _, err := it.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != rows {
+		t.Fatalf("read %d rows, want %d", count, rows)
+	}
+}
+
+func TestGroupByPartitionsSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "row_spiller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var budget int64 = 8192
+	c := new(ClientConn)
+	c.proxy = new(Server)
+	c.proxy.cfg = &config.Config{QueryMemBudget: budget, SpillDir: dir}
+
+	//select count(*), k from t group by k
+	r := &mysql.Result{Resultset: &mysql.Resultset{}}
+	r.Fields = []*mysql.Field{{Name: []byte("count(*)")}, {Name: []byte("k")}}
+	keys, rows := 50, 1000
+	for i := 0; i < rows; i++ {
+		key := fmt.Sprintf("key_%d", i%keys)
+		r.Values = append(r.Values, []interface{}{int64(1), key})
+		r.RowDatas = append(r.RowDatas, mysql.RowData(key))
+	}
+
+	//the size of rows is unknown when the partitions are created
+	p, err := mysql.NewRowPartitioner(1, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.groupByPartitions([]*mysql.Result{r}, []int{1}, map[int]string{0: "count"}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Size(0) <= budget {
+		t.Fatalf("partition size %d is not over budget", p.Size(0))
+	}
+
+	if len(result.Values) != keys {
+		t.Fatalf("groups %d, want %d", len(result.Values), keys)
+	}
+	for _, v := range result.Values {
+		if v[0].(int64) != int64(rows/keys) {
+			t.Fatalf("group %v, want count %d", v, rows/keys)
+		}
+	}
+
+	//the temp files of split partitions are removed
+	p.Close()
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("temp files not removed: %d", len(files))
+	}
+}
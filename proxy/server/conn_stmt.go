@@ -18,12 +18,15 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"runtime"
 	"strconv"
 	"strings"
 
+	"github.com/flike/kingshard/backend"
 	"github.com/flike/kingshard/core/errors"
 	"github.com/flike/kingshard/core/golog"
 	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/proxy/router"
 	"github.com/flike/kingshard/sqlparser"
 )
 
@@ -49,12 +52,32 @@ type Stmt struct {
 	s sqlparser.Statement
 
 	sql string
+
+	//the stmt of sharding table is routed when executing
+	sharded bool
+	//the plan cached when the stmt has no args
+	plan *router.Plan
+
+	//the result kept for COM_STMT_FETCH when executed with a cursor
+	cursor    *mysql.Resultset
+	cursorPos int
+	//the bytes of cursor are charged until the cursor is closed
+	cursorMem *backend.MemTracker
 }
 
 func (s *Stmt) ResetParams() {
 	s.args = make([]interface{}, s.params)
 }
 
+func (s *Stmt) CloseCursor() {
+	if s.cursorMem != nil {
+		s.cursorMem.Release()
+		s.cursorMem = nil
+	}
+	s.cursor = nil
+	s.cursorPos = 0
+}
+
 func (c *ClientConn) handleStmtPrepare(sql string) error {
 	if c.schema == nil {
 		return mysql.NewDefaultError(mysql.ER_NO_DB_ERROR)
@@ -91,7 +114,31 @@ func (c *ClientConn) handleStmtPrepare(sql string) error {
 	}
 
 	defaultRule := c.schema.rule.GetRule(db, tableName)
+	if defaultRule.Type == router.DefaultRuleType {
+		err = c.prepareInDefaultNode(s, defaultRule, db)
+	} else {
+		err = c.prepareInShards(s)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.id = c.stmtId
+	c.stmtId++
+
+	if err = c.writePrepare(s); err != nil {
+		return err
+	}
+
+	s.ResetParams()
+	c.stmts[s.id] = s
+
+	return nil
+}
 
+//the stmt is prepared in the default node and cached in the backend conn,
+//it is executed in the default node as it is
+func (c *ClientConn) prepareInDefaultNode(s *Stmt, defaultRule *router.Rule, db string) error {
 	n := c.nodes[defaultRule.Nodes[0]]
 
 	co, err := c.getBackendConn(n, false, db)
@@ -107,26 +154,63 @@ func (c *ClientConn) handleStmtPrepare(sql string) error {
 		return fmt.Errorf("prepare error %s", err)
 	}
 
-	t, err := co.Prepare(sql)
+	t, err := co.PrepareCached(s.sql)
 	if err != nil {
 		return fmt.Errorf("prepare error %s", err)
 	}
 	s.params = t.ParamNum()
 	s.columns = t.ColumnNum()
 
-	s.id = c.stmtId
-	c.stmtId++
+	return nil
+}
 
-	if err = c.writePrepare(s); err != nil {
-		return err
+//the stmt of sharding table is routed by the args when executing, the
+//columns are got by preparing the sql rewritten for one sub table
+func (c *ClientConn) prepareInShards(s *Stmt) error {
+	_, argIndexs := sqlparser.PositionalArgs(sqlparser.String(s.s))
+	s.params = len(argIndexs)
+	s.sharded = true
+
+	var err error
+	if s.params == 0 {
+		s.plan, err = c.schema.rule.BuildPreparedPlan(c.db, s.s, nil)
+		if err != nil {
+			return fmt.Errorf("prepare error %s", err)
+		}
 	}
 
-	s.ResetParams()
-	c.stmts[s.id] = s
+	stmt, ok := s.s.(*sqlparser.Select)
+	if !ok {
+		return nil
+	}
 
-	err = co.ClosePrepare(t.GetId())
+	//the args in where, having and limit are not needed for the columns
+	probe := *stmt
+	probe.Where = nil
+	probe.Having = nil
+	probe.Limit = nil
+	plan, err := c.schema.rule.BuildPreparedPlan(c.db, &probe, nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("prepare error %s", err)
+	}
+
+	for nodeName, sqls := range plan.RewrittenSqls {
+		if len(sqls) == 0 {
+			continue
+		}
+		co, err := c.getBackendConn(c.nodes[nodeName], false, plan.Rule.DB)
+		if err != nil {
+			c.closeConn(co, false)
+			return fmt.Errorf("prepare error %s", err)
+		}
+		t, err := co.PrepareCached(sqls[0])
+		c.closeConn(co, false)
+		if err != nil {
+			return fmt.Errorf("prepare error %s", err)
+		}
+		//the group columns are added in the rewritten sql
+		s.columns = t.ColumnNum() - len(stmt.GroupBy)
+		break
 	}
 
 	return nil
@@ -200,6 +284,7 @@ func (c *ClientConn) handleStmtExecute(data []byte) error {
 	if len(data) < 9 {
 		return mysql.ErrMalformPacket
 	}
+	c.clearWarnings()
 
 	pos := 0
 	id := binary.LittleEndian.Uint32(data[0:4])
@@ -213,10 +298,12 @@ func (c *ClientConn) handleStmtExecute(data []byte) error {
 
 	flag := data[pos]
 	pos++
-	//now we only support CURSOR_TYPE_NO_CURSOR flag
-	if flag != 0 {
+	//now we only support CURSOR_TYPE_NO_CURSOR and CURSOR_TYPE_READ_ONLY flag
+	if flag&^mysql.CURSOR_TYPE_READ_ONLY != 0 {
 		return mysql.NewError(mysql.ER_UNKNOWN_ERROR, fmt.Sprintf("unsupported flag %d", flag))
 	}
+	//executing again closes the cursor opened before
+	s.CloseCursor()
 
 	//skip iteration-count, always 1
 	pos += 4
@@ -255,17 +342,27 @@ func (c *ClientConn) handleStmtExecute(data []byte) error {
 
 	var err error
 
+	//the rows are sent in binary protocol
+	c.binaryRows = true
+	defer func() {
+		c.binaryRows = false
+	}()
+
 	switch stmt := s.s.(type) {
 	case *sqlparser.Select:
-		err = c.handlePrepareSelect(stmt, s.sql, s.args)
-	case *sqlparser.Insert:
-		err = c.handlePrepareExec(s.s, s.sql, s.args)
-	case *sqlparser.Update:
-		err = c.handlePrepareExec(s.s, s.sql, s.args)
-	case *sqlparser.Delete:
-		err = c.handlePrepareExec(s.s, s.sql, s.args)
-	case *sqlparser.Replace:
-		err = c.handlePrepareExec(s.s, s.sql, s.args)
+		if s.sharded {
+			err = c.handleShardStmt(s, flag&mysql.CURSOR_TYPE_READ_ONLY != 0)
+		} else if flag&mysql.CURSOR_TYPE_READ_ONLY != 0 {
+			err = c.handlePrepareCursor(s, stmt)
+		} else {
+			err = c.handlePrepareSelect(stmt, s.sql, s.args)
+		}
+	case *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete, *sqlparser.Replace:
+		if s.sharded {
+			err = c.handleShardStmt(s, false)
+		} else {
+			err = c.handlePrepareExec(s.s, s.sql, s.args)
+		}
 	default:
 		err = fmt.Errorf("command %T not supported now", stmt)
 	}
@@ -275,7 +372,170 @@ func (c *ClientConn) handleStmtExecute(data []byte) error {
 	return err
 }
 
+//handleShardStmt routes the stmt of sharding table by the args bound, the
+//sqls rewritten are executed as prepared statements in the shards
+func (c *ClientConn) handleShardStmt(s *Stmt, cursor bool) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if err, ok := e.(error); ok {
+				const size = 4096
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+
+				golog.Error("ClientConn", "handleShardStmt",
+					err.Error(), c.connectionId,
+					"stack", string(buf), "sql", s.sql)
+			}
+
+			err = errors.ErrInternalServer
+		}
+	}()
+
+	plan := s.plan
+	stmt := s.s
+	if plan == nil {
+		if v, ok := stmt.(*sqlparser.Select); ok {
+			stmt, err = bindLimitArgs(v, s.args)
+			if err != nil {
+				return err
+			}
+		}
+		plan, err = c.schema.rule.BuildPreparedPlan(c.db, stmt, s.args)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v, ok := stmt.(*sqlparser.Select); ok {
+		if cursor {
+			return c.handleShardCursor(s, v, plan)
+		}
+		return c.handleSelectPlan(v, plan, s.args)
+	}
+	return c.handleExecPlan(plan, s.args)
+}
+
+//handleShardCursor merges the results of shards in memory, the merged
+//rows are kept in s and sent by COM_STMT_FETCH
+func (c *ClientConn) handleShardCursor(s *Stmt, stmt *sqlparser.Select, plan *router.Plan) error {
+	rs, err := c.executeSelectPlan(stmt, plan, s.args)
+	if err != nil {
+		return err
+	}
+
+	var r *mysql.Result
+	if rs == nil {
+		r = &mysql.Result{Status: c.status, Resultset: c.newEmptyResultset(stmt)}
+	} else if r, err = c.buildMergedSelectResult(rs, stmt); err != nil {
+		golog.Error("ClientConn", "handleShardCursor", err.Error(), c.connectionId)
+		return err
+	}
+
+	return c.openCursor(s, r.Status, r.Resultset)
+}
+
+//the limit of select is merged in proxy, so the args in it are replaced
+//with the values bound
+func bindLimitArgs(stmt *sqlparser.Select, args []interface{}) (*sqlparser.Select, error) {
+	if stmt.Limit == nil {
+		return stmt, nil
+	}
+
+	offset, err := bindLimitArg(stmt.Limit.Offset, args)
+	if err != nil {
+		return nil, err
+	}
+	rowcount, err := bindLimitArg(stmt.Limit.Rowcount, args)
+	if err != nil {
+		return nil, err
+	}
+
+	newStmt := *stmt
+	newStmt.Limit = &sqlparser.Limit{Offset: offset, Rowcount: rowcount}
+	return &newStmt, nil
+}
+
+func bindLimitArg(expr sqlparser.ValExpr, args []interface{}) (sqlparser.ValExpr, error) {
+	arg, ok := expr.(sqlparser.ValArg)
+	if !ok {
+		return expr, nil
+	}
+
+	//the positional arg :vN is bound to args[N-1]
+	n, err := strconv.Atoi(strings.TrimPrefix(string(arg), ":v"))
+	if err != nil || n < 1 || len(args) < n {
+		return nil, fmt.Errorf("invalid limit %s", string(arg))
+	}
+
+	switch v := args[n-1].(type) {
+	case int64:
+		return sqlparser.NumVal(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return sqlparser.NumVal(strconv.FormatUint(v, 10)), nil
+	case int8, int16, int32, uint8, uint16, uint32:
+		return sqlparser.NumVal(fmt.Sprintf("%d", v)), nil
+	case []byte:
+		if _, err := strconv.ParseUint(string(v), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid limit %s", string(v))
+		}
+		return sqlparser.NumVal(v), nil
+	default:
+		return nil, fmt.Errorf("invalid limit %v", v)
+	}
+}
+
 func (c *ClientConn) handlePrepareSelect(stmt *sqlparser.Select, sql string, args []interface{}) error {
+	status, r, err := c.executePrepareSelect(stmt, sql, args)
+	if err != nil {
+		return err
+	}
+
+	return c.writeResultset(status, r)
+}
+
+//handlePrepareCursor executes the select and sends only the column
+//definitions, the rows are kept in s and sent by COM_STMT_FETCH
+func (c *ClientConn) handlePrepareCursor(s *Stmt, stmt *sqlparser.Select) error {
+	status, r, err := c.executePrepareSelect(stmt, s.sql, s.args)
+	if err != nil {
+		return err
+	}
+
+	return c.openCursor(s, status, r)
+}
+
+//keep the rows of r in s and send the column definitions, the rows are
+//charged against max_result_bytes until the cursor is closed
+func (c *ClientConn) openCursor(s *Stmt, status uint16, r *mysql.Resultset) error {
+	var limit, size int64
+	if c.queryMem != nil {
+		limit = c.queryMem.Limit()
+	}
+	for _, row := range r.RowDatas {
+		size += int64(len(row))
+	}
+	s.CloseCursor()
+	s.cursorMem = backend.NewMemTracker(limit, c.proxy.queryMem)
+	if err := s.cursorMem.Consume(size); err != nil {
+		s.CloseCursor()
+		return err
+	}
+	s.cursor = r
+	s.cursorPos = 0
+
+	c.affectedRows = int64(-1)
+	total := make([]byte, 0, 1024)
+	var err error
+	total, err = c.writeFieldsBatch(total, status|mysql.SERVER_STATUS_CURSOR_EXISTS, r)
+	if err != nil {
+		return err
+	}
+	_, err = c.writePacketBatch(total, nil, true)
+	return err
+}
+
+func (c *ClientConn) executePrepareSelect(stmt *sqlparser.Select, sql string,
+	args []interface{}) (uint16, *mysql.Resultset, error) {
 	db, tableName := GetSelectDbAndTable(stmt)
 	if "" == db {
 		db = c.db
@@ -283,7 +543,7 @@ func (c *ClientConn) handlePrepareSelect(stmt *sqlparser.Select, sql string, arg
 
 	defaultRule := c.schema.rule.GetRule(db, tableName)
 	if len(defaultRule.Nodes) == 0 {
-		return errors.ErrNoDefaultNode
+		return 0, nil, errors.ErrNoDefaultNode
 	}
 	defaultNode := c.nodes[defaultRule.Nodes[0]]
 
@@ -291,30 +551,27 @@ func (c *ClientConn) handlePrepareSelect(stmt *sqlparser.Select, sql string, arg
 	conn, err := c.getBackendConn(defaultNode, true, db)
 	defer c.closeConn(conn, false)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
 	if conn == nil {
-		r := c.newEmptyResultset(stmt)
-		return c.writeResultset(c.status, r)
+		return c.status, c.newEmptyResultset(stmt), nil
 	}
 
 	var rs []*mysql.Result
 	rs, err = c.executeInNode(conn, sql, args)
+	c.collectWarnings(conn, defaultNode.Cfg.Name, tableName, err)
 	if err != nil {
 		golog.Error("ClientConn", "handlePrepareSelect", err.Error(), c.connectionId)
-		return err
+		return 0, nil, err
 	}
 
 	status := c.status | rs[0].Status
 	if rs[0].Resultset != nil {
-		err = c.writeResultset(status, rs[0].Resultset)
-	} else {
-		r := c.newEmptyResultset(stmt)
-		err = c.writeResultset(status, r)
+		return status, rs[0].Resultset, nil
 	}
 
-	return err
+	return status, c.newEmptyResultset(stmt), nil
 }
 
 func (c *ClientConn) handlePrepareExec(stmt sqlparser.Statement, sql string, args []interface{}) error {
@@ -328,6 +585,9 @@ func (c *ClientConn) handlePrepareExec(stmt sqlparser.Statement, sql string, arg
 		return errors.ErrNoDefaultNode
 	}
 	defaultNode := c.nodes[defaultRule.Nodes[0]]
+	if err := defaultNode.CheckMasterWritable(); err != nil {
+		return err
+	}
 
 	//execute in Master DB
 	conn, err := c.getBackendConn(defaultNode, false, "")
@@ -342,6 +602,10 @@ func (c *ClientConn) handlePrepareExec(stmt sqlparser.Statement, sql string, arg
 
 	var rs []*mysql.Result
 	rs, err = c.executeInNode(conn, sql, args)
+	c.collectWarnings(conn, defaultNode.Cfg.Name, tableName, err)
+	if err == nil {
+		c.markWrite(defaultNode, conn)
+	}
 	c.closeConn(conn, false)
 
 	if err != nil {
@@ -530,10 +794,59 @@ func (c *ClientConn) handleStmtReset(data []byte) error {
 	}
 
 	s.ResetParams()
+	s.CloseCursor()
 
 	return c.writeOK(nil)
 }
 
+func (c *ClientConn) handleStmtFetch(data []byte) error {
+	if len(data) < 8 {
+		return mysql.ErrMalformPacket
+	}
+
+	id := binary.LittleEndian.Uint32(data[0:4])
+
+	s, ok := c.stmts[id]
+	if !ok {
+		return mysql.NewDefaultError(mysql.ER_UNKNOWN_STMT_HANDLER,
+			strconv.FormatUint(uint64(id), 10), "stmt_fetch")
+	}
+
+	if s.cursor == nil {
+		return mysql.NewError(mysql.ER_STMT_HAS_NO_OPEN_CURSOR,
+			fmt.Sprintf("The statement (%d) has no open cursor.", id))
+	}
+
+	rows := s.cursor.RowDatas[s.cursorPos:]
+	numRows := binary.LittleEndian.Uint32(data[4:8])
+	if uint64(numRows) < uint64(len(rows)) {
+		rows = rows[:numRows]
+	}
+	s.cursorPos += len(rows)
+
+	status := c.status | mysql.SERVER_STATUS_CURSOR_EXISTS
+	if s.cursorPos >= len(s.cursor.RowDatas) {
+		//all rows are sent, the cursor is closed
+		status = c.status | mysql.SERVER_STATUS_LAST_ROW_SEND
+		s.CloseCursor()
+	}
+
+	total := make([]byte, 0, 4096)
+	data = make([]byte, 4, 512)
+	var err error
+	for _, v := range rows {
+		data = data[0:4]
+		data = append(data, v...)
+		total, err = c.writePacketBatch(total, data, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.writeEOFBatch(total, status, true)
+	return err
+}
+
 func (c *ClientConn) handleStmtClose(data []byte) error {
 	if len(data) < 4 {
 		return nil
@@ -541,11 +854,21 @@ func (c *ClientConn) handleStmtClose(data []byte) error {
 
 	id := binary.LittleEndian.Uint32(data[0:4])
 
+	if s, ok := c.stmts[id]; ok {
+		s.CloseCursor()
+	}
 	delete(c.stmts, id)
 
 	return nil
 }
 
+//closeCursors closes the cursors of all stmts and gives back their bytes
+func (c *ClientConn) closeCursors() {
+	for _, s := range c.stmts {
+		s.CloseCursor()
+	}
+}
+
 func GetSelectDbAndTable(stmt *sqlparser.Select)(db string, tableName string) {
 	switch v := (stmt.From[0]).(type) {
 	case *sqlparser.AliasedTableExpr:
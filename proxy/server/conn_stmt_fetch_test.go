@@ -0,0 +1,175 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+//read the rows and the status of the eof packet sent by COM_STMT_FETCH
+func readFetchResponse(pkg *mysql.PacketIO) (int, uint16, error) {
+	rows := 0
+	for {
+		data, err := pkg.ReadPacket()
+		if err != nil {
+			return 0, 0, err
+		}
+		if data[0] == mysql.EOF_HEADER && len(data) == 5 {
+			return rows, binary.LittleEndian.Uint16(data[3:]), nil
+		}
+		rows++
+	}
+}
+
+func TestHandleStmtFetch(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+
+	s := &Stmt{id: 1}
+	s.cursor = &mysql.Resultset{
+		RowDatas: []mysql.RowData{{0, 0, 1}, {0, 0, 2}, {0, 0, 3}},
+	}
+	c.stmts = map[uint32]*Stmt{1: s}
+
+	type response struct {
+		rows   int
+		status uint16
+	}
+	done := make(chan response, 2)
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		for i := 0; i < 2; i++ {
+			rows, status, err := readFetchResponse(pkg)
+			if err != nil {
+				t.Error(err)
+				break
+			}
+			done <- response{rows, status}
+		}
+		close(done)
+	}()
+
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, 1)
+	binary.LittleEndian.PutUint32(data[4:], 2)
+
+	if err := c.handleStmtFetch(data); err != nil {
+		t.Fatal(err)
+	}
+	r := <-done
+	if r.rows != 2 || r.status&mysql.SERVER_STATUS_CURSOR_EXISTS == 0 {
+		t.Fatalf("expect 2 rows with an open cursor, got %d rows, status %x", r.rows, r.status)
+	}
+
+	if err := c.handleStmtFetch(data); err != nil {
+		t.Fatal(err)
+	}
+	r = <-done
+	if r.rows != 1 || r.status&mysql.SERVER_STATUS_LAST_ROW_SEND == 0 {
+		t.Fatalf("expect the last row, got %d rows, status %x", r.rows, r.status)
+	}
+	if r.status&mysql.SERVER_STATUS_CURSOR_EXISTS != 0 || s.cursor != nil {
+		t.Fatal("cursor should be closed after the last row")
+	}
+
+	if err := c.handleStmtFetch(data); err == nil {
+		t.Fatal("expect error of no open cursor")
+	}
+}
+
+//the results of shards are merged, and the merged rows are fetched by cursor
+func TestShardCursor(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+
+	stmt, err := sqlparser.Parse("select id from t order by id desc limit 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newResult := func(ids ...int64) *mysql.Result {
+		r := &mysql.Result{Resultset: &mysql.Resultset{}}
+		r.Fields = []*mysql.Field{{Name: []byte("id")}}
+		r.FieldNames = map[string]int{"id": 0}
+		for _, id := range ids {
+			r.Values = append(r.Values, []interface{}{id})
+			r.RowDatas = append(r.RowDatas, mysql.RowData{0, 0, byte(id)})
+		}
+		return r
+	}
+	rs := []*mysql.Result{newResult(1, 3), newResult(2, 4)}
+	r, err := c.buildMergedSelectResult(rs, stmt.(*sqlparser.Select))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan uint16, 1)
+	go func() {
+		pkg := mysql.NewPacketIO(clientConn)
+		//column count, column definition and eof
+		var data []byte
+		var e error
+		for i := 0; i < 3; i++ {
+			if data, e = pkg.ReadPacket(); e != nil {
+				t.Error(e)
+				done <- 0
+				return
+			}
+		}
+		done <- binary.LittleEndian.Uint16(data[3:])
+	}()
+
+	c.proxy.queryMem = backend.NewMemTracker(0, nil)
+	s := &Stmt{id: 1}
+	if err := c.openCursor(s, r.Status, r.Resultset); err != nil {
+		t.Fatal(err)
+	}
+	if status := <-done; status&mysql.SERVER_STATUS_CURSOR_EXISTS == 0 {
+		t.Fatalf("expect an open cursor, status %x", status)
+	}
+
+	if len(s.cursor.RowDatas) != 2 || s.cursor.RowDatas[0][2] != 4 || s.cursor.RowDatas[1][2] != 3 {
+		t.Fatalf("rows of cursor %v", s.cursor.RowDatas)
+	}
+
+	//the bytes of cursor are charged until it is closed
+	if used := c.proxy.queryMem.Used(); used != 6 {
+		t.Fatalf("expect 6 bytes charged, got %d", used)
+	}
+	s.CloseCursor()
+	if used := c.proxy.queryMem.Used(); used != 0 {
+		t.Fatalf("expect bytes released, got %d", used)
+	}
+
+	//the cursor over max_result_bytes is not opened
+	c.queryMem = backend.NewMemTracker(4, nil)
+	err = c.openCursor(s, r.Status, r.Resultset)
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_QUERY_INTERRUPTED {
+		t.Fatal(err)
+	}
+	if s.cursor != nil || c.proxy.queryMem.Used() != 0 {
+		t.Fatal("cursor must not be opened")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func TestBindLimitArgs(t *testing.T) {
+	stmt, err := sqlparser.Parse("select * from t where id > ? limit ?, ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := stmt.(*sqlparser.Select)
+
+	args := []interface{}{int64(1), int8(10), []byte("20")}
+	newSel, err := bindLimitArgs(sel, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sqlparser.String(newSel); s != "select * from t where id > :v1 limit 10, 20" {
+		t.Fatal(s)
+	}
+	//the stmt prepared is not changed
+	if s := sqlparser.String(sel); s != "select * from t where id > :v1 limit :v2, :v3" {
+		t.Fatal(s)
+	}
+
+	args[2] = []byte("abc")
+	if _, err = bindLimitArgs(sel, args); err == nil {
+		t.Fatal("must be error")
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	args := []interface{}{int64(1), "a", nil}
+	sqlArgs := bindArgs(args, []int{2, 0})
+	if !reflect.DeepEqual(sqlArgs, []interface{}{nil, int64(1)}) {
+		t.Fatal(sqlArgs)
+	}
+}
+
+func TestFormatBinaryRow(t *testing.T) {
+	fields := []*mysql.Field{
+		{Type: mysql.MYSQL_TYPE_LONGLONG},
+		{Type: mysql.MYSQL_TYPE_LONG, Flag: mysql.UNSIGNED_FLAG},
+		{Type: mysql.MYSQL_TYPE_DOUBLE},
+		{Type: mysql.MYSQL_TYPE_VAR_STRING},
+		{Type: mysql.MYSQL_TYPE_DATETIME},
+		{Type: mysql.MYSQL_TYPE_LONGLONG},
+	}
+	values := []interface{}{
+		int64(-5),
+		uint64(7),
+		1.5,
+		"abc",
+		[]byte("2016-01-02 03:04:05"),
+		nil,
+	}
+
+	row, err := formatBinaryRow(fields, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := mysql.RowData(row).ParseBinary(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		int64(-5),
+		uint64(7),
+		1.5,
+		[]byte("abc"),
+		[]byte("2016-01-02 03:04:05"),
+		nil,
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatal(data)
+	}
+}
@@ -15,11 +15,32 @@
 package server
 
 import (
+	"net"
 	"testing"
 
+	"github.com/flike/kingshard/config"
 	. "github.com/flike/kingshard/mysql"
 )
 
+//newTestConn returns a client conn of user root without backends, the
+//packets are written to conn
+func newTestConn(conn net.Conn) *ClientConn {
+	c := new(ClientConn)
+	c.c = conn
+	c.pkg = NewPacketIO(conn)
+	c.proxy = &Server{
+		cfg:     new(config.Config),
+		users:   map[string]string{"root": "kingshard", "kingshard": "kingshard", "nodb": "nodb"},
+		schemas: map[string]*Schema{"root": new(Schema), "kingshard": new(Schema)},
+	}
+	c.proxy.blacklistSqls[0] = &BlacklistSqls{}
+	c.capability = CLIENT_PROTOCOL_41 | CLIENT_MULTI_STATEMENTS | CLIENT_PLUGIN_AUTH
+	c.status = SERVER_STATUS_AUTOCOMMIT
+	c.user = "root"
+	c.salt, _ = RandomBuf(20)
+	return c
+}
+
 func TestConn_Handshake(t *testing.T) {
 	c := newTestDBConn(t)
 
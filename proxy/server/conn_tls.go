@@ -0,0 +1,158 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+//the length of SSLRequest packet, which is the head of handshake response
+const sslRequestLen = 32
+
+//load the tls config of client conns, return nil if tls is not enabled
+func loadTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if len(cfg.TLSCert) == 0 && len(cfg.TLSKey) == 0 {
+		if cfg.RequireTLS {
+			return nil, fmt.Errorf("tls_cert and tls_key must be set if require_tls is true")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if len(cfg.TLSCA) != 0 {
+		ca, err := ioutil.ReadFile(cfg.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid tls_ca %s", cfg.TLSCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+func (s *Server) getTLSConfig() *tls.Config {
+	tlsConfig, _ := s.tlsConfig.Load().(*tls.Config)
+	//the empty config means tls is not enabled
+	if tlsConfig == nil || len(tlsConfig.Certificates) == 0 {
+		return nil
+	}
+	return tlsConfig
+}
+
+//the new conns use the tls config, such as the certificates reloaded
+func (s *Server) setTLSConfig(tlsConfig *tls.Config) {
+	//atomic.Value can not store nil
+	if tlsConfig == nil {
+		tlsConfig = new(tls.Config)
+	}
+	s.tlsConfig.Store(tlsConfig)
+}
+
+func (c *ClientConn) serverCapability() uint32 {
+	capability := DEFAULT_CAPABILITY
+	if c.tlsConfig != nil {
+		capability |= mysql.CLIENT_SSL
+	}
+	if mysql.HasCompressCodec(mysql.COMPRESS_ZSTD) {
+		capability |= mysql.CLIENT_ZSTD_COMPRESSION_ALGORITHM
+	}
+	return capability
+}
+
+//the conn with the bytes read ahead by packet io
+type bufferedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+//upgrade the conn to tls after SSLRequest of client
+func (c *ClientConn) upgradeTLS() error {
+	buffered := c.pkg.Buffered()
+	conn := &bufferedConn{
+		Conn: c.c,
+		r:    io.MultiReader(bytes.NewReader(append([]byte(nil), buffered...)), c.c),
+	}
+
+	tlsConn := tls.Server(conn, c.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+
+	sequence := c.pkg.Sequence
+	c.c = tlsConn
+	c.pkg = mysql.NewPacketIO(tlsConn)
+	c.pkg.Sequence = sequence
+	c.tlsConn = tlsConn
+	return nil
+}
+
+//check the transport and the client certificate required by user
+func (c *ClientConn) checkTLS() error {
+	c.proxy.configUpdateMutex.RLock()
+	requireTLS := c.proxy.cfg.RequireTLS
+	c.proxy.configUpdateMutex.RUnlock()
+	if c.tlsConn == nil && requireTLS {
+		return mysql.NewDefaultError(mysql.ER_SECURE_TRANSPORT_REQUIRED)
+	}
+
+	userCfg := c.proxy.getUserConfig(c.user)
+	if userCfg == nil || !userCfg.RequireX509 {
+		return nil
+	}
+
+	//the certificates are verified by tls_ca in handshake
+	var state tls.ConnectionState
+	if c.tlsConn != nil {
+		state = c.tlsConn.ConnectionState()
+	}
+	if len(state.VerifiedChains) == 0 {
+		golog.Error("ClientConn", "checkTLS", "no verified client certificate", c.connectionId,
+			"client_user", c.user)
+		return mysql.NewDefaultError(mysql.ER_ACCESS_DENIED_ERROR, c.user, c.c.RemoteAddr().String(), "Yes")
+	}
+	if len(userCfg.X509CN) != 0 && state.PeerCertificates[0].Subject.CommonName != userCfg.X509CN {
+		golog.Error("ClientConn", "checkTLS", "common name of client certificate mismatch", c.connectionId,
+			"client_user", c.user,
+			"cn", state.PeerCertificates[0].Subject.CommonName)
+		return mysql.NewDefaultError(mysql.ER_ACCESS_DENIED_ERROR, c.user, c.c.RemoteAddr().String(), "Yes")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/mysql"
+)
+
+//write a self signed certificate and key into dir
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kingshard"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := path.Join(dir, "cert.pem")
+	keyFile := path.Join(dir, "key.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := ioutil.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kingshard_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCert(t, dir)
+
+	if _, err := loadTLSConfig(&config.Config{RequireTLS: true}); err == nil {
+		t.Fatal("expect error without certificate")
+	}
+
+	tlsConfig, err := loadTLSConfig(&config.Config{TLSCert: certFile, TLSKey: keyFile, TLSCA: certFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Fatal("client certificate should be verified")
+	}
+
+	s := new(Server)
+	s.setTLSConfig(nil)
+	if s.getTLSConfig() != nil {
+		t.Fatal("tls should not be enabled")
+	}
+	s.setTLSConfig(tlsConfig)
+	if s.getTLSConfig() != tlsConfig {
+		t.Fatal("tls config not set")
+	}
+}
+
+func TestUpgradeTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kingshard_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCert(t, dir)
+	tlsConfig, err := loadTLSConfig(&config.Config{TLSCert: certFile, TLSKey: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	go func() {
+		//SSLRequest and tls handshake are sent without waiting
+		pkg := mysql.NewPacketIO(clientConn)
+		pkg.Sequence = 1
+		sslRequest := make([]byte, 4+sslRequestLen)
+		binary.LittleEndian.PutUint32(sslRequest[4:], mysql.CLIENT_SSL)
+		if err := pkg.WritePacket(sslRequest); err != nil {
+			t.Error(err)
+			return
+		}
+
+		tlsConn := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true})
+		pkg = mysql.NewPacketIO(tlsConn)
+		pkg.Sequence = 2
+		if err := pkg.WritePacket(append(make([]byte, 4), "response"...)); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	c := new(ClientConn)
+	c.c = serverConn
+	c.pkg = mysql.NewPacketIO(serverConn)
+	c.pkg.Sequence = 1
+	c.tlsConfig = tlsConfig
+
+	data, err := c.readPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != sslRequestLen {
+		t.Fatalf("invalid SSLRequest length %d", len(data))
+	}
+	if err := c.upgradeTLS(); err != nil {
+		t.Fatal(err)
+	}
+	data, err = c.readPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "response" || c.tlsConn == nil {
+		t.Fatalf("read %q after tls upgraded", data)
+	}
+}
+
+func TestCheckTLS(t *testing.T) {
+	cfg := &config.Config{
+		UserList: []config.UserConfig{
+			{User: "root", MaxResultBytes: 1024},
+			{User: "x509", RequireX509: true},
+		},
+	}
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	s := &Server{cfg: cfg, userConfigs: newUserConfigs(cfg)}
+	c := &ClientConn{c: serverConn, proxy: s, user: "root"}
+	if err := c.checkTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if limit := s.GetMaxResultBytes("root", &Schema{maxResultBytes: 4096}); limit != 1024 {
+		t.Fatalf("expect max result bytes 1024, got %d", limit)
+	}
+
+	//the client certificate is required
+	c.user = "x509"
+	if err := c.checkTLS(); err == nil {
+		t.Fatal("expect access denied without certificate")
+	}
+
+	cfg.RequireTLS = true
+	c.user = "root"
+	err := c.checkTLS()
+	if e, ok := err.(*mysql.SqlError); !ok || e.Code != mysql.ER_SECURE_TRANSPORT_REQUIRED {
+		t.Fatal(err)
+	}
+}
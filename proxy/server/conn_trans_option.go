@@ -0,0 +1,174 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+const (
+	TransReadOnly  = "read only"
+	TransReadWrite = "read write"
+
+	TransConsistentSnapshot = "with consistent snapshot"
+	TransIsolationPrefix    = "isolation level "
+)
+
+var transIsolationLevels = map[string]bool{
+	"read uncommitted": true,
+	"read committed":   true,
+	"repeatable read":  true,
+	"serializable":     true,
+}
+
+//the characteristics of transaction, the empty field means
+//the default of backend
+type transOption struct {
+	isolation          string
+	accessMode         string //read only or read write
+	consistentSnapshot bool
+}
+
+//parse the characteristics of SET TRANSACTION or START TRANSACTION
+func parseTransOption(characteristics []string, isStart bool) (*transOption, error) {
+	opt := new(transOption)
+	for _, c := range characteristics {
+		switch {
+		case c == TransReadOnly || c == TransReadWrite:
+			opt.accessMode = c
+		case c == TransConsistentSnapshot && isStart:
+			opt.consistentSnapshot = true
+		case strings.HasPrefix(c, TransIsolationPrefix) && !isStart:
+			level := strings.TrimPrefix(c, TransIsolationPrefix)
+			if !transIsolationLevels[level] {
+				return nil, fmt.Errorf("invalid transaction isolation level %s", level)
+			}
+			opt.isolation = level
+		default:
+			return nil, fmt.Errorf("invalid transaction characteristic %s", c)
+		}
+	}
+	return opt, nil
+}
+
+//override the characteristics set in other
+func (o *transOption) merge(other *transOption) {
+	if other == nil {
+		return
+	}
+	if 0 < len(other.isolation) {
+		o.isolation = other.isolation
+	}
+	if 0 < len(other.accessMode) {
+		o.accessMode = other.accessMode
+	}
+	if other.consistentSnapshot {
+		o.consistentSnapshot = true
+	}
+}
+
+//the sql to set the characteristics of next transaction in backend
+func (o *transOption) setSql() string {
+	var characteristics []string
+	if 0 < len(o.isolation) {
+		characteristics = append(characteristics, TransIsolationPrefix+o.isolation)
+	}
+	if 0 < len(o.accessMode) {
+		characteristics = append(characteristics, o.accessMode)
+	}
+	if len(characteristics) == 0 {
+		return ""
+	}
+	return "set transaction " + strings.Join(characteristics, ", ")
+}
+
+func (c *ClientConn) handleSetTransaction(stmt *sqlparser.SetTransaction) error {
+	opt, err := parseTransOption(stmt.Characteristics, false)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(stmt.Scope) {
+	case "session":
+		c.sessionTrans.merge(opt)
+	case "":
+		//only works for the next transaction
+		if c.status&mysql.SERVER_STATUS_IN_TRANS > 0 || 0 < len(c.txConns) {
+			return mysql.NewDefaultError(mysql.ER_CANT_CHANGE_TX_CHARACTERISTICS)
+		}
+		if c.nextTrans == nil {
+			c.nextTrans = new(transOption)
+		}
+		c.nextTrans.merge(opt)
+	default:
+		//the global characteristics of backends are not changed by proxy,
+		//ignore it
+	}
+
+	return c.writeOK(nil)
+}
+
+//get the characteristics of current transaction, they are decided
+//when the transaction begins
+func (c *ClientConn) getTransOption() *transOption {
+	if c.curTrans == nil {
+		c.beginTransOption(nil)
+	}
+	return c.curTrans
+}
+
+func (c *ClientConn) beginTransOption(opt *transOption) {
+	c.curTrans = new(transOption)
+	*c.curTrans = c.sessionTrans
+	c.curTrans.merge(c.nextTrans)
+	c.curTrans.merge(opt)
+	c.nextTrans = nil
+}
+
+func (c *ClientConn) isReadOnlyTrans() bool {
+	return c.getTransOption().accessMode == TransReadOnly
+}
+
+//begin the transaction in the conn when the node joins the transaction
+func (c *ClientConn) beginInConn(n *backend.Node, co *backend.BackendConn) error {
+	opt := c.getTransOption()
+	if !c.isAutoCommit() {
+		if err := co.SetAutoCommit(0); err != nil {
+			return err
+		}
+	}
+	if sql := opt.setSql(); 0 < len(sql) {
+		if _, err := co.Execute(sql); err != nil {
+			return err
+		}
+	}
+
+	if c.isXATrans() {
+		return c.xaStart(n, co)
+	}
+	if opt.consistentSnapshot {
+		_, err := co.Execute("start transaction " + TransConsistentSnapshot)
+		return err
+	}
+	if !c.isAutoCommit() {
+		return nil
+	}
+	return co.Begin()
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/sqlparser"
+)
+
+func TestParseTransOption(t *testing.T) {
+	opt, err := parseTransOption([]string{"isolation level read committed", "read only"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := opt.setSql(); s != "set transaction isolation level read committed, read only" {
+		t.Fatal(s)
+	}
+
+	opt, err = parseTransOption([]string{"with consistent snapshot", "read write"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opt.consistentSnapshot || opt.accessMode != TransReadWrite {
+		t.Fatal(opt)
+	}
+
+	invalids := [][]string{
+		{"isolation level read"},
+		{"read"},
+		{"with consistent snapshot"},
+	}
+	for _, characteristics := range invalids {
+		if _, err := parseTransOption(characteristics, false); err == nil {
+			t.Fatal(characteristics)
+		}
+	}
+	if _, err := parseTransOption([]string{"isolation level serializable"}, true); err == nil {
+		t.Fatal("isolation level in start transaction")
+	}
+}
+
+func TestTransOptionMerge(t *testing.T) {
+	c := new(ClientConn)
+	c.sessionTrans = transOption{isolation: "serializable", accessMode: TransReadOnly}
+	c.nextTrans = &transOption{accessMode: TransReadWrite}
+	c.beginTransOption(&transOption{consistentSnapshot: true})
+
+	opt := c.getTransOption()
+	if opt.isolation != "serializable" || opt.accessMode != TransReadWrite || !opt.consistentSnapshot {
+		t.Fatal(opt)
+	}
+	if c.nextTrans != nil {
+		t.Fatal("next transaction option must be cleared")
+	}
+
+	//the session option is not changed
+	c.curTrans = nil
+	if !c.isReadOnlyTrans() {
+		t.Fatal("must be read only")
+	}
+}
+
+func TestHandleSetGlobalTransaction(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	c := newTestConn(serverConn)
+	c.pkg.Sequence = 1
+
+	stmt, err := sqlparser.Parse("set global transaction isolation level serializable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the global characteristics are ignored
+	headers := readTestPackets(t, clientConn, 1)
+	if err = c.handleSetTransaction(stmt.(*sqlparser.SetTransaction)); err != nil {
+		t.Fatal(err)
+	}
+	if h := <-headers; len(h) != 1 || h[0] != mysql.OK_HEADER {
+		t.Fatalf("expect ok packet, got %v", h)
+	}
+	if c.sessionTrans.isolation != "" || c.nextTrans != nil {
+		t.Fatal("session characteristics must not be changed")
+	}
+}
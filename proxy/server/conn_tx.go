@@ -15,10 +15,41 @@
 package server
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/flike/kingshard/backend"
 	"github.com/flike/kingshard/mysql"
 )
 
+const (
+	//transaction in multi nodes with xa two-phase commit
+	TransModeXA = "xa"
+	//transaction in multi nodes, commit the nodes in turn
+	TransModeBestEffort = "best_effort"
+)
+
+func checkTransMode(mode string) error {
+	switch mode {
+	case "", TransModeXA, TransModeBestEffort:
+		return nil
+	}
+	return fmt.Errorf("invalid trans_mode %s", mode)
+}
+
+//transaction can be executed in multi nodes
+func (c *ClientConn) isMultiNodeTrans() bool {
+	return c.schema.transMode != ""
+}
+
+//the global id of transaction in multi nodes
+func (c *ClientConn) getTransId() string {
+	if len(c.transId) == 0 {
+		c.transId = fmt.Sprintf("ks-%d-%d", time.Now().UnixNano(), c.connectionId)
+	}
+	return c.transId
+}
+
 func (c *ClientConn) isInTransaction() bool {
 	return c.status&mysql.SERVER_STATUS_IN_TRANS > 0 ||
 		!c.isAutoCommit()
@@ -28,12 +59,20 @@ func (c *ClientConn) isAutoCommit() bool {
 	return c.status&mysql.SERVER_STATUS_AUTOCOMMIT > 0
 }
 
-func (c *ClientConn) handleBegin() error {
-	for _, co := range c.txConns {
-		if err := co.Begin(); err != nil {
+func (c *ClientConn) handleBegin(characteristics []string) error {
+	opt, err := parseTransOption(characteristics, true)
+	if err != nil {
+		return err
+	}
+	//begin commits the current transaction implicitly
+	if 0 < len(c.txConns) {
+		if err := c.commit(); err != nil {
 			return err
 		}
 	}
+
+	//the nodes begin the transaction when they join
+	c.beginTransOption(opt)
 	c.status |= mysql.SERVER_STATUS_IN_TRANS
 	return c.writeOK(nil)
 }
@@ -57,27 +96,127 @@ func (c *ClientConn) handleRollback() (err error) {
 func (c *ClientConn) commit() (err error) {
 	c.status &= ^mysql.SERVER_STATUS_IN_TRANS
 
-	for _, co := range c.txConns {
-		if e := co.Commit(); e != nil {
-			err = e
+	if c.isXATrans() {
+		err = c.xaCommit()
+	} else if c.isBestEffortTrans() {
+		err = c.bestEffortCommit()
+	} else {
+		for _, co := range c.txConns {
+			if e := co.Commit(); e != nil {
+				err = e
+			}
 		}
+	}
+	c.recordTxWrites()
+	for _, co := range c.txConns {
 		co.Close()
 	}
 
 	c.txConns = make(map[*backend.Node]*backend.BackendConn)
+	c.transId = ""
+	c.txSqls = nil
+	c.savepoints = nil
+	c.curTrans = nil
+	c.txWrites = nil
 	return
 }
 
 func (c *ClientConn) rollback() (err error) {
 	c.status &= ^mysql.SERVER_STATUS_IN_TRANS
 
-	for _, co := range c.txConns {
-		if e := co.Rollback(); e != nil {
-			err = e
+	if c.isXATrans() {
+		err = c.xaRollback(false)
+	} else {
+		for _, co := range c.txConns {
+			if e := co.Rollback(); e != nil {
+				err = e
+			}
 		}
+	}
+	for _, co := range c.txConns {
 		co.Close()
 	}
 
 	c.txConns = make(map[*backend.Node]*backend.BackendConn)
+	c.transId = ""
+	c.txSqls = nil
+	c.savepoints = nil
+	c.curTrans = nil
+	c.txWrites = nil
 	return
 }
+
+func (c *ClientConn) findSavepoint(name string) int {
+	for i, sp := range c.savepoints {
+		if sp == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *ClientConn) executeInTxConns(sql string) error {
+	for _, co := range c.txConns {
+		if _, err := co.Execute(sql); err != nil {
+			return err
+		}
+		c.recordTxSql(co, sql, nil)
+	}
+	return nil
+}
+
+//create the savepoints in the conn which joins the transaction later
+func (c *ClientConn) replaySavepoints(co *backend.BackendConn) error {
+	for _, sp := range c.savepoints {
+		if _, err := co.Execute(fmt.Sprintf("savepoint `%s`", sp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ClientConn) handleSavepoint(name string) error {
+	//savepoint out of transaction is ignored as mysql
+	if c.isInTransaction() {
+		if err := c.executeInTxConns(fmt.Sprintf("savepoint `%s`", name)); err != nil {
+			return err
+		}
+		//the savepoint with the same name is replaced
+		if i := c.findSavepoint(name); i != -1 {
+			c.savepoints = append(c.savepoints[:i], c.savepoints[i+1:]...)
+		}
+		c.savepoints = append(c.savepoints, name)
+	}
+	return c.writeOK(nil)
+}
+
+func (c *ClientConn) handleReleaseSavepoint(name string) error {
+	i := c.findSavepoint(name)
+	if i == -1 {
+		return newSavepointNotExistError(name)
+	}
+	if err := c.executeInTxConns(fmt.Sprintf("release savepoint `%s`", name)); err != nil {
+		return err
+	}
+	//the savepoints created later are also released
+	c.savepoints = c.savepoints[:i]
+	return c.writeOK(nil)
+}
+
+func (c *ClientConn) handleRollbackSavepoint(name string) error {
+	i := c.findSavepoint(name)
+	if i == -1 {
+		return newSavepointNotExistError(name)
+	}
+	if err := c.executeInTxConns(fmt.Sprintf("rollback to savepoint `%s`", name)); err != nil {
+		return err
+	}
+	//the savepoints created later are deleted
+	c.savepoints = c.savepoints[:i+1]
+	return c.writeOK(nil)
+}
+
+func newSavepointNotExistError(name string) error {
+	return mysql.NewError(mysql.ER_SP_DOES_NOT_EXIST,
+		fmt.Sprintf(mysql.MySQLErrName[mysql.ER_SP_DOES_NOT_EXIST], "SAVEPOINT", name))
+}
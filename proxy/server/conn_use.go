@@ -22,6 +22,14 @@ import (
 )
 
 func (c *ClientConn) handleUseDB(dbName string) error {
+	if err := c.useDB(dbName); err != nil {
+		return err
+	}
+	return c.writeOK(nil)
+}
+
+//useDB switches to the database after checking it in backend
+func (c *ClientConn) useDB(dbName string) error {
 	var co *backend.BackendConn
 	var err error
 
@@ -51,5 +59,5 @@ func (c *ClientConn) handleUseDB(dbName string) error {
 		return err
 	}
 	c.db = dbName
-	return c.writeOK(nil)
+	return nil
 }
@@ -0,0 +1,247 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/core/hack"
+	"github.com/flike/kingshard/mysql"
+)
+
+const (
+	WarnLevelNote    = "Note"
+	WarnLevelWarning = "Warning"
+	WarnLevelError   = "Error"
+)
+
+//the warning of last statement in session, Node and Table are
+//the node and sub table where the warning comes from
+type Warning struct {
+	Level   string
+	Code    uint16
+	Message string
+	Node    string
+	Table   string
+}
+
+//add a warning generated by proxy
+func (c *ClientConn) addWarning(w *Warning) {
+	c.appendWarnings(1, w)
+}
+
+//count is the warning count of statement, may be larger than len(ws)
+func (c *ClientConn) appendWarnings(count uint16, ws ...*Warning) {
+	c.Lock()
+	c.warnings = append(c.warnings, ws...)
+	if math.MaxUint16-c.warningCount < count {
+		c.warningCount = math.MaxUint16
+	} else {
+		c.warningCount += count
+	}
+	c.Unlock()
+}
+
+func (c *ClientConn) clearWarnings() {
+	c.warnings = nil
+	c.warningCount = 0
+}
+
+//collect the error or warnings of last statement executed in backend conn
+func (c *ClientConn) collectWarnings(co *backend.BackendConn, node string, table string, err error) {
+	if err != nil {
+		if e, ok := err.(*mysql.SqlError); ok {
+			c.addWarning(&Warning{
+				Level:   WarnLevelError,
+				Code:    e.Code,
+				Message: e.Message,
+				Node:    node,
+				Table:   table,
+			})
+		}
+		return
+	}
+
+	count := co.Warnings()
+	if count == 0 {
+		return
+	}
+
+	//the warnings are neither rows of the query nor charged to it
+	tracker, handler := co.GetMemTracker(), co.GetRowHandler()
+	co.SetMemTracker(nil)
+	co.SetRowHandler(nil)
+	r, err := co.Execute(mysql.TK_STR_SHOW + " " + mysql.TK_STR_WARNINGS)
+	co.SetMemTracker(tracker)
+	co.SetRowHandler(handler)
+	if err != nil || r.Resultset == nil {
+		golog.Warn("ClientConn", "collectWarnings", "show warnings failed", c.connectionId,
+			"node", node, "count", count)
+		c.appendWarnings(count)
+		return
+	}
+
+	ws := make([]*Warning, 0, r.RowNumber())
+	for i := 0; i < r.RowNumber(); i++ {
+		level, _ := r.GetString(i, 0)
+		code, _ := r.GetUint(i, 1)
+		msg, _ := r.GetString(i, 2)
+		ws = append(ws, &Warning{
+			Level:   level,
+			Code:    uint16(code),
+			Message: msg,
+			Node:    node,
+			Table:   table,
+		})
+	}
+	c.appendWarnings(count, ws...)
+}
+
+//show warnings [limit [offset,] row_count], show count(*) warnings
+//and the same statements of errors
+type showWarnings struct {
+	onlyErrors bool
+	count      bool
+	offset     int
+	limit      int //-1 if there is no limit
+}
+
+func parseShowWarnings(tokens []string) (*showWarnings, bool) {
+	if len(tokens) < 2 || strings.ToLower(tokens[0]) != mysql.TK_STR_SHOW {
+		return nil, false
+	}
+	s := &showWarnings{limit: -1}
+	tokens = tokens[1:]
+
+	//count(*) may be split into tokens by spaces
+	if end := len(tokens) - 1; 0 < end && isWarningsToken(tokens[end]) &&
+		strings.ToLower(strings.Join(tokens[:end], "")) == mysql.TK_STR_COUNT {
+		s.count = true
+		tokens = tokens[end:]
+	}
+
+	switch strings.ToLower(tokens[0]) {
+	case mysql.TK_STR_WARNINGS:
+	case mysql.TK_STR_ERRORS:
+		s.onlyErrors = true
+	default:
+		return nil, false
+	}
+	tokens = tokens[1:]
+	if len(tokens) == 0 {
+		return s, true
+	}
+
+	//the comma between offset and row count is a separator of tokens
+	if s.count || strings.ToLower(tokens[0]) != mysql.TK_STR_LIMIT ||
+		len(tokens) < 2 || 3 < len(tokens) {
+		return nil, false
+	}
+	nums := make([]int, 0, 2)
+	for _, v := range tokens[1:] {
+		n, err := strconv.ParseUint(v, 10, 31)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, int(n))
+	}
+	if len(nums) == 2 {
+		s.offset = nums[0]
+	}
+	s.limit = nums[len(nums)-1]
+	return s, true
+}
+
+func isWarningsToken(token string) bool {
+	token = strings.ToLower(token)
+	return token == mysql.TK_STR_WARNINGS || token == mysql.TK_STR_ERRORS
+}
+
+//filter return the warnings shown by the statement
+func (s *showWarnings) filter(ws []*Warning) []*Warning {
+	var shown []*Warning
+	for _, w := range ws {
+		if s.onlyErrors && w.Level != WarnLevelError {
+			continue
+		}
+		shown = append(shown, w)
+	}
+
+	if len(shown) <= s.offset {
+		return nil
+	}
+	shown = shown[s.offset:]
+	if 0 <= s.limit && s.limit < len(shown) {
+		shown = shown[:s.limit]
+	}
+	return shown
+}
+
+func (c *ClientConn) handleShowWarnings(s *showWarnings) error {
+	if s.count {
+		return c.handleShowWarningCount(s.onlyErrors)
+	}
+
+	var names []string = []string{"Level", "Code", "Message", "Node", "Table"}
+	var values [][]interface{}
+
+	//the fields must be built even if there is no warning
+	fields := make([]*mysql.Field, len(names))
+	for i, v := range []interface{}{"", uint16(0), "", "", ""} {
+		fields[i] = &mysql.Field{Name: hack.Slice(names[i])}
+		if err := formatField(fields[i], v); err != nil {
+			return err
+		}
+	}
+
+	for _, w := range s.filter(c.warnings) {
+		values = append(values, []interface{}{w.Level, w.Code, w.Message, w.Node, w.Table})
+	}
+
+	r, err := c.buildResultset(fields, names, values)
+	if err != nil {
+		return err
+	}
+	for i, f := range fields {
+		r.Fields[i] = f
+		r.FieldNames[names[i]] = i
+	}
+	return c.writeResultset(c.status, r)
+}
+
+//the count may be larger than the warnings kept in session
+func (c *ClientConn) handleShowWarningCount(onlyErrors bool) error {
+	name := "@@session.warning_count"
+	count := uint64(c.warningCount)
+	if onlyErrors {
+		name = "@@session.error_count"
+		count = 0
+		for _, w := range c.warnings {
+			if w.Level == WarnLevelError {
+				count++
+			}
+		}
+	}
+
+	r, err := c.buildResultset(nil, []string{name}, [][]interface{}{{count}})
+	if err != nil {
+		return err
+	}
+	return c.writeResultset(c.status, r)
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flike/kingshard/core/hack"
+)
+
+func TestParseShowWarnings(t *testing.T) {
+	cases := []struct {
+		sql    string
+		ok     bool
+		expect showWarnings
+	}{
+		{"SHOW warnings", true, showWarnings{limit: -1}},
+		{"show ERRORS", true, showWarnings{onlyErrors: true, limit: -1}},
+		{"SHOW WARNINGS LIMIT 10", true, showWarnings{limit: 10}},
+		{"SHOW ERRORS LIMIT 0,5", true, showWarnings{onlyErrors: true, limit: 5}},
+		{"show warnings limit 2, 3", true, showWarnings{offset: 2, limit: 3}},
+		{"SHOW COUNT(*) WARNINGS", true, showWarnings{count: true, limit: -1}},
+		{"show count( * ) errors", true, showWarnings{onlyErrors: true, count: true, limit: -1}},
+		{"show tables", false, showWarnings{}},
+		{"show warnings limit", false, showWarnings{}},
+		{"show warnings limit -1", false, showWarnings{}},
+		{"show warnings limit 1,2,3", false, showWarnings{}},
+		{"show count(*) warnings limit 1", false, showWarnings{}},
+		{"show count(id) warnings", false, showWarnings{}},
+	}
+	for _, tc := range cases {
+		s, ok := parseShowWarnings(strings.FieldsFunc(tc.sql, hack.IsSqlSep))
+		if ok != tc.ok {
+			t.Fatalf("%s: expect %v, got %v", tc.sql, tc.ok, ok)
+		}
+		if ok && *s != tc.expect {
+			t.Fatalf("%s: expect %+v, got %+v", tc.sql, tc.expect, *s)
+		}
+	}
+}
+
+func TestShowWarningsFilter(t *testing.T) {
+	ws := []*Warning{
+		{Level: WarnLevelWarning, Node: "node1"},
+		{Level: WarnLevelError, Node: "node2"},
+		{Level: WarnLevelNote, Node: "node3"},
+		{Level: WarnLevelError, Node: "node4"},
+	}
+	cases := []struct {
+		s      showWarnings
+		expect []string
+	}{
+		{showWarnings{limit: -1}, []string{"node1", "node2", "node3", "node4"}},
+		{showWarnings{limit: 2}, []string{"node1", "node2"}},
+		{showWarnings{offset: 1, limit: 2}, []string{"node2", "node3"}},
+		{showWarnings{offset: 3, limit: 5}, []string{"node4"}},
+		{showWarnings{offset: 4, limit: 1}, nil},
+		{showWarnings{limit: 0}, nil},
+		{showWarnings{onlyErrors: true, limit: -1}, []string{"node2", "node4"}},
+		{showWarnings{onlyErrors: true, offset: 1, limit: 1}, []string{"node4"}},
+	}
+	for _, tc := range cases {
+		var nodes []string
+		for _, w := range tc.s.filter(ws) {
+			nodes = append(nodes, w.Node)
+		}
+		if !reflect.DeepEqual(nodes, tc.expect) {
+			t.Fatalf("%+v: expect %v, got %v", tc.s, tc.expect, nodes)
+		}
+	}
+}
+
+func TestAppendWarnings(t *testing.T) {
+	c := new(ClientConn)
+	c.addWarning(&Warning{Level: WarnLevelWarning, Node: "node1"})
+	c.appendWarnings(3, &Warning{Level: WarnLevelNote, Node: "node2", Table: "t_0001"})
+	if c.warningCount != 4 || len(c.warnings) != 2 {
+		t.Fatalf("count %d, warnings %d", c.warningCount, len(c.warnings))
+	}
+
+	c.appendWarnings(math.MaxUint16)
+	if c.warningCount != math.MaxUint16 {
+		t.Fatalf("count %d", c.warningCount)
+	}
+
+	c.clearWarnings()
+	if c.warningCount != 0 || len(c.warnings) != 0 {
+		t.Fatalf("count %d, warnings %d", c.warningCount, len(c.warnings))
+	}
+}
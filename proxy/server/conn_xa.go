@@ -0,0 +1,142 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+func (c *ClientConn) isXATrans() bool {
+	return c.schema.transMode == TransModeXA
+}
+
+func (c *ClientConn) getXID(n *backend.Node) backend.XID {
+	return backend.XID{Gtrid: c.getTransId(), Bqual: n.Cfg.Name}
+}
+
+//start a xa branch when the node joins the transaction
+func (c *ClientConn) xaStart(n *backend.Node, co *backend.BackendConn) error {
+	return co.XAStart(c.getXID(n))
+}
+
+func (c *ClientConn) txNodeNames() []string {
+	names := make([]string, 0, len(c.txConns))
+	for n := range c.txConns {
+		names = append(names, n.Cfg.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *ClientConn) xaCommit() error {
+	//no branch joined the transaction
+	if len(c.txConns) == 0 {
+		return nil
+	}
+	var err error
+	//phase one
+	for n, co := range c.txConns {
+		if err = co.XAEnd(c.getXID(n)); err != nil {
+			break
+		}
+	}
+
+	//only one branch, no need to prepare
+	if err == nil && len(c.txConns) == 1 {
+		for n, co := range c.txConns {
+			err = co.XACommit(c.getXID(n), true)
+		}
+		return err
+	}
+
+	nodes := c.txNodeNames()
+	logged := false
+	if err == nil {
+		err = c.proxy.xaLog.Write(XALogPrepare, c.transId, nodes)
+		logged = err == nil
+	}
+	if err == nil {
+		for n, co := range c.txConns {
+			if err = co.XAPrepare(c.getXID(n)); err != nil {
+				break
+			}
+		}
+	}
+	//record the decision
+	if err == nil {
+		err = c.proxy.xaLog.Write(XALogCommit, c.transId, nodes)
+	}
+	if err != nil {
+		golog.Error("ClientConn", "xaCommit", err.Error(), c.connectionId, "xid", c.transId)
+		c.xaRollback(logged)
+		return err
+	}
+
+	//phase two, the branches failed to commit are retried in background,
+	//the transaction is committed with warnings
+	var failed []string
+	for n, co := range c.txConns {
+		if e := co.XACommit(c.getXID(n), false); e != nil {
+			golog.Error("ClientConn", "xaCommit", e.Error(), c.connectionId,
+				"xid", c.getXID(n).String())
+			failed = append(failed, n.Cfg.Name)
+			c.addWarning(&Warning{
+				Level:   WarnLevelWarning,
+				Code:    mysql.ER_UNKNOWN_ERROR,
+				Message: fmt.Sprintf("xa branch of node %s is committed later: %s", n.Cfg.Name, e.Error()),
+				Node:    n.Cfg.Name,
+			})
+		}
+	}
+	if 0 < len(failed) {
+		c.proxy.xaLog.AddPending(XALogCommit, c.transId, failed)
+		return nil
+	}
+	if e := c.proxy.xaLog.Write(XALogDone, c.transId, nodes); e != nil {
+		golog.Error("ClientConn", "xaCommit", e.Error(), c.connectionId, "xid", c.transId)
+	}
+
+	return nil
+}
+
+//rollback all the branches, logged is true if the transaction is in log
+func (c *ClientConn) xaRollback(logged bool) (err error) {
+	var failed []string
+	for n, co := range c.txConns {
+		xid := c.getXID(n)
+		//the branch may be ended or prepared already
+		co.XAEnd(xid)
+		if e := co.XARollback(xid); e != nil {
+			err = e
+			failed = append(failed, n.Cfg.Name)
+		}
+	}
+
+	if logged {
+		//the prepared branches failed to rollback are retried in background
+		if 0 < len(failed) {
+			c.proxy.xaLog.AddPending(XALogRollback, c.transId, failed)
+		}
+		if e := c.proxy.xaLog.Write(XALogRollback, c.transId, c.txNodeNames()); e != nil {
+			golog.Error("ClientConn", "xaRollback", e.Error(), c.connectionId, "xid", c.transId)
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/core/golog"
+)
+
+const (
+	FailoverWebhookTimeout = 5 * time.Second
+)
+
+//persist the new master of node into config file and notify the webhook
+func (s *Server) onFailover(e *backend.FailoverEvent) {
+	//the config may be reloaded at the same time
+	s.configUpdateMutex.Lock()
+	cfg := s.cfg
+	n := s.GetNode(e.Node)
+	nodes := make([]config.NodeConfig, len(cfg.Nodes))
+	copy(nodes, cfg.Nodes)
+	for i, nodeCfg := range nodes {
+		if n != nil && nodeCfg.Name == e.Node {
+			nodes[i].Master = e.NewMaster
+			nodes[i].Slave = n.SlaveConfig()
+		}
+	}
+	cfg.Nodes = nodes
+	err := config.WriteConfigFile(cfg)
+	s.configUpdateMutex.Unlock()
+	if err != nil {
+		golog.Error("Server", "onFailover", err.Error(), 0, "node", e.Node)
+	}
+
+	if len(cfg.FailoverWebhook) != 0 {
+		go notifyFailover(cfg.FailoverWebhook, e)
+	}
+}
+
+//post the failover event in json to the webhook
+func notifyFailover(url string, e *backend.FailoverEvent) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		golog.Error("Server", "notifyFailover", err.Error(), 0, "node", e.Node)
+		return
+	}
+
+	client := &http.Client{Timeout: FailoverWebhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		golog.Error("Server", "notifyFailover", err.Error(), 0, "node", e.Node)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		golog.Error("Server", "notifyFailover",
+			fmt.Sprintf("webhook returns %s", resp.Status), 0, "node", e.Node)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+)
+
+func TestNotifyFailover(t *testing.T) {
+	events := make(chan *backend.FailoverEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := new(backend.FailoverEvent)
+		if err := json.NewDecoder(r.Body).Decode(e); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+	defer ts.Close()
+
+	notifyFailover(ts.URL, &backend.FailoverEvent{
+		Node:      "node1",
+		OldMaster: "127.0.0.1:3306",
+		NewMaster: "127.0.0.1:3307",
+	})
+	e := <-events
+	if e.Node != "node1" || e.NewMaster != "127.0.0.1:3307" {
+		t.Fatalf("%+v", e)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/mysql"
+)
+
+//the reply of fake backend, ok packet if names is nil
+type fakeReply struct {
+	names    []string
+	values   [][]interface{}
+	warnings uint16
+}
+
+//fakeBackend is a mysql server which accepts any user, the queries are
+//answered by handler and recorded
+type fakeBackend struct {
+	sync.Mutex
+	l       net.Listener
+	handler func(query string) (*fakeReply, error)
+	queries []string
+	dbs     []*backend.DB
+}
+
+func newFakeBackend(t *testing.T, handler func(query string) (*fakeReply, error)) *fakeBackend {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBackend{l: l, handler: handler}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBackend) Close() {
+	b.l.Close()
+	for _, db := range b.dbs {
+		db.Close()
+	}
+}
+
+func (b *fakeBackend) Queries() []string {
+	b.Lock()
+	defer b.Unlock()
+	return append([]string(nil), b.queries...)
+}
+
+//newNode returns a node whose master is the fake backend
+func (b *fakeBackend) newNode(t *testing.T, name string, maxConnNum int) *backend.Node {
+	db, err := backend.Open(b.l.Addr().String(), "root", "", "kingshard", maxConnNum, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.dbs = append(b.dbs, db)
+	return &backend.Node{Cfg: config.NodeConfig{Name: name}, Master: db}
+}
+
+//newBackendTestConn returns a test client conn which is able to execute
+//sqls in the nodes of fake backend
+func newBackendTestConn(conn net.Conn) *ClientConn {
+	c := newTestConn(conn)
+	c.charset = mysql.DEFAULT_CHARSET
+	c.collation = mysql.DEFAULT_COLLATION_ID
+	c.txConns = make(map[*backend.Node]*backend.BackendConn)
+	return c
+}
+
+func (b *fakeBackend) serve(conn net.Conn) {
+	defer conn.Close()
+
+	//the server side of protocol is written by a client conn
+	s := newTestConn(conn)
+	if err := s.writeInitialHandshake(); err != nil {
+		return
+	}
+	if _, err := s.readPacket(); err != nil {
+		return
+	}
+	if err := s.writeOK(nil); err != nil {
+		return
+	}
+
+	for {
+		s.pkg.Sequence = 0
+		data, err := s.readPacket()
+		if err != nil {
+			return
+		}
+
+		switch data[0] {
+		case mysql.COM_QUIT:
+			return
+		case mysql.COM_QUERY:
+			err = b.handleQuery(s, string(data[1:]))
+		default:
+			err = s.writeOK(nil)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBackend) handleQuery(s *ClientConn, query string) error {
+	b.Lock()
+	b.queries = append(b.queries, query)
+	b.Unlock()
+
+	switch strings.ToLower(query) {
+	case "set autocommit = 0":
+		s.status &= ^mysql.SERVER_STATUS_AUTOCOMMIT
+	case "set autocommit = 1":
+		s.status |= mysql.SERVER_STATUS_AUTOCOMMIT
+	}
+
+	var reply *fakeReply
+	var err error
+	if b.handler != nil {
+		reply, err = b.handler(query)
+	}
+	if err != nil {
+		return s.writeError(err)
+	}
+	if reply == nil {
+		return s.writeOK(nil)
+	}
+
+	s.warningCount = reply.warnings
+	defer s.clearWarnings()
+	if reply.names == nil {
+		return s.writeOK(nil)
+	}
+	r, err := s.buildResultset(nil, reply.names, reply.values)
+	if err != nil {
+		return s.writeError(err)
+	}
+	return s.writeResultset(s.status, r)
+}
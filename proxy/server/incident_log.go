@@ -0,0 +1,73 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+//IncidentLog records the transactions partially committed in multi nodes
+//for manual repair, every node of transaction is a line of
+//"time id node state error sqls"
+type IncidentLog struct {
+	sync.Mutex
+	f *os.File
+}
+
+//the state of a node in partially committed transaction
+type incidentRecord struct {
+	node  string
+	state string //committed or failed
+	err   error
+	sqls  []string
+}
+
+func OpenIncidentLog(path string) (*IncidentLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(IncidentLog)
+	l.f = f
+	return l, nil
+}
+
+func (l *IncidentLog) Write(id string, records []*incidentRecord) error {
+	now := time.Now().Format("2006/01/02 15:04:05")
+	var buf []byte
+	for _, r := range records {
+		errMsg := ""
+		if r.err != nil {
+			errMsg = r.err.Error()
+		}
+		buf = append(buf, fmt.Sprintf("%s %s %s %s %q %q\n",
+			now, id, r.node, r.state, errMsg, r.sqls)...)
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	if _, err := l.f.Write(buf); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+func (l *IncidentLog) Close() error {
+	return l.f.Close()
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestIncidentLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "incident_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "incident.log")
+	l, err := OpenIncidentLog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	records := []*incidentRecord{
+		{node: "node1", state: IncidentCommitted, sqls: []string{"update t set a=1"}},
+		{node: "node2", state: IncidentFailed, err: fmt.Errorf("connection was bad"),
+			sqls: []string{"update t set a=2", "delete from t where id=3"}},
+	}
+	if err := l.Write("ks-1-1", records); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatal(lines)
+	}
+	if !strings.HasSuffix(lines[0], `ks-1-1 node1 committed "" ["update t set a=1"]`) {
+		t.Fatal(lines[0])
+	}
+	if !strings.HasSuffix(lines[1],
+		`ks-1-1 node2 failed "connection was bad" ["update t set a=2" "delete from t where id=3"]`) {
+		t.Fatal(lines[1])
+	}
+}
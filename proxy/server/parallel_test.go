@@ -0,0 +1,141 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/config"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+	"github.com/flike/kingshard/proxy/router"
+)
+
+func TestGetParallelCount(t *testing.T) {
+	c := new(ClientConn)
+	c.proxy = new(Server)
+	c.proxy.cfg = &config.Config{MaxParallelPerNode: 4}
+	c.status = mysql.SERVER_STATUS_AUTOCOMMIT
+
+	tests := map[int]int{0: 1, 1: 1, 3: 3, 4: 4, 10: 4}
+	for sqlCount, count := range tests {
+		if n := c.getParallelCount(sqlCount); n != count {
+			t.Fatalf("sql count %d, parallel %d, want %d", sqlCount, n, count)
+		}
+	}
+
+	//the sqls in transaction are executed in one conn
+	c.status |= mysql.SERVER_STATUS_IN_TRANS
+	if n := c.getParallelCount(10); n != 1 {
+		t.Fatal(n)
+	}
+	c.status = mysql.SERVER_STATUS_AUTOCOMMIT
+
+	c.proxy.cfg.MaxParallelPerNode = 0
+	if n := c.getParallelCount(10); n != 1 {
+		t.Fatal(n)
+	}
+}
+
+func TestAcquireParallel(t *testing.T) {
+	s := new(Server)
+	s.parallelSem.Store(newParallelSem(2))
+
+	//the query of one sub table takes no slot
+	if sem := s.acquireParallel(1); sem != nil {
+		t.Fatal("slot taken by single sql")
+	}
+
+	sem1 := s.acquireParallel(3)
+	sem2 := s.acquireParallel(3)
+	acquired := make(chan chan struct{})
+	go func() {
+		acquired <- s.acquireParallel(3)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("slots over max_parallel_total")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	releaseParallel(sem1)
+	select {
+	case sem3 := <-acquired:
+		releaseParallel(sem3)
+	case <-time.After(time.Second):
+		t.Fatal("slot not released")
+	}
+	releaseParallel(sem2)
+
+	//no limit
+	s.parallelSem.Store(newParallelSem(0))
+	if sem := s.acquireParallel(3); sem != nil {
+		t.Fatal("slot taken without limit")
+	}
+}
+
+//the conns are not enough for all the sqls of concurrent queries, the
+//queries must not wait for each other to return conns
+func TestParallelConnsPoolBusy(t *testing.T) {
+	b := newFakeBackend(t, func(query string) (*fakeReply, error) {
+		time.Sleep(5 * time.Millisecond)
+		return &fakeReply{names: []string{"id"}, values: [][]interface{}{{int64(1)}}}, nil
+	})
+	defer b.Close()
+	//the default pool_wait_timeout 0 waits forever
+	node := b.newNode(t, "node1", 2)
+
+	sqls := []string{"select id from t_0000", "select id from t_0001",
+		"select id from t_0002", "select id from t_0003"}
+	queries := 4
+	errs := make(chan error, queries)
+	for i := 0; i < queries; i++ {
+		go func() {
+			c := newBackendTestConn(nil)
+			c.schema = &Schema{}
+			c.proxy.cfg.MaxParallelPerNode = len(sqls)
+			c.proxy.logSql[0] = golog.LogSqlOff
+			c.queryMem = backend.NewMemTracker(0, nil)
+
+			co, err := c.getBackendConn(node, false, "")
+			if err != nil {
+				errs <- err
+				return
+			}
+			plan := &router.Plan{RewrittenSqls: map[string][]string{"node1": sqls}}
+			rs, err := c.executeInMultiNodes(map[string]*backend.BackendConn{"node1": co}, plan, nil)
+			co.Close()
+			if err == nil && len(rs) != len(sqls) {
+				err = fmt.Errorf("results %d, want %d", len(rs), len(sqls))
+			}
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < queries; i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("the queries wait for each other")
+		}
+	}
+}
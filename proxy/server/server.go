@@ -16,6 +16,7 @@ package server
 
 import (
 	"bufio"
+	"crypto/rsa"
 	"fmt"
 	"io"
 	"net"
@@ -40,6 +41,14 @@ import (
 type Schema struct {
 	nodes map[string]*backend.Node
 	rule  *router.Router
+
+	maxResultBytes int64
+	scatterTimeout time.Duration
+	partialResults string
+	transMode      string
+
+	readMasterAfterWrite time.Duration
+	waitGtidTimeout      time.Duration
 }
 
 type BlacklistSqls struct {
@@ -56,7 +65,9 @@ const (
 type Server struct {
 	cfg   *config.Config
 	addr  string
-	users map[string]string //user : psw
+	users map[string]string //user : psw or hash of psw
+	//user : config of user, read with configUpdateMutex
+	userConfigs map[string]*config.UserConfig
 
 	statusIndex        int32
 	status             [2]int32
@@ -69,12 +80,25 @@ type Server struct {
 	allowipsIndex      int32
 	allowips           [2][]net.IP
 
-	counter *Counter
-	nodes   map[string]*backend.Node
-	schemas map[string]*Schema //user : schema of user
+	counter  *Counter
+	queryMem *backend.MemTracker //the bytes read by all queries
+	nodes    map[string]*backend.Node
+	schemas  map[string]*Schema //user : schema of user
+
+	parallelSem atomic.Value //chan struct{}, limit the sqls executing in backends
+	xaLog       *XALog
+	incidentLog *IncidentLog
 
-	listener net.Listener
-	running  bool
+	listener  net.Listener
+	running   bool
+	tlsConfig atomic.Value //*tls.Config of client conns
+
+	//the hash of caching_sha2_password : SHA256(SHA256(psw))
+	sha2Cache      map[string][]byte
+	sha2CacheMutex sync.RWMutex
+	rsaKey         *rsa.PrivateKey
+	rsaKeyErr      error
+	rsaKeyOnce     sync.Once
 
 	monitor *sqlmonitor.SqlMonitor
 
@@ -152,10 +176,11 @@ func parseBlackListSqls(blackListFilePath string) (*BlacklistSqls, error) {
 	return bs, nil
 }
 
-func parseNode(cfg config.NodeConfig) (*backend.Node, error) {
+func parseNode(cfg config.NodeConfig, onFailover backend.FailoverHook) (*backend.Node, error) {
 	var err error
 	n := new(backend.Node)
 	n.Cfg = cfg
+	n.OnFailover = onFailover
 
 	n.DownAfterNoAlive = time.Duration(cfg.DownAfterNoAlive) * time.Second
 	err = n.ParseMaster(cfg.Master)
@@ -173,14 +198,14 @@ func parseNode(cfg config.NodeConfig) (*backend.Node, error) {
 	return n, nil
 }
 
-func parseNodes(cfgNodes []config.NodeConfig) (map[string]*backend.Node, error) {
+func parseNodes(cfgNodes []config.NodeConfig, onFailover backend.FailoverHook) (map[string]*backend.Node, error) {
 	nodes := make(map[string]*backend.Node, len(cfgNodes))
 	for _, v := range cfgNodes {
 		if _, ok := nodes[v.Name]; ok {
 			return nil, fmt.Errorf("duplicate node [%s]", v.Name)
 		}
 
-		n, err := parseNode(v)
+		n, err := parseNode(v, onFailover)
 		if err != nil {
 			return nil, err
 		}
@@ -216,9 +241,23 @@ func parseSchemaList(schemaCfgList []config.SchemaConfig, allNodes map[string]*b
 			return nil, err
 		}
 
+		if err := checkPartialResults(schemaCfg.PartialResults); err != nil {
+			return nil, err
+		}
+		if err := checkTransMode(schemaCfg.TransMode); err != nil {
+			return nil, err
+		}
+
 		schemas[schemaCfg.User] = &Schema{
-			nodes: nodes,
-			rule:  rule,
+			nodes:          nodes,
+			rule:           rule,
+			maxResultBytes: schemaCfg.MaxResultBytes,
+			scatterTimeout: time.Duration(schemaCfg.ScatterTimeout) * time.Millisecond,
+			partialResults: schemaCfg.PartialResults,
+			transMode:      schemaCfg.TransMode,
+
+			readMasterAfterWrite: time.Duration(schemaCfg.ReadMasterAfterWrite) * time.Second,
+			waitGtidTimeout:      time.Duration(schemaCfg.WaitGtidTimeout) * time.Millisecond,
 		}
 
 	}
@@ -226,16 +265,51 @@ func parseSchemaList(schemaCfgList []config.SchemaConfig, allNodes map[string]*b
 	return schemas, nil
 }
 
+func newParallelSem(n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
+
+func (s *Server) getParallelSem() chan struct{} {
+	sem, _ := s.parallelSem.Load().(chan struct{})
+	return sem
+}
+
+//wait for a slot of executing sql in backends, return the semaphore
+//which the slot should be released to. Only the query fans out to
+//more than one sub table takes the slot.
+func (s *Server) acquireParallel(sqlCount int) chan struct{} {
+	if sqlCount <= 1 {
+		return nil
+	}
+	sem := s.getParallelSem()
+	if sem != nil {
+		sem <- struct{}{}
+	}
+	return sem
+}
+
+func releaseParallel(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
+
 func NewServer(cfg *config.Config) (*Server, error) {
 	s := new(Server)
 
 	s.cfg = cfg
 	s.counter = new(Counter)
+	s.queryMem = backend.NewMemTracker(0, nil)
+	s.parallelSem.Store(newParallelSem(cfg.MaxParallelTotal))
 	s.addr = cfg.Addr
 	s.users = make(map[string]string)
 	for _, user := range cfg.UserList {
 		s.users[user.User] = user.Password
 	}
+	s.userConfigs = newUserConfigs(cfg)
 	atomic.StoreInt32(&s.statusIndex, 0)
 	s.status[s.statusIndex] = Online
 	atomic.StoreInt32(&s.logSqlIndex, 0)
@@ -274,7 +348,7 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	}
 	atomic.StoreInt32(&s.allowipsIndex, 0)
 
-	if nodes, err := parseNodes(s.cfg.Nodes); err != nil {
+	if nodes, err := parseNodes(s.cfg.Nodes, s.onFailover); err != nil {
 		return nil, err
 	} else {
 		s.nodes = nodes
@@ -292,13 +366,29 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		}
 	}
 
+	tlsConfig, err := loadTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.setTLSConfig(tlsConfig)
+
+	if err := s.openXALog(); err != nil {
+		return nil, err
+	}
+	if len(cfg.IncidentLogFile) != 0 {
+		incidentLog, err := OpenIncidentLog(cfg.IncidentLogFile)
+		if err != nil {
+			return nil, err
+		}
+		s.incidentLog = incidentLog
+	}
+
 	if cfg.SqlMonitor.Enable {
 		s.monitor = new(sqlmonitor.SqlMonitor)
 		s.monitor.Start(cfg.SqlMonitor.Mode, cfg.SqlMonitor.CacheSize, cfg.SqlMonitor.ChanSize,
 			cfg.SqlMonitor.CachePath, cfg.SqlMonitor.SuccessOnly)
 	}
 
-	var err error
 	netProto := "tcp"
 
 	s.listener, err = net.Listen(netProto, s.addr)
@@ -315,6 +405,28 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	return s, nil
 }
 
+//open the xa log and recover the in-doubt branches if xa is used
+func (s *Server) openXALog() error {
+	useXA := false
+	for _, schemaCfg := range s.cfg.SchemaList {
+		if schemaCfg.TransMode == TransModeXA {
+			useXA = true
+		}
+	}
+	if !useXA {
+		return nil
+	}
+	if len(s.cfg.XALogFile) == 0 {
+		return fmt.Errorf("xa_log_file must be set if trans_mode is xa")
+	}
+
+	var err error
+	if s.xaLog, err = OpenXALog(s.cfg.XALogFile); err != nil {
+		return err
+	}
+	return s.recoverXA()
+}
+
 func (s *Server) flushCounter() {
 	for {
 		s.counter.FlushCounter()
@@ -344,6 +456,7 @@ func (s *Server) newClientConn(co net.Conn) *ClientConn {
 
 	c.pkg = mysql.NewPacketIO(tcpConn)
 	c.proxy = s
+	c.tlsConfig = s.getTLSConfig()
 
 	c.pkg.Sequence = 0
 
@@ -638,6 +751,9 @@ func (s *Server) Run() error {
 
 	// flush counter
 	go s.flushCounter()
+	if s.xaLog != nil {
+		go s.retryXABranches()
+	}
 
 	for s.running {
 		conn, err := s.listener.Accept()
@@ -759,6 +875,37 @@ func (s *Server) GetSchema(user string) *Schema {
 	return s.schemas[user]
 }
 
+func newUserConfigs(cfg *config.Config) map[string]*config.UserConfig {
+	userConfigs := make(map[string]*config.UserConfig, len(cfg.UserList))
+	for i := range cfg.UserList {
+		userConfigs[cfg.UserList[i].User] = &cfg.UserList[i]
+	}
+	return userConfigs
+}
+
+//getUserConfig returns the config of user, nil if the user not exists
+func (s *Server) getUserConfig(user string) *config.UserConfig {
+	s.configUpdateMutex.RLock()
+	defer s.configUpdateMutex.RUnlock()
+	return s.userConfigs[user]
+}
+
+//the max bytes of results a query of user can read from backends,
+//the smaller one of user and schema config, 0 means no limit
+func (s *Server) GetMaxResultBytes(user string, schema *Schema) int64 {
+	var limit int64
+	if userCfg := s.getUserConfig(user); userCfg != nil {
+		limit = userCfg.MaxResultBytes
+	}
+
+	if schema != nil && 0 < schema.maxResultBytes {
+		if limit == 0 || schema.maxResultBytes < limit {
+			limit = schema.maxResultBytes
+		}
+	}
+	return limit
+}
+
 func (s *Server) GetSlowLogTime() int {
 	return s.slowLogTime[s.slowLogTimeIndex]
 }
@@ -802,7 +949,7 @@ func (s *Server) UpdateConfig(newCfg *config.Config) {
 	}
 
 	//parse new nodes
-	nodes, err := parseNodes(newCfg.Nodes)
+	nodes, err := parseNodes(newCfg.Nodes, s.onFailover)
 	if nil != err {
 		golog.Error("Server", "UpdateConfig", err.Error(), 0)
 		return
@@ -813,11 +960,26 @@ func (s *Server) UpdateConfig(newCfg *config.Config) {
 		golog.Error("Server", "UpdateConfig", err.Error(), 0)
 		return
 	}
+	//the xa log is only opened when kingshard starts
+	for _, schema := range newSchemas {
+		if schema.transMode == TransModeXA && s.xaLog == nil {
+			golog.Error("Server", "UpdateConfig", "xa log is not opened, restart to use xa", 0)
+			return
+		}
+	}
+
+	//reload the certificates
+	newTLSConfig, err := loadTLSConfig(newCfg)
+	if nil != err {
+		golog.Error("Server", "UpdateConfig", err.Error(), 0)
+		return
+	}
 
 	newUserList := make(map[string]string)
 	for _, user := range newCfg.UserList {
 		newUserList[user.User] = user.Password
 	}
+	newUserConfigs := newUserConfigs(newCfg)
 
 	for user, _ := range newUserList {
 		if _, exist := newSchemas[user]; !exist {
@@ -855,6 +1017,11 @@ func (s *Server) UpdateConfig(newCfg *config.Config) {
 
 	//reset cfg
 	s.cfg = newCfg
+	s.setTLSConfig(newTLSConfig)
+	if newCfg.MaxParallelTotal != cap(s.getParallelSem()) {
+		//the slots taken are released to the old semaphore
+		s.parallelSem.Store(newParallelSem(newCfg.MaxParallelTotal))
+	}
 
 	if 0 == s.blacklistSqlsIndex {
 		s.blacklistSqls[1] = newBlackList
@@ -875,6 +1042,7 @@ func (s *Server) UpdateConfig(newCfg *config.Config) {
 	}
 
 	s.users = newUserList
+	s.userConfigs = newUserConfigs
 
 	switch strings.ToLower(newCfg.LogLevel) {
 	case "debug":
@@ -0,0 +1,371 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flike/kingshard/backend"
+	"github.com/flike/kingshard/core/golog"
+	"github.com/flike/kingshard/mysql"
+)
+
+const (
+	//all the branches are going to be prepared
+	XALogPrepare = "prepare"
+	//all the branches are prepared and will be committed
+	XALogCommit = "commit"
+	//the branches are rolled back
+	XALogRollback = "rollback"
+	//all the branches are committed
+	XALogDone = "done"
+
+	//rewrite the log with the unfinished transactions only when
+	//the records are over this value
+	XALogCompactRecords = 4096
+	//the interval of retrying the branches failed to commit or rollback
+	XARetryInterval = 10 * time.Second
+)
+
+//XALog is the durable log of xa transaction decisions, every record is
+//a line of "state gtrid node1,node2" and synced to disk when written.
+type XALog struct {
+	sync.Mutex
+	path string
+	f    *os.File
+
+	records int                    //the records in file
+	active  map[string]*xaLogEntry //the transactions not finished
+	//the branches failed to commit or rollback, they are retried
+	//in background, nodes of entry are the failed nodes
+	pending map[string]*xaLogEntry
+}
+
+type xaLogEntry struct {
+	state string
+	nodes []string
+}
+
+func OpenXALog(path string) (*XALog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(XALog)
+	l.path = path
+	l.f = f
+	l.active = make(map[string]*xaLogEntry)
+	l.pending = make(map[string]*xaLogEntry)
+	return l, nil
+}
+
+func (l *XALog) Write(state string, gtrid string, nodes []string) error {
+	l.Lock()
+	defer l.Unlock()
+	if err := l.write(state, gtrid, nodes); err != nil {
+		return err
+	}
+
+	switch state {
+	case XALogPrepare, XALogCommit:
+		l.active[gtrid] = &xaLogEntry{state: state, nodes: nodes}
+		return nil
+	default:
+		delete(l.active, gtrid)
+		return l.compact()
+	}
+}
+
+func (l *XALog) write(state string, gtrid string, nodes []string) error {
+	line := fmt.Sprintf("%s %s %s\n", state, gtrid, strings.Join(nodes, ","))
+	if _, err := l.f.WriteString(line); err != nil {
+		return err
+	}
+	l.records++
+	return l.f.Sync()
+}
+
+//remove the records of finished transactions, the log is truncated if
+//all the transactions are finished, otherwise it is rewritten when the
+//records are too many
+func (l *XALog) compact() error {
+	if len(l.active) == 0 && len(l.pending) == 0 {
+		return l.truncate()
+	}
+	if l.records < XALogCompactRecords {
+		return nil
+	}
+
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	records := 0
+	w := bufio.NewWriter(f)
+	for _, entries := range []map[string]*xaLogEntry{l.active, l.pending} {
+		for gtrid, entry := range entries {
+			fmt.Fprintf(w, "%s %s %s\n", entry.state, gtrid, strings.Join(entry.nodes, ","))
+			records++
+		}
+	}
+	if err = w.Flush(); err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err == nil {
+		err = os.Rename(tmp, l.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	//the old file is replaced
+	if f, err = os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	l.f.Close()
+	l.f = f
+	l.records = records
+	return nil
+}
+
+//Truncate remove all the records
+func (l *XALog) Truncate() error {
+	l.Lock()
+	defer l.Unlock()
+	l.active = make(map[string]*xaLogEntry)
+	l.pending = make(map[string]*xaLogEntry)
+	return l.truncate()
+}
+
+func (l *XALog) truncate() error {
+	if err := l.f.Truncate(0); err != nil {
+		return err
+	}
+	l.records = 0
+	return l.f.Sync()
+}
+
+//AddPending add the branches of nodes failed to commit or rollback,
+//state is the decision of transaction
+func (l *XALog) AddPending(state string, gtrid string, nodes []string) {
+	l.Lock()
+	defer l.Unlock()
+	if entry, ok := l.pending[gtrid]; ok {
+		entry.nodes = append(entry.nodes, nodes...)
+		return
+	}
+	l.pending[gtrid] = &xaLogEntry{state: state, nodes: nodes}
+}
+
+//Pending return a copy of the branches to be retried
+func (l *XALog) Pending() map[string]*xaLogEntry {
+	l.Lock()
+	defer l.Unlock()
+	pending := make(map[string]*xaLogEntry, len(l.pending))
+	for gtrid, entry := range l.pending {
+		pending[gtrid] = &xaLogEntry{state: entry.state, nodes: entry.nodes}
+	}
+	return pending
+}
+
+//Resolve update the failed nodes of pending transaction after retried,
+//the transaction is done if no node failed
+func (l *XALog) Resolve(gtrid string, failed []string) error {
+	l.Lock()
+	defer l.Unlock()
+	entry, ok := l.pending[gtrid]
+	if !ok {
+		return nil
+	}
+	if 0 < len(failed) {
+		entry.nodes = failed
+		return nil
+	}
+
+	delete(l.pending, gtrid)
+	if entry.state == XALogCommit {
+		if err := l.write(XALogDone, gtrid, nil); err != nil {
+			return err
+		}
+	}
+	delete(l.active, gtrid)
+	return l.compact()
+}
+
+func (l *XALog) Close() error {
+	return l.f.Close()
+}
+
+//load the last state of every transaction in log
+func loadXALog(path string) (map[string]*xaLogEntry, error) {
+	entries := make(map[string]*xaLogEntry)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		//the last line may be broken when crashed
+		if len(fields) < 2 {
+			continue
+		}
+
+		entry := &xaLogEntry{state: fields[0]}
+		if len(fields) == 3 {
+			entry.nodes = strings.Split(fields[2], ",")
+		}
+		entries[fields[1]] = entry
+	}
+
+	return entries, scanner.Err()
+}
+
+//recover the in-doubt xa branches in all nodes, the branches committed in
+//log are committed and the others are rolled back. The branches failed to
+//recover are retried in background.
+func (s *Server) recoverXA() error {
+	entries, err := loadXALog(s.xaLog.path)
+	if err != nil {
+		return err
+	}
+
+	failed := make(map[string][]string)
+	for name, node := range s.nodes {
+		co, err := node.GetMasterConn()
+		if err != nil {
+			golog.Error("Server", "recoverXA", err.Error(), 0, "node", name)
+			addUnknownXABranches(failed, entries, name)
+			continue
+		}
+
+		xids, err := co.XARecover()
+		if err != nil {
+			golog.Error("Server", "recoverXA", err.Error(), 0, "node", name)
+			addUnknownXABranches(failed, entries, name)
+			co.Close()
+			continue
+		}
+
+		for _, xid := range xids {
+			entry, ok := entries[xid.Gtrid]
+			if !ok {
+				//not in this proxy
+				continue
+			}
+			if err := recoverXABranch(co, xid, entry.state); err != nil {
+				golog.Error("Server", "recoverXA", err.Error(), 0,
+					"node", name, "xid", xid.String(), "state", entry.state)
+				failed[xid.Gtrid] = append(failed[xid.Gtrid], name)
+			} else {
+				golog.Info("Server", "recoverXA", "recover xa branch", 0,
+					"node", name, "xid", xid.String(), "state", entry.state)
+			}
+		}
+		co.Close()
+	}
+
+	//keep the log if some branches are not resolved
+	if len(failed) == 0 {
+		return s.xaLog.Truncate()
+	}
+	for gtrid, nodes := range failed {
+		s.xaLog.AddPending(entries[gtrid].state, gtrid, nodes)
+	}
+	return nil
+}
+
+//the branches of node are unknown if xa recover failed in node
+func addUnknownXABranches(failed map[string][]string, entries map[string]*xaLogEntry, node string) {
+	for gtrid, entry := range entries {
+		if entry.state == XALogDone {
+			continue
+		}
+		for _, name := range entry.nodes {
+			if name == node {
+				failed[gtrid] = append(failed[gtrid], node)
+				break
+			}
+		}
+	}
+}
+
+func recoverXABranch(co *backend.BackendConn, xid backend.XID, state string) error {
+	if state == XALogCommit || state == XALogDone {
+		return co.XACommit(xid, false)
+	}
+	return co.XARollback(xid)
+}
+
+//retry the branches failed to commit or rollback in background
+func (s *Server) retryXABranches() {
+	for {
+		time.Sleep(XARetryInterval)
+		s.resolvePendingXA()
+	}
+}
+
+func (s *Server) resolvePendingXA() {
+	for gtrid, entry := range s.xaLog.Pending() {
+		var failed []string
+		for _, name := range entry.nodes {
+			xid := backend.XID{Gtrid: gtrid, Bqual: name}
+			if err := s.resolveXABranch(name, xid, entry.state); err != nil {
+				golog.Error("Server", "resolvePendingXA", err.Error(), 0,
+					"node", name, "xid", xid.String(), "state", entry.state)
+				failed = append(failed, name)
+			} else {
+				golog.Info("Server", "resolvePendingXA", "resolve xa branch", 0,
+					"node", name, "xid", xid.String(), "state", entry.state)
+			}
+		}
+		if err := s.xaLog.Resolve(gtrid, failed); err != nil {
+			golog.Error("Server", "resolvePendingXA", err.Error(), 0, "xid", gtrid)
+		}
+	}
+}
+
+func (s *Server) resolveXABranch(name string, xid backend.XID, state string) error {
+	node := s.GetNode(name)
+	if node == nil {
+		return fmt.Errorf("node %s not found", name)
+	}
+	co, err := node.GetMasterConn()
+	if err != nil {
+		return err
+	}
+	defer co.Close()
+
+	err = recoverXABranch(co, xid, state)
+	//the branch is not prepared in node, it was committed or rolled back
+	if e, ok := err.(*mysql.SqlError); ok && e.Code == mysql.ER_XAER_NOTA {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestXALog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xa_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "xa.log")
+	l, err := OpenXALog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	nodes := []string{"node1", "node2"}
+	l.Write(XALogPrepare, "ks-1-1", nodes)
+	l.Write(XALogPrepare, "ks-2-1", nodes)
+	l.Write(XALogCommit, "ks-1-1", nodes)
+	l.Write(XALogRollback, "ks-2-1", nodes)
+	l.Write(XALogPrepare, "ks-3-2", nodes)
+	//broken line
+	l.f.WriteString("commit\n")
+
+	entries, err := loadXALog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries %v", entries)
+	}
+	if entries["ks-1-1"].state != XALogCommit || entries["ks-2-1"].state != XALogRollback ||
+		entries["ks-3-2"].state != XALogPrepare {
+		t.Fatalf("entries %v", entries)
+	}
+	if !reflect.DeepEqual(entries["ks-1-1"].nodes, nodes) {
+		t.Fatal(entries["ks-1-1"].nodes)
+	}
+
+	if err := l.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = loadXALog(file)
+	if len(entries) != 0 {
+		t.Fatal(entries)
+	}
+}
+
+func TestXALogCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xa_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "xa.log")
+	l, err := OpenXALog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	nodes := []string{"node1", "node2"}
+	l.Write(XALogPrepare, "ks-1-1", nodes)
+	l.Write(XALogCommit, "ks-1-1", nodes)
+	l.Write(XALogPrepare, "ks-2-1", nodes)
+	l.Write(XALogCommit, "ks-2-1", nodes)
+
+	//ks-1-1 is done, ks-2-1 failed to commit in node2
+	l.Write(XALogDone, "ks-1-1", nodes)
+	l.AddPending(XALogCommit, "ks-2-1", []string{"node2"})
+
+	//rewrite the log with unfinished transactions
+	l.Lock()
+	l.records = XALogCompactRecords
+	err = l.compact()
+	l.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loadXALog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries["ks-2-1"].state != XALogCommit {
+		t.Fatalf("entries %v", entries)
+	}
+
+	pending := l.Pending()
+	if !reflect.DeepEqual(pending["ks-2-1"].nodes, []string{"node2"}) {
+		t.Fatal(pending)
+	}
+
+	//the branch still fails
+	if err := l.Resolve("ks-2-1", []string{"node2"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Pending()) != 1 {
+		t.Fatal(l.Pending())
+	}
+
+	//the log is truncated when all the transactions are finished
+	if err := l.Resolve("ks-2-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Pending()) != 0 {
+		t.Fatal(l.Pending())
+	}
+	entries, _ = loadXALog(file)
+	if len(entries) != 0 {
+		t.Fatal(entries)
+	}
+}
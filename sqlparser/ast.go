@@ -21,6 +21,7 @@ package sqlparser
 import (
 	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/flike/kingshard/core/hack"
 	"github.com/flike/kingshard/sqltypes"
@@ -199,11 +200,16 @@ func (node *Delete) Format(buf *TrackedBuffer) {
 // Set represents a SET statement.
 type Set struct {
 	Comments Comments
+	Scope    string
 	Exprs    UpdateExprs
 }
 
 func (node *Set) Format(buf *TrackedBuffer) {
-	buf.Fprintf("set %v%v", node.Comments, node.Exprs)
+	buf.Fprintf("set %v", node.Comments)
+	if node.Scope != "" {
+		buf.Fprintf("%s ", node.Scope)
+	}
+	buf.Fprintf("%v", node.Exprs)
 }
 
 // DDL represents a CREATE, ALTER, DROP or RENAME statement.
@@ -468,6 +474,7 @@ func (StrVal) IExpr()          {}
 func (NumVal) IExpr()          {}
 func (ValArg) IExpr()          {}
 func (*NullVal) IExpr()        {}
+func (*DefaultVal) IExpr()     {}
 func (*ColName) IExpr()        {}
 func (ValTuple) IExpr()        {}
 func (*Subquery) IExpr()       {}
@@ -604,6 +611,7 @@ func (StrVal) IValExpr()      {}
 func (NumVal) IValExpr()      {}
 func (ValArg) IValExpr()      {}
 func (*NullVal) IValExpr()    {}
+func (*DefaultVal) IValExpr() {}
 func (*ColName) IValExpr()    {}
 func (ValTuple) IValExpr()    {}
 func (*Subquery) IValExpr()   {}
@@ -631,7 +639,7 @@ func (node NumVal) Format(buf *TrackedBuffer) {
 type ValArg []byte
 
 func (node ValArg) Format(buf *TrackedBuffer) {
-	buf.WriteArg(string(node[1:]))
+	buf.WriteArg(string(node))
 }
 
 // NullVal represents a NULL value.
@@ -641,6 +649,13 @@ func (node *NullVal) Format(buf *TrackedBuffer) {
 	buf.Fprintf("null")
 }
 
+// DefaultVal represents the DEFAULT value.
+type DefaultVal struct{}
+
+func (node *DefaultVal) Format(buf *TrackedBuffer) {
+	buf.Fprintf("default")
+}
+
 // ColName represents a column name.
 type ColName struct {
 	Name, Qualifier []byte
@@ -915,15 +930,27 @@ func (node OnDup) Format(buf *TrackedBuffer) {
 	buf.Fprintf(" on duplicate key update %v", UpdateExprs(node))
 }
 
-func (*Begin) IStatement()    {}
-func (*Commit) IStatement()   {}
-func (*Rollback) IStatement() {}
+func (*Begin) IStatement()             {}
+func (*Commit) IStatement()            {}
+func (*Rollback) IStatement()          {}
+func (*Savepoint) IStatement()         {}
+func (*SetTransaction) IStatement()    {}
+func (*ReleaseSavepoint) IStatement()  {}
+func (*RollbackSavepoint) IStatement() {}
 
+// Begin represents BEGIN or START TRANSACTION, Characteristics are the
+// options of START TRANSACTION in lower case,
+// such as "read only" and "with consistent snapshot".
 type Begin struct {
+	Characteristics []string
 }
 
 func (node *Begin) Format(buf *TrackedBuffer) {
-	buf.Fprintf("begin")
+	if len(node.Characteristics) == 0 {
+		buf.Fprintf("begin")
+		return
+	}
+	buf.Fprintf("start transaction %s", strings.Join(node.Characteristics, ", "))
 }
 
 type Commit struct {
@@ -940,6 +967,45 @@ func (node *Rollback) Format(buf *TrackedBuffer) {
 	buf.Fprintf("rollback")
 }
 
+// SetTransaction represents SET [GLOBAL|SESSION] TRANSACTION characteristic, ...
+type SetTransaction struct {
+	Comments        Comments
+	Scope           string
+	Characteristics []string
+}
+
+func (node *SetTransaction) Format(buf *TrackedBuffer) {
+	buf.Fprintf("set %v", node.Comments)
+	if node.Scope != "" {
+		buf.Fprintf("%s ", node.Scope)
+	}
+	buf.Fprintf("transaction %s", strings.Join(node.Characteristics, ", "))
+}
+
+type Savepoint struct {
+	Name []byte
+}
+
+func (node *Savepoint) Format(buf *TrackedBuffer) {
+	buf.Fprintf("savepoint %s", node.Name)
+}
+
+type ReleaseSavepoint struct {
+	Name []byte
+}
+
+func (node *ReleaseSavepoint) Format(buf *TrackedBuffer) {
+	buf.Fprintf("release savepoint %s", node.Name)
+}
+
+type RollbackSavepoint struct {
+	Name []byte
+}
+
+func (node *RollbackSavepoint) Format(buf *TrackedBuffer) {
+	buf.Fprintf("rollback to savepoint %s", node.Name)
+}
+
 // Replace represents an REPLACE statement.
 type Replace struct {
 	Comments Comments
@@ -1029,3 +1095,83 @@ func (*Truncate) IStatement() {}
 func (node *Truncate) Format(buf *TrackedBuffer) {
 	buf.Fprintf("truncate%v%s%v", node.Comments, node.TableOpt, node.Table)
 }
+
+// LoadData represents LOAD DATA LOCAL INFILE, the file is sent by client.
+type LoadData struct {
+	Comments    Comments
+	File        StrVal
+	DupOpt      string
+	Table       *TableName
+	Fields      *LoadDataFields
+	Lines       *LoadDataLines
+	IgnoreLines NumVal
+	Columns     Columns
+}
+
+// LoadDataFields represents the FIELDS options of LOAD DATA,
+// the option not set is nil.
+type LoadDataFields struct {
+	Terminated StrVal
+	Optionally bool
+	Enclosed   StrVal
+	Escaped    StrVal
+}
+
+// LoadDataLines represents the LINES options of LOAD DATA,
+// the option not set is nil.
+type LoadDataLines struct {
+	Starting   StrVal
+	Terminated StrVal
+}
+
+const (
+	AST_LOAD_REPLACE = "replace"
+)
+
+func (*LoadData) IStatement() {}
+
+func (node *LoadData) Format(buf *TrackedBuffer) {
+	buf.Fprintf("load data %vlocal infile %v", node.Comments, node.File)
+	if node.DupOpt != "" {
+		buf.Fprintf(" %s", node.DupOpt)
+	}
+	buf.Fprintf(" into table %v%v%v", node.Table, node.Fields, node.Lines)
+	if node.IgnoreLines != nil {
+		buf.Fprintf(" ignore %v lines", node.IgnoreLines)
+	}
+	if node.Columns != nil {
+		buf.Fprintf(" %v", node.Columns)
+	}
+}
+
+func (node *LoadDataFields) Format(buf *TrackedBuffer) {
+	if node == nil {
+		return
+	}
+	buf.Fprintf(" fields")
+	if node.Terminated != nil {
+		buf.Fprintf(" terminated by %v", node.Terminated)
+	}
+	if node.Enclosed != nil {
+		if node.Optionally {
+			buf.Fprintf(" optionally")
+		}
+		buf.Fprintf(" enclosed by %v", node.Enclosed)
+	}
+	if node.Escaped != nil {
+		buf.Fprintf(" escaped by %v", node.Escaped)
+	}
+}
+
+func (node *LoadDataLines) Format(buf *TrackedBuffer) {
+	if node == nil {
+		return
+	}
+	buf.Fprintf(" lines")
+	if node.Starting != nil {
+		buf.Fprintf(" starting by %v", node.Starting)
+	}
+	if node.Terminated != nil {
+		buf.Fprintf(" terminated by %v", node.Terminated)
+	}
+}
@@ -4,6 +4,7 @@ package sqlparser
 import __yyfmt__ "fmt"
 
 //line ./sqlparser/sql.y:20
+
 import "bytes"
 
 func SetParseTree(yylex interface{}, stmt Statement) {
@@ -19,13 +20,19 @@ func ForceEOF(yylex interface{}) {
 }
 
 var (
-	SHARE        = []byte("share")
-	MODE         = []byte("mode")
-	IF_BYTES     = []byte("if")
-	VALUES_BYTES = []byte("values")
+	SHARE           = []byte("share")
+	MODE            = []byte("mode")
+	IF_BYTES        = []byte("if")
+	VALUES_BYTES    = []byte("values")
+	DATA_BYTES      = []byte("data")
+	LOCAL_BYTES     = []byte("local")
+	FIELDS_BYTES    = []byte("fields")
+	COLUMNS_BYTES   = []byte("columns")
+	ROWS_BYTES      = []byte("rows")
+	SAVEPOINT_BYTES = []byte("savepoint")
 )
 
-//line ./sqlparser/sql.y:45
+//line ./sqlparser/sql.y:51
 type yySymType struct {
 	yys         int
 	empty       struct{}
@@ -35,6 +42,7 @@ type yySymType struct {
 	bytes       []byte
 	bytes2      [][]byte
 	str         string
+	strs        []string
 	selectExprs SelectExprs
 	selectExpr  SelectExpr
 	columns     Columns
@@ -60,6 +68,8 @@ type yySymType struct {
 	insRows     InsertRows
 	updateExprs UpdateExprs
 	updateExpr  *UpdateExpr
+	loadFields  *LoadDataFields
+	loadLines   *LoadDataLines
 }
 
 const LEX_ERROR = 57346
@@ -131,25 +141,34 @@ const START = 57411
 const TRANSACTION = 57412
 const COMMIT = 57413
 const ROLLBACK = 57414
-const NAMES = 57415
-const REPLACE = 57416
-const ADMIN = 57417
-const HELP = 57418
-const OFFSET = 57419
-const COLLATE = 57420
-const CREATE = 57421
-const ALTER = 57422
-const DROP = 57423
-const RENAME = 57424
-const TABLE = 57425
-const INDEX = 57426
-const VIEW = 57427
-const TO = 57428
-const IGNORE = 57429
-const IF = 57430
-const UNIQUE = 57431
-const USING = 57432
-const TRUNCATE = 57433
+const RELEASE = 57415
+const NAMES = 57416
+const REPLACE = 57417
+const ADMIN = 57418
+const HELP = 57419
+const OFFSET = 57420
+const COLLATE = 57421
+const CREATE = 57422
+const ALTER = 57423
+const DROP = 57424
+const RENAME = 57425
+const TABLE = 57426
+const INDEX = 57427
+const VIEW = 57428
+const TO = 57429
+const IGNORE = 57430
+const IF = 57431
+const UNIQUE = 57432
+const USING = 57433
+const TRUNCATE = 57434
+const LOAD = 57435
+const INFILE = 57436
+const TERMINATED = 57437
+const OPTIONALLY = 57438
+const ENCLOSED = 57439
+const ESCAPED = 57440
+const LINES = 57441
+const STARTING = 57442
 
 var yyToknames = [...]string{
 	"$end",
@@ -239,6 +258,7 @@ var yyToknames = [...]string{
 	"TRANSACTION",
 	"COMMIT",
 	"ROLLBACK",
+	"RELEASE",
 	"NAMES",
 	"REPLACE",
 	"ADMIN",
@@ -258,6 +278,14 @@ var yyToknames = [...]string{
 	"UNIQUE",
 	"USING",
 	"TRUNCATE",
+	"LOAD",
+	"INFILE",
+	"TERMINATED",
+	"OPTIONALLY",
+	"ENCLOSED",
+	"ESCAPED",
+	"LINES",
+	"STARTING",
 	"')'",
 }
 var yyStatenames = [...]string{}
@@ -273,274 +301,307 @@ var yyExca = [...]int{
 	-2, 0,
 }
 
-const yyNprod = 219
+const yyNprod = 254
 const yyPrivate = 57344
 
 var yyTokenNames []string
 var yyStates []string
 
-const yyLast = 647
+const yyLast = 710
 
 var yyAct = [...]int{
 
-	111, 108, 315, 352, 187, 385, 102, 139, 74, 346,
-	270, 119, 76, 185, 229, 188, 3, 148, 97, 265,
-	199, 98, 60, 92, 162, 161, 109, 118, 211, 88,
-	124, 81, 394, 53, 394, 63, 279, 79, 115, 116,
-	117, 394, 133, 122, 142, 156, 156, 78, 77, 47,
-	83, 49, 66, 85, 156, 50, 258, 89, 227, 205,
-	363, 362, 114, 118, 125, 361, 124, 72, 82, 37,
-	38, 39, 40, 79, 115, 116, 117, 256, 106, 122,
-	120, 121, 103, 138, 334, 336, 52, 96, 53, 132,
-	343, 146, 84, 141, 78, 152, 129, 396, 105, 395,
-	125, 158, 62, 55, 56, 57, 393, 54, 150, 123,
-	342, 307, 184, 186, 147, 79, 120, 121, 154, 305,
-	189, 257, 58, 226, 190, 298, 193, 274, 160, 78,
-	77, 78, 77, 135, 94, 335, 206, 198, 259, 196,
-	171, 399, 134, 201, 208, 123, 266, 203, 204, 137,
-	197, 243, 162, 161, 221, 174, 175, 176, 171, 207,
-	235, 206, 61, 161, 242, 241, 358, 233, 237, 238,
-	103, 222, 75, 162, 161, 87, 225, 239, 234, 345,
-	244, 245, 246, 248, 249, 250, 251, 252, 253, 254,
-	255, 240, 130, 347, 287, 288, 289, 290, 291, 275,
-	292, 293, 145, 347, 236, 103, 103, 272, 217, 328,
-	261, 263, 276, 266, 329, 310, 273, 247, 360, 269,
-	277, 267, 326, 215, 78, 77, 218, 327, 78, 283,
-	51, 359, 281, 90, 37, 38, 39, 40, 150, 200,
-	280, 332, 331, 233, 330, 130, 297, 200, 262, 284,
-	114, 118, 258, 369, 124, 282, 300, 301, 338, 379,
-	155, 101, 115, 116, 117, 64, 106, 122, 299, 78,
-	77, 285, 304, 311, 71, 318, 103, 313, 378, 130,
-	314, 306, 268, 150, 309, 312, 105, 126, 125, 93,
-	214, 216, 213, 156, 19, 377, 223, 233, 233, 133,
-	324, 325, 232, 93, 120, 121, 99, 231, 194, 321,
-	172, 173, 174, 175, 176, 171, 341, 349, 192, 19,
-	232, 191, 93, 348, 344, 231, 79, 354, 339, 337,
-	350, 353, 296, 123, 114, 118, 320, 260, 124, 391,
-	19, 20, 21, 22, 295, 79, 115, 116, 117, 159,
-	106, 122, 319, 392, 364, 220, 219, 207, 62, 365,
-	153, 64, 375, 374, 23, 376, 373, 143, 140, 136,
-	105, 203, 125, 383, 86, 128, 384, 380, 386, 386,
-	386, 381, 382, 353, 387, 388, 34, 366, 120, 121,
-	78, 77, 127, 91, 19, 400, 303, 209, 397, 144,
-	401, 69, 402, 67, 357, 170, 169, 172, 173, 174,
-	175, 176, 171, 149, 316, 317, 271, 123, 28, 29,
-	356, 30, 31, 367, 32, 33, 202, 323, 200, 24,
-	25, 27, 26, 114, 118, 73, 398, 124, 389, 19,
-	42, 35, 18, 17, 101, 115, 116, 117, 19, 106,
-	122, 16, 15, 170, 169, 172, 173, 174, 175, 176,
-	171, 14, 13, 12, 118, 95, 210, 124, 48, 105,
-	278, 125, 212, 368, 79, 115, 116, 117, 80, 133,
-	122, 151, 390, 118, 370, 351, 124, 120, 121, 99,
-	131, 355, 322, 79, 115, 116, 117, 118, 133, 122,
-	124, 125, 371, 372, 340, 308, 195, 79, 115, 116,
-	117, 264, 133, 122, 113, 110, 123, 120, 121, 112,
-	125, 170, 169, 172, 173, 174, 175, 176, 171, 224,
-	107, 163, 104, 333, 125, 230, 120, 121, 170, 169,
-	172, 173, 174, 175, 176, 171, 123, 286, 228, 302,
-	120, 121, 170, 169, 172, 173, 174, 175, 176, 171,
-	100, 294, 157, 68, 36, 123, 170, 169, 172, 173,
-	174, 175, 176, 171, 165, 167, 70, 11, 10, 123,
-	177, 178, 179, 180, 181, 182, 183, 168, 166, 164,
-	170, 169, 172, 173, 174, 175, 176, 171, 169, 172,
-	173, 174, 175, 176, 171, 287, 288, 289, 290, 291,
-	41, 292, 293, 9, 8, 7, 6, 5, 4, 2,
-	1, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 43, 44, 45, 46, 0, 0, 0, 0, 0,
-	0, 0, 0, 59, 0, 0, 65,
+	128, 35, 108, 84, 385, 125, 212, 421, 160, 299,
+	379, 119, 136, 172, 210, 258, 70, 294, 345, 115,
+	224, 213, 3, 114, 88, 103, 42, 437, 126, 102,
+	44, 45, 46, 47, 187, 186, 67, 68, 432, 432,
+	73, 456, 256, 317, 318, 319, 320, 321, 432, 322,
+	323, 181, 181, 181, 85, 287, 453, 94, 255, 90,
+	96, 452, 238, 99, 100, 76, 104, 105, 82, 106,
+	445, 447, 446, 448, 353, 54, 113, 56, 92, 367,
+	369, 57, 59, 397, 60, 443, 150, 354, 104, 148,
+	60, 90, 308, 120, 159, 163, 285, 62, 63, 64,
+	146, 152, 167, 66, 169, 162, 170, 396, 288, 459,
+	150, 407, 434, 433, 179, 176, 371, 154, 183, 395,
+	93, 112, 431, 95, 171, 375, 337, 335, 178, 286,
+	174, 368, 254, 61, 209, 211, 72, 244, 214, 65,
+	376, 89, 215, 328, 218, 303, 150, 185, 150, 223,
+	104, 90, 242, 90, 155, 245, 230, 221, 110, 196,
+	231, 222, 440, 98, 272, 89, 235, 158, 229, 104,
+	156, 232, 226, 295, 250, 340, 248, 271, 270, 227,
+	199, 200, 201, 196, 295, 264, 230, 197, 198, 199,
+	200, 201, 196, 262, 249, 149, 120, 71, 266, 267,
+	186, 263, 253, 268, 392, 265, 273, 274, 380, 277,
+	278, 279, 280, 281, 282, 283, 284, 304, 269, 87,
+	241, 243, 240, 86, 275, 296, 187, 186, 101, 187,
+	186, 120, 120, 166, 58, 378, 147, 301, 394, 305,
+	290, 292, 393, 302, 298, 365, 147, 380, 306, 361,
+	169, 364, 150, 359, 362, 310, 150, 90, 360, 276,
+	363, 312, 225, 225, 309, 194, 197, 198, 199, 200,
+	201, 196, 174, 262, 287, 403, 327, 311, 314, 44,
+	45, 46, 47, 180, 168, 81, 330, 331, 22, 23,
+	24, 25, 261, 297, 315, 147, 109, 260, 150, 329,
+	109, 343, 334, 90, 348, 414, 120, 341, 344, 413,
+	342, 339, 26, 412, 42, 336, 181, 153, 174, 195,
+	194, 197, 198, 199, 200, 201, 196, 219, 262, 262,
+	251, 357, 358, 217, 39, 451, 216, 109, 143, 469,
+	351, 228, 317, 318, 319, 320, 321, 374, 322, 323,
+	468, 22, 467, 466, 381, 377, 464, 463, 387, 48,
+	326, 383, 386, 382, 313, 42, 31, 32, 89, 33,
+	34, 36, 325, 37, 38, 184, 72, 261, 27, 28,
+	30, 29, 260, 50, 51, 52, 53, 42, 398, 429,
+	40, 41, 372, 399, 370, 350, 349, 69, 410, 408,
+	74, 75, 409, 430, 411, 247, 229, 246, 418, 231,
+	177, 164, 161, 422, 422, 422, 416, 417, 386, 145,
+	427, 423, 424, 157, 419, 97, 150, 420, 415, 435,
+	401, 90, 400, 441, 388, 144, 107, 333, 22, 236,
+	291, 449, 131, 135, 165, 450, 141, 79, 77, 346,
+	458, 465, 460, 118, 132, 133, 134, 173, 123, 139,
+	195, 194, 197, 198, 199, 200, 201, 196, 462, 461,
+	457, 455, 300, 131, 135, 454, 391, 141, 122, 347,
+	142, 402, 390, 356, 118, 132, 133, 134, 225, 123,
+	139, 22, 83, 49, 439, 425, 137, 138, 116, 22,
+	442, 444, 436, 438, 426, 352, 131, 135, 21, 122,
+	141, 142, 20, 373, 19, 18, 17, 89, 132, 133,
+	134, 16, 123, 139, 15, 14, 140, 137, 138, 116,
+	195, 194, 197, 198, 199, 200, 201, 196, 289, 131,
+	135, 13, 122, 141, 142, 12, 111, 237, 55, 307,
+	89, 132, 133, 134, 239, 123, 139, 140, 91, 175,
+	137, 138, 428, 404, 384, 389, 355, 338, 220, 22,
+	293, 135, 130, 127, 141, 122, 129, 142, 234, 252,
+	124, 89, 132, 133, 134, 135, 153, 139, 141, 188,
+	140, 405, 406, 137, 138, 89, 132, 133, 134, 135,
+	153, 139, 141, 233, 121, 366, 151, 259, 142, 89,
+	132, 133, 134, 316, 153, 139, 257, 117, 135, 324,
+	182, 141, 142, 140, 137, 138, 78, 43, 89, 132,
+	133, 134, 80, 153, 139, 11, 142, 10, 137, 138,
+	332, 195, 194, 197, 198, 199, 200, 201, 196, 9,
+	8, 7, 137, 138, 140, 142, 6, 195, 194, 197,
+	198, 199, 200, 201, 196, 5, 4, 2, 140, 1,
+	0, 137, 138, 0, 0, 0, 0, 190, 192, 0,
+	0, 0, 140, 202, 203, 204, 205, 206, 207, 208,
+	193, 191, 189, 195, 194, 197, 198, 199, 200, 201,
+	196, 140, 195, 194, 197, 198, 199, 200, 201, 196,
 }
 var yyPact = [...]int{
 
-	335, -1000, -1000, 196, -1000, -1000, -1000, -1000, -1000, -1000,
+	283, -1000, -1000, 241, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -49, -14, 9, 5, -1000, 37,
-	-1000, -1000, -1000, 71, 234, -1000, 434, 386, -1000, -1000,
-	-1000, 383, -1000, -69, 327, 426, 84, -72, -31, 234,
-	-1000, -6, 234, -1000, 343, -74, 234, -74, -1000, 368,
-	286, -1000, 54, -1000, -1000, -11, -1000, -1000, 413, -1000,
-	252, 367, 346, 327, 203, 462, -1000, 80, -1000, 53,
-	338, 93, 234, -1000, 337, -1000, -57, 336, 379, 149,
-	234, 327, 389, 295, 329, 327, -1000, 251, -1000, -1000,
-	330, 48, 98, 518, -1000, 42, 314, -1000, -1000, -1000,
-	476, 285, 282, -1000, 272, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 476, -1000, 327, 295, 418,
-	295, -1000, 333, 443, 6, 326, -1000, 377, -77, -1000,
-	195, -1000, 325, -1000, -1000, 324, -1000, 267, -1000, 263,
-	196, 16, -1000, -1000, -1000, 271, 413, -1000, -1000, 234,
-	128, 42, 42, 476, 263, 94, 476, 476, 161, 476,
-	476, 476, 476, 476, 476, 476, 476, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 518, -30, 14, 31, 518,
-	-1000, 230, 413, -1000, 434, 87, 466, 253, 237, 403,
-	42, -1000, 476, 466, 466, -1000, -1000, 47, -1000, -1000,
-	146, 234, -1000, -65, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, 389, 295, 213, -1000, -1000, 295, 229, 562,
-	313, 289, 45, -1000, -1000, -1000, -1000, -1000, 108, 466,
-	-1000, 263, 476, 476, 466, 494, -1000, 375, 236, 525,
-	-1000, 79, 79, 61, 61, 61, -1000, -1000, 476, -1000,
-	-1000, 12, 413, 4, 154, -1000, 42, 389, 295, 403,
-	399, 401, 98, 466, 234, 321, -1000, -1000, 305, -1000,
-	-1000, 203, 263, -1000, 416, 271, 271, -1000, -1000, 179,
-	166, 201, 199, 198, 33, -1000, 298, 151, 297, -1000,
-	466, 449, 476, -1000, 466, -1000, 3, -1000, 8, -1000,
-	476, 119, 140, 150, 399, -1000, 476, 476, -1000, -1000,
-	-1000, -1000, 408, 390, 562, 113, -1000, 188, -1000, 175,
-	-1000, -1000, -1000, -1000, -34, -38, -39, -1000, -1000, -1000,
-	476, 466, -1000, -1000, 466, 476, -1000, 361, -1000, -1000,
-	381, 211, -1000, 480, -1000, 403, 42, 476, 42, -1000,
-	-1000, 259, 242, 223, 466, 466, 350, 476, 476, 476,
-	-1000, -1000, -1000, 399, 98, 210, 98, 234, 234, 234,
-	431, 466, 466, -1000, 323, -1, -1000, -8, -10, 295,
-	-1000, 429, 70, -1000, 234, -1000, -1000, 203, -1000, 234,
-	-1000, 234, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -24, -19, 34,
+	-2, -1000, 54, -1000, 1, 334, 334, -1000, 105, 334,
+	-1000, -1000, -1000, 494, 431, -1000, -1000, -1000, 429, -1000,
+	-13, 345, 483, 134, -26, 20, 334, -1000, 24, 334,
+	-1000, 394, -41, 334, -41, 334, 334, -1000, 334, 411,
+	260, -1000, 78, -1000, 22, 334, -1000, -1000, 453, -1000,
+	303, 410, 390, 345, 204, 110, 578, 334, -1000, 74,
+	108, 392, 111, 334, -1000, 381, -1000, -7, 380, 424,
+	180, 334, 242, 334, -1000, 334, -1000, 345, 433, 337,
+	379, 345, -1000, 334, 274, -1000, -1000, 356, 67, 172,
+	621, -1000, 519, 486, -1000, -1000, -1000, 597, 300, 297,
+	-1000, 291, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, 597, -1000, 345, 337, 478, 337, 204, 334,
+	-1000, -1000, 247, 564, 242, 378, 550, -1000, 419, -44,
+	-1000, 124, -1000, 376, -1000, -1000, 374, -1000, 334, -1000,
+	-1000, 301, -1000, 281, 241, 16, -1000, -1000, -1000, -67,
+	261, 453, -1000, -1000, 334, 129, 519, 519, 597, 281,
+	107, 597, 597, 203, 597, 597, 597, 597, 597, 597,
+	597, 597, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	621, -20, 13, -8, 621, -1000, 422, 453, -1000, 494,
+	125, 630, 264, 253, 459, 519, -1000, 242, 597, 630,
+	-1000, 65, 630, -1000, -1000, -1000, -1000, 164, 334, -1000,
+	-10, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 334,
+	433, 337, 235, -1000, -1000, 337, 332, 252, 299, 341,
+	346, 63, -1000, -1000, -1000, -1000, -1000, 145, 630, -1000,
+	281, 597, 597, 630, 585, -1000, 416, 113, 192, -1000,
+	104, 104, 80, 80, 80, -1000, -1000, 597, -1000, -1000,
+	11, 453, 10, 114, -1000, 519, 433, 337, 459, 434,
+	465, 172, 630, 334, 365, -1000, -1000, 364, -1000, -1000,
+	204, 281, -1000, -16, 472, 261, 261, -1000, -1000, 210,
+	206, 217, 208, 202, 28, -1000, 363, 0, 361, -1000,
+	630, 458, 597, -1000, 630, -1000, 9, -1000, 58, -1000,
+	597, 175, 155, 194, 434, -1000, 597, 597, -1000, -1000,
+	-1000, -1000, 409, -1000, -1000, 470, 462, 299, 151, -1000,
+	199, -1000, 195, -1000, -1000, -1000, -1000, 19, 7, -17,
+	-1000, -1000, -1000, 597, 630, -1000, -1000, 630, 597, -1000,
+	406, -1000, -1000, 388, 233, -1000, 569, -1000, 12, 459,
+	519, 597, 519, -1000, -1000, 277, 273, 269, 630, 630,
+	401, 597, 597, 597, -1000, -1000, -1000, 345, 434, 172,
+	232, 172, 334, 334, 334, 488, 630, 630, -1000, 334,
+	373, 6, -1000, -3, -4, 337, -87, -1000, -1000, 487,
+	91, -1000, 334, -1000, -1000, 204, -18, -1000, -40, -1000,
+	334, -1000, 260, 302, -54, 461, 457, -71, 456, 334,
+	-1000, -5, 455, 454, 325, 324, 437, 321, -1000, -1000,
+	-1000, 320, 318, -1000, -1000, 307, -1000, -1000, -1000, -1000,
 }
 var yyPgo = [...]int{
 
-	0, 620, 619, 15, 618, 617, 616, 615, 614, 613,
-	578, 577, 610, 576, 564, 563, 230, 18, 21, 562,
-	561, 560, 548, 14, 547, 535, 22, 533, 5, 20,
-	6, 532, 531, 17, 530, 13, 26, 4, 529, 519,
-	11, 515, 1, 514, 511, 19, 506, 505, 492, 491,
-	10, 485, 3, 484, 2, 482, 23, 481, 9, 8,
-	12, 175, 478, 472, 470, 468, 466, 0, 7, 465,
-	463, 462, 461, 452, 451, 443, 442, 440,
+	0, 669, 667, 21, 666, 665, 656, 651, 650, 649,
+	637, 635, 359, 632, 627, 626, 234, 23, 19, 620,
+	619, 617, 616, 15, 613, 607, 16, 605, 7, 20,
+	11, 604, 589, 13, 580, 14, 28, 6, 579, 576,
+	12, 573, 5, 572, 570, 17, 568, 567, 566, 565,
+	9, 564, 4, 563, 18, 562, 2, 559, 10, 3,
+	24, 163, 558, 554, 549, 548, 547, 0, 8, 546,
+	545, 541, 525, 524, 521, 29, 25, 516, 515, 514,
+	512, 508, 505, 504, 503, 502, 501, 500, 493,
 }
 var yyR1 = [...]int{
 
 	0, 1, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 2, 2, 2, 2, 2, 2, 2, 3, 3,
-	3, 4, 4, 73, 73, 5, 6, 7, 7, 7,
-	7, 70, 70, 71, 72, 74, 74, 75, 76, 8,
-	8, 8, 9, 9, 9, 10, 11, 11, 11, 77,
-	12, 13, 13, 14, 14, 14, 14, 14, 15, 15,
-	17, 17, 18, 18, 18, 21, 21, 19, 19, 19,
-	22, 22, 23, 23, 23, 23, 20, 20, 20, 24,
-	24, 24, 24, 24, 24, 24, 24, 24, 25, 25,
-	25, 26, 26, 27, 27, 27, 27, 28, 28, 29,
-	29, 30, 30, 30, 30, 30, 31, 31, 31, 31,
-	31, 31, 31, 31, 31, 31, 32, 32, 32, 32,
-	32, 32, 32, 33, 33, 38, 38, 36, 36, 40,
-	37, 37, 35, 35, 35, 35, 35, 35, 35, 35,
-	35, 35, 35, 35, 35, 35, 35, 35, 35, 39,
-	39, 41, 41, 41, 43, 46, 46, 44, 44, 45,
-	47, 47, 42, 42, 42, 34, 34, 34, 34, 48,
-	48, 49, 49, 50, 50, 51, 51, 52, 53, 53,
-	53, 54, 54, 54, 54, 55, 55, 55, 56, 56,
-	57, 57, 58, 58, 59, 59, 60, 60, 61, 61,
-	62, 62, 16, 16, 63, 63, 63, 63, 63, 64,
-	64, 65, 65, 66, 66, 67, 68, 69, 69,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 3, 3, 3, 4, 4, 77, 77, 5, 6,
+	7, 7, 7, 7, 7, 7, 7, 70, 70, 70,
+	75, 75, 76, 76, 71, 72, 72, 72, 73, 74,
+	78, 78, 79, 80, 81, 82, 82, 82, 83, 83,
+	84, 84, 84, 84, 84, 85, 85, 86, 86, 86,
+	87, 87, 87, 8, 8, 8, 9, 9, 9, 10,
+	11, 11, 11, 88, 12, 13, 13, 14, 14, 14,
+	14, 14, 15, 15, 17, 17, 18, 18, 18, 21,
+	21, 19, 19, 19, 22, 22, 23, 23, 23, 23,
+	20, 20, 20, 24, 24, 24, 24, 24, 24, 24,
+	24, 24, 25, 25, 25, 26, 26, 27, 27, 27,
+	27, 28, 28, 29, 29, 30, 30, 30, 30, 30,
+	31, 31, 31, 31, 31, 31, 31, 31, 31, 31,
+	32, 32, 32, 32, 32, 32, 32, 33, 33, 38,
+	38, 36, 36, 40, 37, 37, 35, 35, 35, 35,
+	35, 35, 35, 35, 35, 35, 35, 35, 35, 35,
+	35, 35, 35, 39, 39, 41, 41, 41, 43, 46,
+	46, 44, 44, 45, 47, 47, 42, 42, 42, 34,
+	34, 34, 34, 48, 48, 49, 49, 50, 50, 51,
+	51, 52, 53, 53, 53, 54, 54, 54, 54, 55,
+	55, 55, 56, 56, 57, 57, 58, 58, 59, 59,
+	60, 60, 60, 61, 61, 62, 62, 16, 16, 63,
+	63, 63, 63, 63, 64, 64, 65, 65, 66, 66,
+	67, 68, 69, 69,
 }
 var yyR2 = [...]int{
 
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 1, 4, 12,
-	3, 8, 8, 6, 6, 8, 7, 3, 4, 4,
-	6, 1, 2, 1, 1, 4, 2, 2, 4, 5,
-	8, 4, 6, 7, 4, 5, 4, 5, 5, 0,
-	2, 0, 2, 1, 2, 1, 1, 1, 0, 1,
-	1, 3, 1, 2, 3, 1, 1, 0, 1, 2,
-	1, 3, 3, 3, 3, 5, 0, 1, 2, 1,
-	1, 2, 3, 2, 3, 2, 2, 2, 1, 3,
-	1, 1, 3, 0, 5, 5, 5, 1, 3, 0,
-	2, 1, 3, 3, 2, 3, 3, 3, 4, 3,
-	4, 5, 6, 3, 4, 2, 1, 1, 1, 1,
-	1, 1, 1, 2, 1, 1, 3, 3, 1, 3,
-	1, 3, 1, 1, 1, 3, 3, 3, 3, 3,
-	3, 3, 3, 2, 3, 4, 5, 4, 1, 1,
-	1, 1, 1, 1, 5, 0, 1, 1, 2, 4,
-	0, 2, 1, 3, 5, 1, 1, 1, 1, 0,
-	3, 0, 2, 0, 3, 1, 3, 2, 0, 1,
-	1, 0, 2, 4, 4, 0, 2, 4, 0, 3,
-	1, 3, 0, 5, 1, 3, 3, 3, 0, 2,
-	0, 3, 0, 1, 1, 1, 1, 1, 1, 0,
-	1, 0, 1, 0, 2, 1, 0, 0, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 4, 12, 3, 8, 8, 6, 6, 8, 7,
+	3, 4, 4, 4, 6, 4, 5, 1, 2, 3,
+	1, 3, 1, 2, 1, 1, 3, 4, 2, 3,
+	4, 2, 2, 4, 14, 0, 1, 1, 0, 2,
+	0, 4, 4, 5, 4, 0, 2, 0, 4, 4,
+	0, 3, 3, 5, 8, 4, 6, 7, 4, 5,
+	4, 5, 5, 0, 2, 0, 2, 1, 2, 1,
+	1, 1, 0, 1, 1, 3, 1, 2, 3, 1,
+	1, 0, 1, 2, 1, 3, 3, 3, 3, 5,
+	0, 1, 2, 1, 1, 2, 3, 2, 3, 2,
+	2, 2, 1, 3, 1, 1, 3, 0, 5, 5,
+	5, 1, 3, 0, 2, 1, 3, 3, 2, 3,
+	3, 3, 4, 3, 4, 5, 6, 3, 4, 2,
+	1, 1, 1, 1, 1, 1, 1, 2, 1, 1,
+	3, 3, 1, 3, 1, 3, 1, 1, 1, 3,
+	3, 3, 3, 3, 3, 3, 3, 2, 3, 4,
+	5, 4, 1, 1, 1, 1, 1, 1, 5, 0,
+	1, 1, 2, 4, 0, 2, 1, 3, 5, 1,
+	1, 1, 1, 0, 3, 0, 2, 0, 3, 1,
+	3, 2, 0, 1, 1, 0, 2, 4, 4, 0,
+	2, 4, 0, 3, 1, 3, 0, 5, 1, 3,
+	3, 3, 3, 0, 2, 0, 3, 0, 1, 1,
+	1, 1, 1, 1, 0, 1, 0, 1, 0, 2,
+	1, 0, 0, 1,
 }
 var yyChk = [...]int{
 
 	-1000, -1, -2, -3, -4, -5, -6, -7, -8, -9,
-	-10, -11, -70, -71, -72, -73, -74, -75, -76, 5,
-	6, 7, 8, 29, 94, 95, 97, 96, 83, 84,
-	86, 87, 89, 90, 51, 106, -14, 38, 39, 40,
-	41, -12, -77, -12, -12, -12, -12, 98, -65, 100,
-	104, -16, 100, 102, 98, 98, 99, 100, 85, -12,
-	-26, 91, 31, -67, 31, -12, -3, 17, -15, 18,
-	-13, -16, -26, 9, -59, 88, -60, -42, -67, 31,
-	-62, 103, 99, -67, 98, -67, 31, -61, 103, -67,
-	-61, 25, -56, 36, 80, -69, 98, -17, -18, 76,
-	-21, 31, -30, -35, -31, 56, 36, -34, -42, -36,
-	-41, -67, -39, -43, 20, 32, 33, 34, 21, -40,
-	74, 75, 37, 103, 24, 58, 35, 25, 29, -26,
-	42, 28, -35, 36, 62, 80, 31, 56, -67, -68,
-	31, -68, 101, 31, 20, 53, -67, -26, -33, 24,
-	-3, -57, -42, 31, -26, 9, 42, -19, -67, 19,
-	80, 55, 54, -32, 71, 56, 70, 57, 69, 73,
-	72, 79, 74, 75, 76, 77, 78, 62, 63, 64,
-	65, 66, 67, 68, -30, -35, -30, -37, -3, -35,
-	-35, 36, 36, -40, 36, -46, -35, -26, -59, -29,
-	10, -60, 93, -35, -35, 53, -67, 31, -68, 20,
-	-66, 105, -63, 97, 95, 28, 96, 13, 31, 31,
-	31, -68, -56, 29, -38, -36, 107, 42, -22, -23,
-	-25, 36, 31, -40, -18, -67, 76, -30, -30, -35,
-	-36, 71, 70, 57, -35, -35, 21, 56, -35, -35,
-	-35, -35, -35, -35, -35, -35, 107, 107, 42, 107,
-	107, -17, 18, -17, -44, -45, 59, -56, 29, -29,
-	-50, 13, -30, -35, 80, 53, -67, -68, -64, 101,
-	-33, -59, 42, -42, -29, 42, -24, 43, 44, 45,
+	-10, -11, -70, -71, -72, -73, -74, -77, -78, -79,
+	-80, -81, 5, 6, 7, 8, 29, 95, 96, 98,
+	97, 83, 84, 86, 87, -67, 88, 90, 91, 51,
+	107, 108, 31, -14, 38, 39, 40, 41, -12, -88,
+	-12, -12, -12, -12, 99, -65, 101, 105, -16, 101,
+	103, 99, 99, 100, 101, 85, 102, -67, -67, -12,
+	-26, 92, 31, -67, -12, -12, -3, 17, -15, 18,
+	-13, -16, -26, 9, -59, -67, 89, 85, -60, 31,
+	-42, -62, 104, 100, -67, 99, -67, 31, -61, 104,
+	-67, -61, -75, -76, -67, -67, -67, 25, -56, 36,
+	80, -69, 99, -67, -17, -18, 76, -21, 31, -30,
+	-35, -31, 56, 36, -34, -42, -36, -41, -67, -39,
+	-43, 20, 32, 33, 34, 21, -40, 74, 75, 37,
+	104, 24, 58, 35, 25, 29, -26, 42, -59, 85,
+	-67, 28, -35, 36, -75, 80, 62, 31, 56, -67,
+	-68, 31, -68, 102, 31, 20, 53, -67, 42, -67,
+	-67, -26, -33, 24, -3, -57, -42, 31, -26, -67,
+	9, 42, -19, -67, 19, 80, 55, 54, -32, 71,
+	56, 70, 57, 69, 73, 72, 79, 74, 75, 76,
+	77, 78, 62, 63, 64, 65, 66, 67, 68, -30,
+	-35, -30, -37, -3, -35, -35, 36, 36, -40, 36,
+	-46, -35, -26, -59, -29, 10, -60, -75, 94, -35,
+	-67, 31, -35, 53, 28, -68, 20, -66, 106, -63,
+	98, 96, 28, 97, 13, 31, 31, 31, -68, -76,
+	-56, 29, -38, -36, 116, 42, 109, -22, -23, -25,
+	36, 31, -40, -18, -67, 76, -30, -30, -35, -36,
+	71, 70, 57, -35, -35, 21, 56, -35, -35, -35,
+	-35, -35, -35, -35, -35, 116, 116, 42, 116, 116,
+	-17, 18, -17, -44, -45, 59, -56, 29, -29, -50,
+	13, -30, -35, 80, 53, -67, -68, -64, 102, -33,
+	-59, 42, -42, 32, -29, 42, -24, 43, 44, 45,
 	46, 47, 49, 50, -20, 31, 19, -23, 80, -36,
-	-35, -35, 55, 21, -35, 107, -17, 107, -47, -45,
+	-35, -35, 55, 21, -35, 116, -17, 116, -47, -45,
 	61, -30, -33, -59, -50, -54, 15, 14, -67, 31,
-	31, -36, -48, 11, -23, -23, 43, 48, 43, 48,
-	43, 43, 43, -27, 51, 102, 52, 31, 107, 31,
-	55, -35, 107, 82, -35, 60, -58, 53, -58, -54,
-	-35, -51, -52, -35, -68, -49, 12, 14, 53, 43,
-	43, 99, 99, 99, -35, -35, 26, 42, 92, 42,
-	-53, 22, 23, -50, -30, -37, -30, 36, 36, 36,
-	27, -35, -35, -52, -54, -28, -67, -28, -28, 7,
-	-55, 16, 30, 107, 42, 107, 107, -59, 7, 71,
-	-67, -67, -67,
+	31, -36, -82, 90, 103, -48, 11, -23, -23, 43,
+	48, 43, 48, 43, 43, 43, -27, 51, 103, 52,
+	31, 116, 31, 55, -35, 116, 82, -35, 60, -58,
+	53, -58, -54, -35, -51, -52, -35, -68, 25, -49,
+	12, 14, 53, 43, 43, 100, 100, 100, -35, -35,
+	26, 42, 93, 42, -53, 22, 23, 99, -50, -30,
+	-37, -30, 36, 36, 36, 27, -35, -35, -52, -26,
+	-54, -28, -67, -28, -28, 7, -83, -67, -55, 16,
+	30, 116, 42, 116, 116, -59, -85, 114, -84, 7,
+	71, -67, -87, 103, -86, 110, 112, 111, 113, -67,
+	-56, 33, 115, 110, 14, 14, 112, 14, -67, 114,
+	-67, 14, 14, 32, 32, 14, 32, 32, 32, 32,
 }
 var yyDef = [...]int{
 
 	0, -2, 1, 2, 3, 4, 5, 6, 7, 8,
-	9, 10, 11, 12, 13, 14, 15, 16, 17, 49,
-	49, 49, 49, 49, 211, 202, 0, 0, 31, 0,
-	33, 34, 49, 0, 0, 49, 0, 53, 55, 56,
-	57, 58, 51, 202, 0, 0, 0, 200, 0, 0,
-	212, 0, 0, 203, 0, 198, 0, 198, 32, 0,
-	188, 36, 91, 37, 215, 217, 20, 54, 0, 59,
-	50, 0, 0, 0, 27, 0, 194, 0, 162, 215,
-	0, 0, 0, 216, 0, 216, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 218, 18, 60, 62,
-	67, 215, 65, 66, 101, 0, 0, 132, 133, 134,
-	0, 162, 0, 148, 0, 165, 166, 167, 168, 128,
-	151, 152, 153, 149, 150, 155, 52, 0, 0, 99,
-	0, 28, 29, 0, 0, 0, 216, 0, 213, 41,
-	0, 44, 0, 46, 199, 0, 216, 188, 35, 0,
-	124, 0, 190, 92, 38, 0, 0, 63, 68, 0,
+	9, 10, 11, 12, 13, 14, 15, 16, 17, 18,
+	19, 20, 83, 83, 83, 83, 83, 246, 237, 0,
+	0, 37, 0, 44, 45, 0, 0, 83, 0, 0,
+	83, 83, 250, 0, 87, 89, 90, 91, 92, 85,
+	237, 0, 0, 0, 235, 0, 0, 247, 0, 0,
+	238, 0, 233, 0, 233, 38, 0, 48, 0, 0,
+	222, 51, 125, 52, 252, 0, 23, 88, 0, 93,
+	84, 0, 0, 0, 30, 196, 0, 0, 228, 250,
+	0, 0, 0, 0, 251, 0, 251, 0, 0, 0,
+	0, 0, 39, 40, 42, 46, 49, 0, 0, 0,
+	0, 0, 253, 0, 21, 94, 96, 101, 250, 99,
+	100, 135, 0, 0, 166, 167, 168, 0, 196, 0,
+	182, 0, 199, 200, 201, 202, 162, 185, 186, 187,
+	183, 184, 189, 86, 0, 0, 133, 0, 31, 0,
+	196, 32, 33, 0, 35, 0, 0, 251, 0, 248,
+	75, 0, 78, 0, 80, 234, 0, 251, 0, 43,
+	47, 222, 50, 0, 158, 0, 224, 126, 53, 0,
+	0, 0, 97, 102, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 116, 117, 118,
-	119, 120, 121, 122, 104, 0, 0, 0, 0, 130,
-	143, 0, 0, 115, 0, 0, 156, 188, 99, 173,
-	0, 195, 0, 130, 196, 197, 163, 215, 39, 201,
-	0, 0, 216, 209, 204, 205, 206, 207, 208, 45,
-	47, 48, 0, 0, 123, 125, 189, 0, 99, 70,
-	76, 0, 88, 90, 61, 69, 64, 102, 103, 106,
-	107, 0, 0, 0, 109, 0, 113, 0, 135, 136,
-	137, 138, 139, 140, 141, 142, 105, 127, 0, 129,
-	144, 0, 0, 0, 160, 157, 0, 0, 0, 173,
-	181, 0, 100, 30, 0, 0, 214, 42, 0, 210,
-	23, 24, 0, 191, 169, 0, 0, 79, 80, 0,
-	0, 0, 0, 0, 93, 77, 0, 0, 0, 108,
-	110, 0, 0, 114, 131, 145, 0, 147, 0, 158,
-	0, 0, 192, 192, 181, 26, 0, 0, 164, 216,
-	43, 126, 171, 0, 71, 74, 81, 0, 83, 0,
-	85, 86, 87, 72, 0, 0, 0, 78, 73, 89,
-	0, 111, 146, 154, 161, 0, 21, 0, 22, 25,
-	182, 174, 175, 178, 40, 173, 0, 0, 0, 82,
-	84, 0, 0, 0, 112, 159, 0, 0, 0, 0,
-	177, 179, 180, 181, 172, 170, 75, 0, 0, 0,
-	0, 183, 184, 176, 185, 0, 97, 0, 0, 0,
-	19, 0, 0, 94, 0, 95, 96, 193, 186, 0,
-	98, 0, 187,
+	0, 0, 150, 151, 152, 153, 154, 155, 156, 138,
+	0, 0, 0, 0, 164, 177, 0, 0, 149, 0,
+	0, 190, 222, 133, 207, 0, 229, 36, 0, 164,
+	197, 250, 230, 231, 232, 73, 236, 0, 0, 251,
+	244, 239, 240, 241, 242, 243, 79, 81, 82, 41,
+	0, 0, 157, 159, 223, 0, 0, 133, 104, 110,
+	0, 122, 124, 95, 103, 98, 136, 137, 140, 141,
+	0, 0, 0, 143, 0, 147, 0, 169, 170, 171,
+	172, 173, 174, 175, 176, 139, 161, 0, 163, 178,
+	0, 0, 0, 194, 191, 0, 0, 0, 207, 215,
+	0, 134, 34, 0, 0, 249, 76, 0, 245, 26,
+	27, 0, 225, 55, 203, 0, 0, 113, 114, 0,
+	0, 0, 0, 0, 127, 111, 0, 0, 0, 142,
+	144, 0, 0, 148, 165, 179, 0, 181, 0, 192,
+	0, 0, 226, 226, 215, 29, 0, 0, 198, 251,
+	77, 160, 0, 56, 57, 205, 0, 105, 108, 115,
+	0, 117, 0, 119, 120, 121, 106, 0, 0, 0,
+	112, 107, 123, 0, 145, 180, 188, 195, 0, 24,
+	0, 25, 28, 216, 208, 209, 212, 74, 0, 207,
+	0, 0, 0, 116, 118, 0, 0, 0, 146, 193,
+	0, 0, 0, 0, 211, 213, 214, 0, 215, 206,
+	204, 109, 0, 0, 0, 0, 217, 218, 210, 58,
+	219, 0, 131, 0, 0, 0, 65, 60, 22, 0,
+	0, 128, 0, 129, 130, 227, 70, 67, 59, 220,
+	0, 132, 222, 0, 66, 0, 0, 0, 0, 0,
+	54, 0, 0, 0, 0, 0, 0, 0, 221, 71,
+	72, 0, 0, 61, 62, 0, 64, 68, 69, 63,
 }
 var yyTok1 = [...]int{
 
@@ -548,7 +609,7 @@ var yyTok1 = [...]int{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 78, 73, 3,
-	36, 107, 76, 74, 42, 75, 80, 77, 3, 3,
+	36, 116, 76, 74, 42, 75, 80, 77, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	63, 62, 64, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
@@ -569,6 +630,7 @@ var yyTok2 = [...]int{
 	68, 69, 70, 71, 81, 82, 83, 84, 85, 86,
 	87, 88, 89, 90, 91, 92, 93, 94, 95, 96,
 	97, 98, 99, 100, 101, 102, 103, 104, 105, 106,
+	107, 108, 109, 110, 111, 112, 113, 114, 115,
 }
 var yyTok3 = [...]int{
 	0,
@@ -913,43 +975,43 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:193
+		//line ./sqlparser/sql.y:213
 		{
 			SetParseTree(yylex, yyDollar[1].statement)
 		}
 	case 2:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:199
+		//line ./sqlparser/sql.y:219
 		{
 			yyVAL.statement = yyDollar[1].selStmt
 		}
-	case 18:
+	case 21:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:220
+		//line ./sqlparser/sql.y:243
 		{
 			yyVAL.selStmt = &SimpleSelect{Comments: Comments(yyDollar[2].bytes2), Distinct: yyDollar[3].str, SelectExprs: yyDollar[4].selectExprs}
 		}
-	case 19:
+	case 22:
 		yyDollar = yyS[yypt-12 : yypt+1]
-		//line ./sqlparser/sql.y:224
+		//line ./sqlparser/sql.y:247
 		{
 			yyVAL.selStmt = &Select{Comments: Comments(yyDollar[2].bytes2), Distinct: yyDollar[3].str, SelectExprs: yyDollar[4].selectExprs, From: yyDollar[6].tableExprs, Where: NewWhere(AST_WHERE, yyDollar[7].boolExpr), GroupBy: GroupBy(yyDollar[8].valExprs), Having: NewWhere(AST_HAVING, yyDollar[9].boolExpr), OrderBy: yyDollar[10].orderBy, Limit: yyDollar[11].limit, Lock: yyDollar[12].str}
 		}
-	case 20:
+	case 23:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:228
+		//line ./sqlparser/sql.y:251
 		{
 			yyVAL.selStmt = &Union{Type: yyDollar[2].str, Left: yyDollar[1].selStmt, Right: yyDollar[3].selStmt}
 		}
-	case 21:
+	case 24:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line ./sqlparser/sql.y:235
+		//line ./sqlparser/sql.y:258
 		{
 			yyVAL.statement = &Insert{Comments: Comments(yyDollar[2].bytes2), Ignore: yyDollar[3].str, Table: yyDollar[5].tableName, Columns: yyDollar[6].columns, Rows: yyDollar[7].insRows, OnDup: OnDup(yyDollar[8].updateExprs)}
 		}
-	case 22:
+	case 25:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line ./sqlparser/sql.y:239
+		//line ./sqlparser/sql.y:262
 		{
 			cols := make(Columns, 0, len(yyDollar[7].updateExprs))
 			vals := make(ValTuple, 0, len(yyDollar[7].updateExprs))
@@ -959,15 +1021,15 @@ yydefault:
 			}
 			yyVAL.statement = &Insert{Comments: Comments(yyDollar[2].bytes2), Ignore: yyDollar[3].str, Table: yyDollar[5].tableName, Columns: cols, Rows: Values{vals}, OnDup: OnDup(yyDollar[8].updateExprs)}
 		}
-	case 23:
+	case 26:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line ./sqlparser/sql.y:251
+		//line ./sqlparser/sql.y:274
 		{
 			yyVAL.statement = &Replace{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[4].tableName, Columns: yyDollar[5].columns, Rows: yyDollar[6].insRows}
 		}
-	case 24:
+	case 27:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line ./sqlparser/sql.y:255
+		//line ./sqlparser/sql.y:278
 		{
 			cols := make(Columns, 0, len(yyDollar[6].updateExprs))
 			vals := make(ValTuple, 0, len(yyDollar[6].updateExprs))
@@ -977,39 +1039,45 @@ yydefault:
 			}
 			yyVAL.statement = &Replace{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[4].tableName, Columns: cols, Rows: Values{vals}}
 		}
-	case 25:
+	case 28:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line ./sqlparser/sql.y:268
+		//line ./sqlparser/sql.y:291
 		{
 			yyVAL.statement = &Update{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[3].tableName, Exprs: yyDollar[5].updateExprs, Where: NewWhere(AST_WHERE, yyDollar[6].boolExpr), OrderBy: yyDollar[7].orderBy, Limit: yyDollar[8].limit}
 		}
-	case 26:
+	case 29:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line ./sqlparser/sql.y:274
+		//line ./sqlparser/sql.y:297
 		{
 			yyVAL.statement = &Delete{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[4].tableName, Where: NewWhere(AST_WHERE, yyDollar[5].boolExpr), OrderBy: yyDollar[6].orderBy, Limit: yyDollar[7].limit}
 		}
-	case 27:
+	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:280
+		//line ./sqlparser/sql.y:303
 		{
 			yyVAL.statement = &Set{Comments: Comments(yyDollar[2].bytes2), Exprs: yyDollar[3].updateExprs}
 		}
-	case 28:
+	case 31:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:284
+		//line ./sqlparser/sql.y:307
+		{
+			yyVAL.statement = &Set{Comments: Comments(yyDollar[2].bytes2), Scope: string(yyDollar[3].bytes), Exprs: yyDollar[4].updateExprs}
+		}
+	case 32:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:311
 		{
 			yyVAL.statement = &Set{Comments: Comments(yyDollar[2].bytes2), Exprs: UpdateExprs{&UpdateExpr{Name: &ColName{Name: []byte("names")}, Expr: StrVal("default")}}}
 		}
-	case 29:
+	case 33:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:288
+		//line ./sqlparser/sql.y:315
 		{
 			yyVAL.statement = &Set{Comments: Comments(yyDollar[2].bytes2), Exprs: UpdateExprs{&UpdateExpr{Name: &ColName{Name: []byte("names")}, Expr: yyDollar[4].valExpr}}}
 		}
-	case 30:
+	case 34:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line ./sqlparser/sql.y:292
+		//line ./sqlparser/sql.y:319
 		{
 			yyVAL.statement = &Set{
 				Comments: Comments(yyDollar[2].bytes2),
@@ -1023,679 +1091,903 @@ yydefault:
 				},
 			}
 		}
-	case 31:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:308
-		{
-			yyVAL.statement = &Begin{}
-		}
-	case 32:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:312
-		{
-			yyVAL.statement = &Begin{}
-		}
-	case 33:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:319
-		{
-			yyVAL.statement = &Commit{}
-		}
-	case 34:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:325
-		{
-			yyVAL.statement = &Rollback{}
-		}
 	case 35:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:331
+		//line ./sqlparser/sql.y:333
 		{
-			yyVAL.statement = &Admin{Region: yyDollar[2].tableName, Columns: yyDollar[3].columns, Rows: yyDollar[4].insRows}
+			yyVAL.statement = &SetTransaction{Comments: Comments(yyDollar[2].bytes2), Characteristics: yyDollar[4].strs}
 		}
 	case 36:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:335
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:337
 		{
-			yyVAL.statement = &AdminHelp{}
+			yyVAL.statement = &SetTransaction{Comments: Comments(yyDollar[2].bytes2), Scope: string(yyDollar[3].bytes), Characteristics: yyDollar[5].strs}
 		}
 	case 37:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:341
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:343
 		{
-			yyVAL.statement = &UseDB{DB: string(yyDollar[2].bytes)}
+			yyVAL.statement = &Begin{}
 		}
 	case 38:
-		yyDollar = yyS[yypt-4 : yypt+1]
+		yyDollar = yyS[yypt-2 : yypt+1]
 		//line ./sqlparser/sql.y:347
 		{
-			yyVAL.statement = &Truncate{Comments: Comments(yyDollar[2].bytes2), TableOpt: yyDollar[3].str, Table: yyDollar[4].tableName}
+			yyVAL.statement = &Begin{}
 		}
 	case 39:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:353
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:351
 		{
-			yyVAL.statement = &DDL{Action: AST_CREATE, NewName: yyDollar[4].bytes}
+			yyVAL.statement = &Begin{Characteristics: yyDollar[3].strs}
 		}
 	case 40:
-		yyDollar = yyS[yypt-8 : yypt+1]
+		yyDollar = yyS[yypt-1 : yypt+1]
 		//line ./sqlparser/sql.y:357
 		{
-			// Change this to an alter statement
-			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[7].bytes, NewName: yyDollar[7].bytes}
+			yyVAL.strs = []string{yyDollar[1].str}
 		}
 	case 41:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:362
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:361
 		{
-			yyVAL.statement = &DDL{Action: AST_CREATE, NewName: yyDollar[3].bytes}
+			yyVAL.strs = append(yyDollar[1].strs, yyDollar[3].str)
 		}
 	case 42:
-		yyDollar = yyS[yypt-6 : yypt+1]
-		//line ./sqlparser/sql.y:368
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:367
 		{
-			yyVAL.statement = &DDL{Action: AST_ALTER, Ignore: yyDollar[2].str, Table: yyDollar[4].bytes, NewName: yyDollar[4].bytes}
+			yyVAL.str = string(yyDollar[1].bytes)
 		}
 	case 43:
-		yyDollar = yyS[yypt-7 : yypt+1]
-		//line ./sqlparser/sql.y:372
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:371
 		{
-			// Change this to a rename statement
-			yyVAL.statement = &DDL{Action: AST_RENAME, Ignore: yyDollar[2].str, Table: yyDollar[4].bytes, NewName: yyDollar[7].bytes}
+			yyVAL.str = yyDollar[1].str + " " + string(yyDollar[2].bytes)
 		}
 	case 44:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:377
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:378
 		{
-			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[3].bytes, NewName: yyDollar[3].bytes}
+			yyVAL.statement = &Commit{}
 		}
 	case 45:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:383
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:384
 		{
-			yyVAL.statement = &DDL{Action: AST_RENAME, Table: yyDollar[3].bytes, NewName: yyDollar[5].bytes}
+			yyVAL.statement = &Rollback{}
 		}
 	case 46:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:389
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:388
 		{
-			yyVAL.statement = &DDL{Action: AST_DROP, Table: yyDollar[4].bytes}
+			yyVAL.statement = &RollbackSavepoint{Name: yyDollar[3].bytes}
 		}
 	case 47:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:393
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:392
 		{
-			// Change this to an alter statement
-			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[5].bytes, NewName: yyDollar[5].bytes}
+			if !bytes.Equal(yyDollar[3].bytes, SAVEPOINT_BYTES) {
+				yylex.Error("expecting savepoint")
+				return 1
+			}
+			yyVAL.statement = &RollbackSavepoint{Name: yyDollar[4].bytes}
 		}
 	case 48:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:398
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:402
 		{
-			yyVAL.statement = &DDL{Action: AST_DROP, Table: yyDollar[4].bytes}
+			if !bytes.Equal(yyDollar[1].bytes, SAVEPOINT_BYTES) {
+				yylex.Error("expecting savepoint")
+				return 1
+			}
+			yyVAL.statement = &Savepoint{Name: yyDollar[2].bytes}
 		}
 	case 49:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:403
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:412
 		{
-			SetAllowComments(yylex, true)
+			if !bytes.Equal(yyDollar[2].bytes, SAVEPOINT_BYTES) {
+				yylex.Error("expecting savepoint")
+				return 1
+			}
+			yyVAL.statement = &ReleaseSavepoint{Name: yyDollar[3].bytes}
 		}
 	case 50:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:407
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:422
 		{
-			yyVAL.bytes2 = yyDollar[2].bytes2
-			SetAllowComments(yylex, false)
+			yyVAL.statement = &Admin{Region: yyDollar[2].tableName, Columns: yyDollar[3].columns, Rows: yyDollar[4].insRows}
 		}
 	case 51:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:413
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:426
 		{
-			yyVAL.bytes2 = nil
+			yyVAL.statement = &AdminHelp{}
 		}
 	case 52:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:417
+		//line ./sqlparser/sql.y:432
 		{
-			yyVAL.bytes2 = append(yyDollar[1].bytes2, yyDollar[2].bytes)
+			yyVAL.statement = &UseDB{DB: string(yyDollar[2].bytes)}
 		}
 	case 53:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:423
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:438
 		{
-			yyVAL.str = AST_UNION
+			yyVAL.statement = &Truncate{Comments: Comments(yyDollar[2].bytes2), TableOpt: yyDollar[3].str, Table: yyDollar[4].tableName}
 		}
 	case 54:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:427
+		yyDollar = yyS[yypt-14 : yypt+1]
+		//line ./sqlparser/sql.y:444
 		{
-			yyVAL.str = AST_UNION_ALL
+			if !bytes.Equal(yyDollar[3].bytes, DATA_BYTES) {
+				yylex.Error("expecting data")
+				return 1
+			}
+			//only the file sent by client is supported
+			if !bytes.Equal(yyDollar[4].bytes, LOCAL_BYTES) {
+				yylex.Error("expecting local")
+				return 1
+			}
+			yyVAL.statement = &LoadData{Comments: Comments(yyDollar[2].bytes2), File: StrVal(yyDollar[6].bytes), DupOpt: yyDollar[7].str, Table: yyDollar[10].tableName, Fields: yyDollar[11].loadFields, Lines: yyDollar[12].loadLines, IgnoreLines: NumVal(yyDollar[13].bytes), Columns: yyDollar[14].columns}
 		}
 	case 55:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:431
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:458
 		{
-			yyVAL.str = AST_SET_MINUS
+			yyVAL.str = ""
 		}
 	case 56:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:435
+		//line ./sqlparser/sql.y:460
 		{
-			yyVAL.str = AST_EXCEPT
+			yyVAL.str = AST_LOAD_REPLACE
 		}
 	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:439
+		//line ./sqlparser/sql.y:462
 		{
-			yyVAL.str = AST_INTERSECT
+			yyVAL.str = AST_IGNORE
 		}
 	case 58:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:444
+		//line ./sqlparser/sql.y:465
 		{
-			yyVAL.str = ""
+			yyVAL.loadFields = nil
 		}
 	case 59:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:448
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:469
 		{
-			yyVAL.str = AST_DISTINCT
+			if !bytes.Equal(yyDollar[1].bytes, FIELDS_BYTES) && !bytes.Equal(yyDollar[1].bytes, COLUMNS_BYTES) {
+				yylex.Error("expecting fields")
+				return 1
+			}
+			if yyDollar[2].loadFields.Terminated == nil && yyDollar[2].loadFields.Enclosed == nil && yyDollar[2].loadFields.Escaped == nil {
+				yylex.Error("expecting terminated, enclosed or escaped")
+				return 1
+			}
+			yyVAL.loadFields = yyDollar[2].loadFields
 		}
 	case 60:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:454
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:482
 		{
-			yyVAL.selectExprs = SelectExprs{yyDollar[1].selectExpr}
+			yyVAL.loadFields = &LoadDataFields{}
 		}
 	case 61:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:458
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:486
 		{
-			yyVAL.selectExprs = append(yyVAL.selectExprs, yyDollar[3].selectExpr)
+			yyDollar[1].loadFields.Terminated = StrVal(yyDollar[4].bytes)
+			yyVAL.loadFields = yyDollar[1].loadFields
 		}
 	case 62:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:464
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:491
 		{
-			yyVAL.selectExpr = &StarExpr{}
+			yyDollar[1].loadFields.Enclosed = StrVal(yyDollar[4].bytes)
+			yyVAL.loadFields = yyDollar[1].loadFields
 		}
 	case 63:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:468
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:496
 		{
-			yyVAL.selectExpr = &NonStarExpr{Expr: yyDollar[1].expr, As: yyDollar[2].bytes}
+			yyDollar[1].loadFields.Optionally = true
+			yyDollar[1].loadFields.Enclosed = StrVal(yyDollar[5].bytes)
+			yyVAL.loadFields = yyDollar[1].loadFields
 		}
 	case 64:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:472
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:502
 		{
-			yyVAL.selectExpr = &StarExpr{TableName: yyDollar[1].bytes}
+			yyDollar[1].loadFields.Escaped = StrVal(yyDollar[4].bytes)
+			yyVAL.loadFields = yyDollar[1].loadFields
 		}
 	case 65:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:478
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:508
 		{
-			yyVAL.expr = yyDollar[1].boolExpr
+			yyVAL.loadLines = nil
 		}
 	case 66:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:482
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:512
 		{
-			yyVAL.expr = yyDollar[1].valExpr
+			if yyDollar[2].loadLines.Starting == nil && yyDollar[2].loadLines.Terminated == nil {
+				yylex.Error("expecting starting or terminated")
+				return 1
+			}
+			yyVAL.loadLines = yyDollar[2].loadLines
 		}
 	case 67:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:487
+		//line ./sqlparser/sql.y:521
 		{
-			yyVAL.bytes = nil
+			yyVAL.loadLines = &LoadDataLines{}
 		}
 	case 68:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:491
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:525
 		{
-			yyVAL.bytes = yyDollar[1].bytes
+			yyDollar[1].loadLines.Starting = StrVal(yyDollar[4].bytes)
+			yyVAL.loadLines = yyDollar[1].loadLines
 		}
 	case 69:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:495
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:530
 		{
-			yyVAL.bytes = yyDollar[2].bytes
+			yyDollar[1].loadLines.Terminated = StrVal(yyDollar[4].bytes)
+			yyVAL.loadLines = yyDollar[1].loadLines
 		}
 	case 70:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:501
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:536
 		{
-			yyVAL.tableExprs = TableExprs{yyDollar[1].tableExpr}
+			yyVAL.bytes = nil
 		}
 	case 71:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:505
+		//line ./sqlparser/sql.y:540
 		{
-			yyVAL.tableExprs = append(yyVAL.tableExprs, yyDollar[3].tableExpr)
+			yyVAL.bytes = yyDollar[2].bytes
 		}
 	case 72:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:511
+		//line ./sqlparser/sql.y:544
 		{
-			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].smTableExpr, As: yyDollar[2].bytes, Hints: yyDollar[3].indexHints}
+			if !bytes.Equal(yyDollar[3].bytes, ROWS_BYTES) {
+				yylex.Error("expecting lines")
+				return 1
+			}
+			yyVAL.bytes = yyDollar[2].bytes
 		}
 	case 73:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:515
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:554
 		{
-			yyVAL.tableExpr = &ParenTableExpr{Expr: yyDollar[2].tableExpr}
+			yyVAL.statement = &DDL{Action: AST_CREATE, NewName: yyDollar[4].bytes}
 		}
 	case 74:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:519
+		yyDollar = yyS[yypt-8 : yypt+1]
+		//line ./sqlparser/sql.y:558
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+			// Change this to an alter statement
+			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[7].bytes, NewName: yyDollar[7].bytes}
 		}
 	case 75:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:523
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:563
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+			yyVAL.statement = &DDL{Action: AST_CREATE, NewName: yyDollar[3].bytes}
 		}
 	case 76:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:528
+		yyDollar = yyS[yypt-6 : yypt+1]
+		//line ./sqlparser/sql.y:569
 		{
-			yyVAL.bytes = nil
+			yyVAL.statement = &DDL{Action: AST_ALTER, Ignore: yyDollar[2].str, Table: yyDollar[4].bytes, NewName: yyDollar[4].bytes}
 		}
 	case 77:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:532
+		yyDollar = yyS[yypt-7 : yypt+1]
+		//line ./sqlparser/sql.y:573
 		{
-			yyVAL.bytes = yyDollar[1].bytes
+			// Change this to a rename statement
+			yyVAL.statement = &DDL{Action: AST_RENAME, Ignore: yyDollar[2].str, Table: yyDollar[4].bytes, NewName: yyDollar[7].bytes}
 		}
 	case 78:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:536
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:578
 		{
-			yyVAL.bytes = yyDollar[2].bytes
+			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[3].bytes, NewName: yyDollar[3].bytes}
 		}
 	case 79:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:542
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:584
 		{
-			yyVAL.str = AST_JOIN
+			yyVAL.statement = &DDL{Action: AST_RENAME, Table: yyDollar[3].bytes, NewName: yyDollar[5].bytes}
 		}
 	case 80:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:546
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line ./sqlparser/sql.y:590
 		{
-			yyVAL.str = AST_STRAIGHT_JOIN
+			yyVAL.statement = &DDL{Action: AST_DROP, Table: yyDollar[4].bytes}
 		}
 	case 81:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:550
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:594
 		{
-			yyVAL.str = AST_LEFT_JOIN
+			// Change this to an alter statement
+			yyVAL.statement = &DDL{Action: AST_ALTER, Table: yyDollar[5].bytes, NewName: yyDollar[5].bytes}
 		}
 	case 82:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:554
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:599
 		{
-			yyVAL.str = AST_LEFT_JOIN
+			yyVAL.statement = &DDL{Action: AST_DROP, Table: yyDollar[4].bytes}
 		}
 	case 83:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:558
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:604
 		{
-			yyVAL.str = AST_RIGHT_JOIN
+			SetAllowComments(yylex, true)
 		}
 	case 84:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:562
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:608
 		{
-			yyVAL.str = AST_RIGHT_JOIN
+			yyVAL.bytes2 = yyDollar[2].bytes2
+			SetAllowComments(yylex, false)
 		}
 	case 85:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:566
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:614
 		{
-			yyVAL.str = AST_JOIN
+			yyVAL.bytes2 = nil
 		}
 	case 86:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:570
+		//line ./sqlparser/sql.y:618
 		{
-			yyVAL.str = AST_CROSS_JOIN
+			yyVAL.bytes2 = append(yyDollar[1].bytes2, yyDollar[2].bytes)
 		}
 	case 87:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:624
+		{
+			yyVAL.str = AST_UNION
+		}
+	case 88:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:628
+		{
+			yyVAL.str = AST_UNION_ALL
+		}
+	case 89:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:632
+		{
+			yyVAL.str = AST_SET_MINUS
+		}
+	case 90:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:636
+		{
+			yyVAL.str = AST_EXCEPT
+		}
+	case 91:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:640
+		{
+			yyVAL.str = AST_INTERSECT
+		}
+	case 92:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:645
+		{
+			yyVAL.str = ""
+		}
+	case 93:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:649
+		{
+			yyVAL.str = AST_DISTINCT
+		}
+	case 94:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:655
+		{
+			yyVAL.selectExprs = SelectExprs{yyDollar[1].selectExpr}
+		}
+	case 95:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:659
+		{
+			yyVAL.selectExprs = append(yyVAL.selectExprs, yyDollar[3].selectExpr)
+		}
+	case 96:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:665
+		{
+			yyVAL.selectExpr = &StarExpr{}
+		}
+	case 97:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:574
+		//line ./sqlparser/sql.y:669
+		{
+			yyVAL.selectExpr = &NonStarExpr{Expr: yyDollar[1].expr, As: yyDollar[2].bytes}
+		}
+	case 98:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:673
+		{
+			yyVAL.selectExpr = &StarExpr{TableName: yyDollar[1].bytes}
+		}
+	case 99:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:679
+		{
+			yyVAL.expr = yyDollar[1].boolExpr
+		}
+	case 100:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:683
+		{
+			yyVAL.expr = yyDollar[1].valExpr
+		}
+	case 101:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:688
+		{
+			yyVAL.bytes = nil
+		}
+	case 102:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:692
+		{
+			yyVAL.bytes = yyDollar[1].bytes
+		}
+	case 103:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:696
+		{
+			yyVAL.bytes = yyDollar[2].bytes
+		}
+	case 104:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:702
+		{
+			yyVAL.tableExprs = TableExprs{yyDollar[1].tableExpr}
+		}
+	case 105:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:706
+		{
+			yyVAL.tableExprs = append(yyVAL.tableExprs, yyDollar[3].tableExpr)
+		}
+	case 106:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:712
+		{
+			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].smTableExpr, As: yyDollar[2].bytes, Hints: yyDollar[3].indexHints}
+		}
+	case 107:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:716
+		{
+			yyVAL.tableExpr = &ParenTableExpr{Expr: yyDollar[2].tableExpr}
+		}
+	case 108:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:720
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+		}
+	case 109:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line ./sqlparser/sql.y:724
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+		}
+	case 110:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line ./sqlparser/sql.y:729
+		{
+			yyVAL.bytes = nil
+		}
+	case 111:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:733
+		{
+			yyVAL.bytes = yyDollar[1].bytes
+		}
+	case 112:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:737
+		{
+			yyVAL.bytes = yyDollar[2].bytes
+		}
+	case 113:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:743
+		{
+			yyVAL.str = AST_JOIN
+		}
+	case 114:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line ./sqlparser/sql.y:747
+		{
+			yyVAL.str = AST_STRAIGHT_JOIN
+		}
+	case 115:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:751
+		{
+			yyVAL.str = AST_LEFT_JOIN
+		}
+	case 116:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:755
+		{
+			yyVAL.str = AST_LEFT_JOIN
+		}
+	case 117:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:759
+		{
+			yyVAL.str = AST_RIGHT_JOIN
+		}
+	case 118:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:763
+		{
+			yyVAL.str = AST_RIGHT_JOIN
+		}
+	case 119:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:767
+		{
+			yyVAL.str = AST_JOIN
+		}
+	case 120:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:771
+		{
+			yyVAL.str = AST_CROSS_JOIN
+		}
+	case 121:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line ./sqlparser/sql.y:775
 		{
 			yyVAL.str = AST_NATURAL_JOIN
 		}
-	case 88:
+	case 122:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:580
+		//line ./sqlparser/sql.y:781
 		{
 			yyVAL.smTableExpr = &TableName{Name: yyDollar[1].bytes}
 		}
-	case 89:
+	case 123:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:584
+		//line ./sqlparser/sql.y:785
 		{
 			yyVAL.smTableExpr = &TableName{Qualifier: yyDollar[1].bytes, Name: yyDollar[3].bytes}
 		}
-	case 90:
+	case 124:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:588
+		//line ./sqlparser/sql.y:789
 		{
 			yyVAL.smTableExpr = yyDollar[1].subquery
 		}
-	case 91:
+	case 125:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:594
+		//line ./sqlparser/sql.y:795
 		{
 			yyVAL.tableName = &TableName{Name: yyDollar[1].bytes}
 		}
-	case 92:
+	case 126:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:598
+		//line ./sqlparser/sql.y:799
 		{
 			yyVAL.tableName = &TableName{Qualifier: yyDollar[1].bytes, Name: yyDollar[3].bytes}
 		}
-	case 93:
+	case 127:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:603
+		//line ./sqlparser/sql.y:804
 		{
 			yyVAL.indexHints = nil
 		}
-	case 94:
+	case 128:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:607
+		//line ./sqlparser/sql.y:808
 		{
 			yyVAL.indexHints = &IndexHints{Type: AST_USE, Indexes: yyDollar[4].bytes2}
 		}
-	case 95:
+	case 129:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:611
+		//line ./sqlparser/sql.y:812
 		{
 			yyVAL.indexHints = &IndexHints{Type: AST_IGNORE, Indexes: yyDollar[4].bytes2}
 		}
-	case 96:
+	case 130:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:615
+		//line ./sqlparser/sql.y:816
 		{
 			yyVAL.indexHints = &IndexHints{Type: AST_FORCE, Indexes: yyDollar[4].bytes2}
 		}
-	case 97:
+	case 131:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:621
+		//line ./sqlparser/sql.y:822
 		{
 			yyVAL.bytes2 = [][]byte{yyDollar[1].bytes}
 		}
-	case 98:
+	case 132:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:625
+		//line ./sqlparser/sql.y:826
 		{
 			yyVAL.bytes2 = append(yyDollar[1].bytes2, yyDollar[3].bytes)
 		}
-	case 99:
+	case 133:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:630
+		//line ./sqlparser/sql.y:831
 		{
 			yyVAL.boolExpr = nil
 		}
-	case 100:
+	case 134:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:634
+		//line ./sqlparser/sql.y:835
 		{
 			yyVAL.boolExpr = yyDollar[2].boolExpr
 		}
-	case 102:
+	case 136:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:641
+		//line ./sqlparser/sql.y:842
 		{
 			yyVAL.boolExpr = &AndExpr{Left: yyDollar[1].boolExpr, Right: yyDollar[3].boolExpr}
 		}
-	case 103:
+	case 137:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:645
+		//line ./sqlparser/sql.y:846
 		{
 			yyVAL.boolExpr = &OrExpr{Left: yyDollar[1].boolExpr, Right: yyDollar[3].boolExpr}
 		}
-	case 104:
+	case 138:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:649
+		//line ./sqlparser/sql.y:850
 		{
 			yyVAL.boolExpr = &NotExpr{Expr: yyDollar[2].boolExpr}
 		}
-	case 105:
+	case 139:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:653
+		//line ./sqlparser/sql.y:854
 		{
 			yyVAL.boolExpr = &ParenBoolExpr{Expr: yyDollar[2].boolExpr}
 		}
-	case 106:
+	case 140:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:659
+		//line ./sqlparser/sql.y:860
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: yyDollar[2].str, Right: yyDollar[3].valExpr}
 		}
-	case 107:
+	case 141:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:663
+		//line ./sqlparser/sql.y:864
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: AST_IN, Right: yyDollar[3].tuple}
 		}
-	case 108:
+	case 142:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:667
+		//line ./sqlparser/sql.y:868
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: AST_NOT_IN, Right: yyDollar[4].tuple}
 		}
-	case 109:
+	case 143:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:671
+		//line ./sqlparser/sql.y:872
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: AST_LIKE, Right: yyDollar[3].valExpr}
 		}
-	case 110:
+	case 144:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:675
+		//line ./sqlparser/sql.y:876
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: AST_NOT_LIKE, Right: yyDollar[4].valExpr}
 		}
-	case 111:
+	case 145:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:679
+		//line ./sqlparser/sql.y:880
 		{
 			yyVAL.boolExpr = &RangeCond{Left: yyDollar[1].valExpr, Operator: AST_BETWEEN, From: yyDollar[3].valExpr, To: yyDollar[5].valExpr}
 		}
-	case 112:
+	case 146:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line ./sqlparser/sql.y:683
+		//line ./sqlparser/sql.y:884
 		{
 			yyVAL.boolExpr = &RangeCond{Left: yyDollar[1].valExpr, Operator: AST_NOT_BETWEEN, From: yyDollar[4].valExpr, To: yyDollar[6].valExpr}
 		}
-	case 113:
+	case 147:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:687
+		//line ./sqlparser/sql.y:888
 		{
 			yyVAL.boolExpr = &NullCheck{Operator: AST_IS_NULL, Expr: yyDollar[1].valExpr}
 		}
-	case 114:
+	case 148:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:691
+		//line ./sqlparser/sql.y:892
 		{
 			yyVAL.boolExpr = &NullCheck{Operator: AST_IS_NOT_NULL, Expr: yyDollar[1].valExpr}
 		}
-	case 115:
+	case 149:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:695
+		//line ./sqlparser/sql.y:896
 		{
 			yyVAL.boolExpr = &ExistsExpr{Subquery: yyDollar[2].subquery}
 		}
-	case 116:
+	case 150:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:701
+		//line ./sqlparser/sql.y:902
 		{
 			yyVAL.str = AST_EQ
 		}
-	case 117:
+	case 151:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:705
+		//line ./sqlparser/sql.y:906
 		{
 			yyVAL.str = AST_LT
 		}
-	case 118:
+	case 152:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:709
+		//line ./sqlparser/sql.y:910
 		{
 			yyVAL.str = AST_GT
 		}
-	case 119:
+	case 153:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:713
+		//line ./sqlparser/sql.y:914
 		{
 			yyVAL.str = AST_LE
 		}
-	case 120:
+	case 154:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:717
+		//line ./sqlparser/sql.y:918
 		{
 			yyVAL.str = AST_GE
 		}
-	case 121:
+	case 155:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:721
+		//line ./sqlparser/sql.y:922
 		{
 			yyVAL.str = AST_NE
 		}
-	case 122:
+	case 156:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:725
+		//line ./sqlparser/sql.y:926
 		{
 			yyVAL.str = AST_NSE
 		}
-	case 123:
+	case 157:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:731
+		//line ./sqlparser/sql.y:932
 		{
 			yyVAL.insRows = yyDollar[2].values
 		}
-	case 124:
+	case 158:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:735
+		//line ./sqlparser/sql.y:936
 		{
 			yyVAL.insRows = yyDollar[1].selStmt
 		}
-	case 125:
+	case 159:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:741
+		//line ./sqlparser/sql.y:942
 		{
 			yyVAL.values = Values{yyDollar[1].tuple}
 		}
-	case 126:
+	case 160:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:745
+		//line ./sqlparser/sql.y:946
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].tuple)
 		}
-	case 127:
+	case 161:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:751
+		//line ./sqlparser/sql.y:952
 		{
 			yyVAL.tuple = ValTuple(yyDollar[2].valExprs)
 		}
-	case 128:
+	case 162:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:755
+		//line ./sqlparser/sql.y:956
 		{
 			yyVAL.tuple = yyDollar[1].subquery
 		}
-	case 129:
+	case 163:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:761
+		//line ./sqlparser/sql.y:962
 		{
 			yyVAL.subquery = &Subquery{yyDollar[2].selStmt}
 		}
-	case 130:
+	case 164:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:767
+		//line ./sqlparser/sql.y:968
 		{
 			yyVAL.valExprs = ValExprs{yyDollar[1].valExpr}
 		}
-	case 131:
+	case 165:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:771
+		//line ./sqlparser/sql.y:972
 		{
 			yyVAL.valExprs = append(yyDollar[1].valExprs, yyDollar[3].valExpr)
 		}
-	case 132:
+	case 166:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:777
+		//line ./sqlparser/sql.y:978
 		{
 			yyVAL.valExpr = yyDollar[1].valExpr
 		}
-	case 133:
+	case 167:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:781
+		//line ./sqlparser/sql.y:982
 		{
 			yyVAL.valExpr = yyDollar[1].colName
 		}
-	case 134:
+	case 168:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:785
+		//line ./sqlparser/sql.y:986
 		{
 			yyVAL.valExpr = yyDollar[1].tuple
 		}
-	case 135:
+	case 169:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:789
+		//line ./sqlparser/sql.y:990
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_BITAND, Right: yyDollar[3].valExpr}
 		}
-	case 136:
+	case 170:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:793
+		//line ./sqlparser/sql.y:994
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_BITOR, Right: yyDollar[3].valExpr}
 		}
-	case 137:
+	case 171:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:797
+		//line ./sqlparser/sql.y:998
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_BITXOR, Right: yyDollar[3].valExpr}
 		}
-	case 138:
+	case 172:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:801
+		//line ./sqlparser/sql.y:1002
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_PLUS, Right: yyDollar[3].valExpr}
 		}
-	case 139:
+	case 173:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:805
+		//line ./sqlparser/sql.y:1006
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_MINUS, Right: yyDollar[3].valExpr}
 		}
-	case 140:
+	case 174:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:809
+		//line ./sqlparser/sql.y:1010
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_MULT, Right: yyDollar[3].valExpr}
 		}
-	case 141:
+	case 175:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:813
+		//line ./sqlparser/sql.y:1014
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_DIV, Right: yyDollar[3].valExpr}
 		}
-	case 142:
+	case 176:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:817
+		//line ./sqlparser/sql.y:1018
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: AST_MOD, Right: yyDollar[3].valExpr}
 		}
-	case 143:
+	case 177:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:821
+		//line ./sqlparser/sql.y:1022
 		{
 			if num, ok := yyDollar[2].valExpr.(NumVal); ok {
 				switch yyDollar[1].byt {
@@ -1710,267 +2002,267 @@ yydefault:
 				yyVAL.valExpr = &UnaryExpr{Operator: yyDollar[1].byt, Expr: yyDollar[2].valExpr}
 			}
 		}
-	case 144:
+	case 178:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:836
+		//line ./sqlparser/sql.y:1037
 		{
 			yyVAL.valExpr = &FuncExpr{Name: yyDollar[1].bytes}
 		}
-	case 145:
+	case 179:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:840
+		//line ./sqlparser/sql.y:1041
 		{
 			yyVAL.valExpr = &FuncExpr{Name: yyDollar[1].bytes, Exprs: yyDollar[3].selectExprs}
 		}
-	case 146:
+	case 180:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:844
+		//line ./sqlparser/sql.y:1045
 		{
 			yyVAL.valExpr = &FuncExpr{Name: yyDollar[1].bytes, Distinct: true, Exprs: yyDollar[4].selectExprs}
 		}
-	case 147:
+	case 181:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:848
+		//line ./sqlparser/sql.y:1049
 		{
 			yyVAL.valExpr = &FuncExpr{Name: yyDollar[1].bytes, Exprs: yyDollar[3].selectExprs}
 		}
-	case 148:
+	case 182:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:852
+		//line ./sqlparser/sql.y:1053
 		{
 			yyVAL.valExpr = yyDollar[1].caseExpr
 		}
-	case 149:
+	case 183:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:858
+		//line ./sqlparser/sql.y:1059
 		{
 			yyVAL.bytes = IF_BYTES
 		}
-	case 150:
+	case 184:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:862
+		//line ./sqlparser/sql.y:1063
 		{
 			yyVAL.bytes = VALUES_BYTES
 		}
-	case 151:
+	case 185:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:868
+		//line ./sqlparser/sql.y:1069
 		{
 			yyVAL.byt = AST_UPLUS
 		}
-	case 152:
+	case 186:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:872
+		//line ./sqlparser/sql.y:1073
 		{
 			yyVAL.byt = AST_UMINUS
 		}
-	case 153:
+	case 187:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:876
+		//line ./sqlparser/sql.y:1077
 		{
 			yyVAL.byt = AST_TILDA
 		}
-	case 154:
+	case 188:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:882
+		//line ./sqlparser/sql.y:1083
 		{
 			yyVAL.caseExpr = &CaseExpr{Expr: yyDollar[2].valExpr, Whens: yyDollar[3].whens, Else: yyDollar[4].valExpr}
 		}
-	case 155:
+	case 189:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:887
+		//line ./sqlparser/sql.y:1088
 		{
 			yyVAL.valExpr = nil
 		}
-	case 156:
+	case 190:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:891
+		//line ./sqlparser/sql.y:1092
 		{
 			yyVAL.valExpr = yyDollar[1].valExpr
 		}
-	case 157:
+	case 191:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:897
+		//line ./sqlparser/sql.y:1098
 		{
 			yyVAL.whens = []*When{yyDollar[1].when}
 		}
-	case 158:
+	case 192:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:901
+		//line ./sqlparser/sql.y:1102
 		{
 			yyVAL.whens = append(yyDollar[1].whens, yyDollar[2].when)
 		}
-	case 159:
+	case 193:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:907
+		//line ./sqlparser/sql.y:1108
 		{
 			yyVAL.when = &When{Cond: yyDollar[2].boolExpr, Val: yyDollar[4].valExpr}
 		}
-	case 160:
+	case 194:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:912
+		//line ./sqlparser/sql.y:1113
 		{
 			yyVAL.valExpr = nil
 		}
-	case 161:
+	case 195:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:916
+		//line ./sqlparser/sql.y:1117
 		{
 			yyVAL.valExpr = yyDollar[2].valExpr
 		}
-	case 162:
+	case 196:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:922
+		//line ./sqlparser/sql.y:1123
 		{
 			yyVAL.colName = &ColName{Name: yyDollar[1].bytes}
 		}
-	case 163:
+	case 197:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:926
+		//line ./sqlparser/sql.y:1127
 		{
 			yyVAL.colName = &ColName{Qualifier: yyDollar[1].bytes, Name: yyDollar[3].bytes}
 		}
-	case 164:
+	case 198:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:930
+		//line ./sqlparser/sql.y:1131
 		{
 			yyVAL.colName = &ColName{Qualifier: yyDollar[3].bytes, Name: yyDollar[5].bytes}
 		}
-	case 165:
+	case 199:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:936
+		//line ./sqlparser/sql.y:1137
 		{
 			yyVAL.valExpr = StrVal(yyDollar[1].bytes)
 		}
-	case 166:
+	case 200:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:940
+		//line ./sqlparser/sql.y:1141
 		{
 			yyVAL.valExpr = NumVal(yyDollar[1].bytes)
 		}
-	case 167:
+	case 201:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:944
+		//line ./sqlparser/sql.y:1145
 		{
 			yyVAL.valExpr = ValArg(yyDollar[1].bytes)
 		}
-	case 168:
+	case 202:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:948
+		//line ./sqlparser/sql.y:1149
 		{
 			yyVAL.valExpr = &NullVal{}
 		}
-	case 169:
+	case 203:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:953
+		//line ./sqlparser/sql.y:1154
 		{
 			yyVAL.valExprs = nil
 		}
-	case 170:
+	case 204:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:957
+		//line ./sqlparser/sql.y:1158
 		{
 			yyVAL.valExprs = yyDollar[3].valExprs
 		}
-	case 171:
+	case 205:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:962
+		//line ./sqlparser/sql.y:1163
 		{
 			yyVAL.boolExpr = nil
 		}
-	case 172:
+	case 206:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:966
+		//line ./sqlparser/sql.y:1167
 		{
 			yyVAL.boolExpr = yyDollar[2].boolExpr
 		}
-	case 173:
+	case 207:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:971
+		//line ./sqlparser/sql.y:1172
 		{
 			yyVAL.orderBy = nil
 		}
-	case 174:
+	case 208:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:975
+		//line ./sqlparser/sql.y:1176
 		{
 			yyVAL.orderBy = yyDollar[3].orderBy
 		}
-	case 175:
+	case 209:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:981
+		//line ./sqlparser/sql.y:1182
 		{
 			yyVAL.orderBy = OrderBy{yyDollar[1].order}
 		}
-	case 176:
+	case 210:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:985
+		//line ./sqlparser/sql.y:1186
 		{
 			yyVAL.orderBy = append(yyDollar[1].orderBy, yyDollar[3].order)
 		}
-	case 177:
+	case 211:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:991
+		//line ./sqlparser/sql.y:1192
 		{
 			yyVAL.order = &Order{Expr: yyDollar[1].valExpr, Direction: yyDollar[2].str}
 		}
-	case 178:
+	case 212:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:996
+		//line ./sqlparser/sql.y:1197
 		{
 			yyVAL.str = AST_ASC
 		}
-	case 179:
+	case 213:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1000
+		//line ./sqlparser/sql.y:1201
 		{
 			yyVAL.str = AST_ASC
 		}
-	case 180:
+	case 214:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1004
+		//line ./sqlparser/sql.y:1205
 		{
 			yyVAL.str = AST_DESC
 		}
-	case 181:
+	case 215:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1009
+		//line ./sqlparser/sql.y:1210
 		{
 			yyVAL.limit = nil
 		}
-	case 182:
+	case 216:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:1013
+		//line ./sqlparser/sql.y:1214
 		{
 			yyVAL.limit = &Limit{Rowcount: yyDollar[2].valExpr}
 		}
-	case 183:
+	case 217:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:1017
+		//line ./sqlparser/sql.y:1218
 		{
 			yyVAL.limit = &Limit{Offset: yyDollar[2].valExpr, Rowcount: yyDollar[4].valExpr}
 		}
-	case 184:
+	case 218:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:1021
+		//line ./sqlparser/sql.y:1222
 		{
 			yyVAL.limit = &Limit{Offset: yyDollar[4].valExpr, Rowcount: yyDollar[2].valExpr}
 		}
-	case 185:
+	case 219:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1026
+		//line ./sqlparser/sql.y:1227
 		{
 			yyVAL.str = ""
 		}
-	case 186:
+	case 220:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:1030
+		//line ./sqlparser/sql.y:1231
 		{
 			yyVAL.str = AST_FOR_UPDATE
 		}
-	case 187:
+	case 221:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line ./sqlparser/sql.y:1034
+		//line ./sqlparser/sql.y:1235
 		{
 			if !bytes.Equal(yyDollar[3].bytes, SHARE) {
 				yylex.Error("expecting share")
@@ -1982,189 +2274,195 @@ yydefault:
 			}
 			yyVAL.str = AST_SHARE_MODE
 		}
-	case 188:
+	case 222:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1047
+		//line ./sqlparser/sql.y:1248
 		{
 			yyVAL.columns = nil
 		}
-	case 189:
+	case 223:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1051
+		//line ./sqlparser/sql.y:1252
 		{
 			yyVAL.columns = yyDollar[2].columns
 		}
-	case 190:
+	case 224:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1057
+		//line ./sqlparser/sql.y:1258
 		{
 			yyVAL.columns = Columns{&NonStarExpr{Expr: yyDollar[1].colName}}
 		}
-	case 191:
+	case 225:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1061
+		//line ./sqlparser/sql.y:1262
 		{
 			yyVAL.columns = append(yyVAL.columns, &NonStarExpr{Expr: yyDollar[3].colName})
 		}
-	case 192:
+	case 226:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1066
+		//line ./sqlparser/sql.y:1267
 		{
 			yyVAL.updateExprs = nil
 		}
-	case 193:
+	case 227:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line ./sqlparser/sql.y:1070
+		//line ./sqlparser/sql.y:1271
 		{
 			yyVAL.updateExprs = yyDollar[5].updateExprs
 		}
-	case 194:
+	case 228:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1076
+		//line ./sqlparser/sql.y:1277
 		{
 			yyVAL.updateExprs = UpdateExprs{yyDollar[1].updateExpr}
 		}
-	case 195:
+	case 229:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1080
+		//line ./sqlparser/sql.y:1281
 		{
 			yyVAL.updateExprs = append(yyDollar[1].updateExprs, yyDollar[3].updateExpr)
 		}
-	case 196:
+	case 230:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1086
+		//line ./sqlparser/sql.y:1287
 		{
 			yyVAL.updateExpr = &UpdateExpr{Name: yyDollar[1].colName, Expr: yyDollar[3].valExpr}
 		}
-	case 197:
+	case 231:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1090
+		//line ./sqlparser/sql.y:1291
 		{
 			yyVAL.updateExpr = &UpdateExpr{Name: yyDollar[1].colName, Expr: StrVal("ON")}
 		}
-	case 198:
+	case 232:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line ./sqlparser/sql.y:1295
+		{
+			yyVAL.updateExpr = &UpdateExpr{Name: yyDollar[1].colName, Expr: &DefaultVal{}}
+		}
+	case 233:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1095
+		//line ./sqlparser/sql.y:1300
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 199:
+	case 234:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:1097
+		//line ./sqlparser/sql.y:1302
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 200:
+	case 235:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1100
+		//line ./sqlparser/sql.y:1305
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 201:
+	case 236:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line ./sqlparser/sql.y:1102
+		//line ./sqlparser/sql.y:1307
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 202:
+	case 237:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1105
+		//line ./sqlparser/sql.y:1310
 		{
 			yyVAL.str = ""
 		}
-	case 203:
+	case 238:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1107
+		//line ./sqlparser/sql.y:1312
 		{
 			yyVAL.str = AST_IGNORE
 		}
-	case 204:
+	case 239:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1111
+		//line ./sqlparser/sql.y:1316
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 205:
+	case 240:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1113
+		//line ./sqlparser/sql.y:1318
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 206:
+	case 241:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1115
+		//line ./sqlparser/sql.y:1320
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 207:
+	case 242:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1117
+		//line ./sqlparser/sql.y:1322
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 208:
+	case 243:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1119
+		//line ./sqlparser/sql.y:1324
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 209:
+	case 244:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1122
+		//line ./sqlparser/sql.y:1327
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 210:
+	case 245:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1124
+		//line ./sqlparser/sql.y:1329
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 211:
+	case 246:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1127
+		//line ./sqlparser/sql.y:1332
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 212:
+	case 247:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1129
+		//line ./sqlparser/sql.y:1334
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 213:
+	case 248:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1132
+		//line ./sqlparser/sql.y:1337
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 214:
+	case 249:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line ./sqlparser/sql.y:1134
+		//line ./sqlparser/sql.y:1339
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 215:
+	case 250:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1138
+		//line ./sqlparser/sql.y:1343
 		{
 			yyVAL.bytes = bytes.ToLower(yyDollar[1].bytes)
 		}
-	case 216:
+	case 251:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1143
+		//line ./sqlparser/sql.y:1348
 		{
 			ForceEOF(yylex)
 		}
-	case 217:
+	case 252:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line ./sqlparser/sql.y:1148
+		//line ./sqlparser/sql.y:1353
 		{
 			yyVAL.str = ""
 		}
-	case 218:
+	case 253:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line ./sqlparser/sql.y:1152
+		//line ./sqlparser/sql.y:1357
 		{
 			yyVAL.str = AST_TABLE
 		}
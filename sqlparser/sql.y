@@ -38,6 +38,12 @@ var (
   MODE  =        []byte("mode")
   IF_BYTES =     []byte("if")
   VALUES_BYTES = []byte("values")
+  DATA_BYTES =   []byte("data")
+  LOCAL_BYTES =  []byte("local")
+  FIELDS_BYTES = []byte("fields")
+  COLUMNS_BYTES = []byte("columns")
+  ROWS_BYTES =   []byte("rows")
+  SAVEPOINT_BYTES = []byte("savepoint")
 )
 
 %}
@@ -50,6 +56,7 @@ var (
   bytes       []byte
   bytes2      [][]byte
   str         string
+  strs        []string
   selectExprs SelectExprs
   selectExpr  SelectExpr
   columns     Columns
@@ -75,6 +82,8 @@ var (
   insRows     InsertRows
   updateExprs UpdateExprs
   updateExpr  *UpdateExpr
+  loadFields  *LoadDataFields
+  loadLines   *LoadDataLines
 }
 
 %token LEX_ERROR
@@ -105,7 +114,7 @@ var (
 %left <empty> END
 
 // Transaction Tokens
-%token <empty> BEGIN START TRANSACTION COMMIT ROLLBACK
+%token <empty> BEGIN START TRANSACTION COMMIT ROLLBACK RELEASE
 
 // Charset Tokens
 %token <empty> NAMES 
@@ -127,6 +136,9 @@ var (
 // truncate 
 %token <empty> TRUNCATE
 
+// load data
+%token <empty> LOAD INFILE TERMINATED OPTIONALLY ENCLOSED ESCAPED LINES STARTING
+
 %start any_command
 
 %type <statement> command
@@ -181,10 +193,18 @@ var (
 %type <str> table_opt
 
 %type <statement> begin_statement commit_statement rollback_statement
+%type <statement> savepoint_statement release_statement
+%type <strs> transaction_characteristic_list
+%type <str> transaction_characteristic
 %type <statement> replace_statement
 %type <statement> admin_statement
 %type <statement> use_statement
 %type <statement> truncate_statement
+%type <statement> load_statement
+%type <str> load_dup_opt
+%type <loadFields> load_fields_opt load_field_list
+%type <loadLines> load_lines_opt load_line_list
+%type <bytes> load_ignore_opt
 
 %%
 
@@ -210,10 +230,13 @@ command:
 | begin_statement
 | commit_statement
 | rollback_statement
+| savepoint_statement
+| release_statement
 | replace_statement
 | admin_statement
 | use_statement
 | truncate_statement
+| load_statement
 
 select_statement:
   SELECT comment_opt distinct_opt select_expression_list
@@ -280,6 +303,10 @@ set_statement:
   {
     $$ = &Set{Comments: Comments($2), Exprs: $3}
   }
+| SET comment_opt sql_id update_list
+  {
+    $$ = &Set{Comments: Comments($2), Scope: string($3), Exprs: $4}
+  }
 | SET comment_opt NAMES DEFAULT
    {
      $$ = &Set{Comments: Comments($2), Exprs: UpdateExprs{&UpdateExpr{Name: &ColName{Name:[]byte("names")}, Expr: StrVal("default")}}}
@@ -302,6 +329,14 @@ set_statement:
 	       },
 	    }
   }
+| SET comment_opt TRANSACTION transaction_characteristic_list
+  {
+    $$ = &SetTransaction{Comments: Comments($2), Characteristics: $4}
+  }
+| SET comment_opt sql_id TRANSACTION transaction_characteristic_list
+  {
+    $$ = &SetTransaction{Comments: Comments($2), Scope: string($3), Characteristics: $5}
+  }
 
 begin_statement:
   BEGIN
@@ -312,6 +347,30 @@ begin_statement:
   {
     $$ = &Begin{}
   }
+| START TRANSACTION transaction_characteristic_list
+  {
+    $$ = &Begin{Characteristics: $3}
+  }
+
+transaction_characteristic_list:
+  transaction_characteristic
+  {
+    $$ = []string{$1}
+  }
+| transaction_characteristic_list ',' transaction_characteristic
+  {
+    $$ = append($1, $3)
+  }
+
+transaction_characteristic:
+  sql_id
+  {
+    $$ = string($1)
+  }
+| transaction_characteristic sql_id
+  {
+    $$ = $1 + " " + string($2)
+  }
 
 
 commit_statement:
@@ -325,6 +384,38 @@ rollback_statement:
   {
     $$ = &Rollback{}
   }
+| ROLLBACK TO sql_id
+  {
+    $$ = &RollbackSavepoint{Name: $3}
+  }
+| ROLLBACK TO sql_id sql_id
+  {
+    if !bytes.Equal($3, SAVEPOINT_BYTES) {
+      yylex.Error("expecting savepoint")
+      return 1
+    }
+    $$ = &RollbackSavepoint{Name: $4}
+  }
+
+savepoint_statement:
+  sql_id sql_id
+  {
+    if !bytes.Equal($1, SAVEPOINT_BYTES) {
+      yylex.Error("expecting savepoint")
+      return 1
+    }
+    $$ = &Savepoint{Name: $2}
+  }
+
+release_statement:
+  RELEASE sql_id sql_id
+  {
+    if !bytes.Equal($2, SAVEPOINT_BYTES) {
+      yylex.Error("expecting savepoint")
+      return 1
+    }
+    $$ = &ReleaseSavepoint{Name: $3}
+  }
 
 admin_statement:
   ADMIN dml_table_expression column_list_opt row_list
@@ -348,6 +439,116 @@ truncate_statement:
     $$ = &Truncate{Comments: Comments($2), TableOpt: $3, Table: $4}
   }
 
+load_statement:
+  LOAD comment_opt sql_id sql_id INFILE STRING load_dup_opt INTO TABLE dml_table_expression load_fields_opt load_lines_opt load_ignore_opt column_list_opt
+  {
+    if !bytes.Equal($3, DATA_BYTES) {
+      yylex.Error("expecting data")
+      return 1
+    }
+    //only the file sent by client is supported
+    if !bytes.Equal($4, LOCAL_BYTES) {
+      yylex.Error("expecting local")
+      return 1
+    }
+    $$ = &LoadData{Comments: Comments($2), File: StrVal($6), DupOpt: $7, Table: $10, Fields: $11, Lines: $12, IgnoreLines: NumVal($13), Columns: $14}
+  }
+
+load_dup_opt:
+  { $$ = "" }
+| REPLACE
+  { $$ = AST_LOAD_REPLACE }
+| IGNORE
+  { $$ = AST_IGNORE }
+
+load_fields_opt:
+  {
+    $$ = nil
+  }
+| sql_id load_field_list
+  {
+    if !bytes.Equal($1, FIELDS_BYTES) && !bytes.Equal($1, COLUMNS_BYTES) {
+      yylex.Error("expecting fields")
+      return 1
+    }
+    if $2.Terminated == nil && $2.Enclosed == nil && $2.Escaped == nil {
+      yylex.Error("expecting terminated, enclosed or escaped")
+      return 1
+    }
+    $$ = $2
+  }
+
+load_field_list:
+  {
+    $$ = &LoadDataFields{}
+  }
+| load_field_list TERMINATED BY STRING
+  {
+    $1.Terminated = StrVal($4)
+    $$ = $1
+  }
+| load_field_list ENCLOSED BY STRING
+  {
+    $1.Enclosed = StrVal($4)
+    $$ = $1
+  }
+| load_field_list OPTIONALLY ENCLOSED BY STRING
+  {
+    $1.Optionally = true
+    $1.Enclosed = StrVal($5)
+    $$ = $1
+  }
+| load_field_list ESCAPED BY STRING
+  {
+    $1.Escaped = StrVal($4)
+    $$ = $1
+  }
+
+load_lines_opt:
+  {
+    $$ = nil
+  }
+| LINES load_line_list
+  {
+    if $2.Starting == nil && $2.Terminated == nil {
+      yylex.Error("expecting starting or terminated")
+      return 1
+    }
+    $$ = $2
+  }
+
+load_line_list:
+  {
+    $$ = &LoadDataLines{}
+  }
+| load_line_list STARTING BY STRING
+  {
+    $1.Starting = StrVal($4)
+    $$ = $1
+  }
+| load_line_list TERMINATED BY STRING
+  {
+    $1.Terminated = StrVal($4)
+    $$ = $1
+  }
+
+load_ignore_opt:
+  {
+    $$ = nil
+  }
+| IGNORE NUMBER LINES
+  {
+    $$ = $2
+  }
+| IGNORE NUMBER sql_id
+  {
+    if !bytes.Equal($3, ROWS_BYTES) {
+      yylex.Error("expecting lines")
+      return 1
+    }
+    $$ = $2
+  }
+
 create_statement:
   CREATE TABLE not_exists_opt ID force_eof
   {
@@ -1090,6 +1291,10 @@ update_expression:
   {
     $$ = &UpdateExpr{Name: $1, Expr: StrVal("ON")}
   }
+| column_name '=' DEFAULT
+  {
+    $$ = &UpdateExpr{Name: $1, Expr: &DefaultVal{}}
+  }
 
 exists_opt:
   { $$ = struct{}{} }
@@ -54,4 +54,168 @@ func TestMixer(t *testing.T) {
 
 	sql = "show proxy abc"
 	testParse(t, sql)
+
+	sql = "select 1 # comment\nfrom t"
+	testParse(t, sql)
+}
+
+func TestSavepoint(t *testing.T) {
+	sqls := map[string]string{
+		"savepoint sp1":                "savepoint sp1",
+		"SAVEPOINT `SP1`":              "savepoint sp1",
+		"release savepoint sp1":        "release savepoint sp1",
+		"rollback to sp1":              "rollback to savepoint sp1",
+		"rollback to savepoint sp1":    "rollback to savepoint sp1",
+		"ROLLBACK TO SAVEPOINT s_p_01": "rollback to savepoint s_p_01",
+		"savepoint savepoint":          "savepoint savepoint",
+		"select savepoint from t":      "select savepoint from t",
+	}
+	for sql, expect := range sqls {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if s := String(stmt); s != expect {
+			t.Fatalf("%s: expect %s, got %s", sql, expect, s)
+		}
+	}
+
+	for _, sql := range []string{"release sp1", "savepoints sp1", "rollback to save sp1"} {
+		if _, err := Parse(sql); err == nil {
+			t.Fatal(sql, "must fail")
+		}
+	}
+}
+
+func TestTransactionCharacteristics(t *testing.T) {
+	sqls := map[string]string{
+		"begin":                       "begin",
+		"start transaction":           "begin",
+		"START TRANSACTION READ ONLY": "start transaction read only",
+		"start transaction with consistent snapshot, read write":          "start transaction with consistent snapshot, read write",
+		"set transaction isolation level read committed":                  "set transaction isolation level read committed",
+		"SET SESSION TRANSACTION READ ONLY, ISOLATION LEVEL SERIALIZABLE": "set session transaction read only, isolation level serializable",
+		"set global transaction isolation level repeatable read":          "set global transaction isolation level repeatable read",
+	}
+	for sql, expect := range sqls {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if s := String(stmt); s != expect {
+			t.Fatalf("%s: expect %s, got %s", sql, expect, s)
+		}
+	}
+
+	//the columns named as the words of characteristics
+	testParse(t, "set level = 1")
+	testParse(t, "select read, only from t where level = 1")
+}
+
+func TestSetScope(t *testing.T) {
+	sqls := map[string]string{
+		"set session sql_mode = 'ANSI'":     "set session sql_mode = 'ANSI'",
+		"set @@session.time_zone = default": "set @@session.time_zone = default",
+		"set @a = 1, @b = 'b'":              "set @a = 1, @b = 'b'",
+	}
+	for sql, expect := range sqls {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if s := String(stmt); s != expect {
+			t.Fatalf("%s: expect %s, got %s", sql, expect, s)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		sql   string
+		stmts []string
+	}{
+		{"select 1", []string{"select 1"}},
+		{"select 1;", []string{"select 1"}},
+		{"select 1; select 2 ;; ", []string{"select 1", "select 2"}},
+		{"insert into t values('a;b');update t set `c;d`=1", []string{"insert into t values('a;b')", "update t set `c;d`=1"}},
+		{"select 1 /* ; */;select 2 -- ;\n", []string{"select 1 /* ; */", "select 2 -- ;"}},
+		{"select 1 # c;omment\n; select 2", []string{"select 1 # c;omment", "select 2"}},
+	}
+	for _, test := range tests {
+		stmts := SplitStatements(test.sql)
+		if len(stmts) != len(test.stmts) {
+			t.Fatalf("split %q: got %q", test.sql, stmts)
+		}
+		for i := range stmts {
+			if stmts[i] != test.stmts[i] {
+				t.Fatalf("split %q: got %q, expect %q", test.sql, stmts[i], test.stmts[i])
+			}
+		}
+	}
+}
+
+func TestPositionalArgs(t *testing.T) {
+	tests := []struct {
+		sql     string
+		out     string
+		indexes []int
+	}{
+		{"select 1", "select 1", []int{}},
+		{"select * from t where id = ? and name = ?", "select * from t where id = ? and name = ?", []int{0, 1}},
+		{"insert into t(id, name) values (?, '?'), (?, ?)", "insert  into t(id, name) values (?, '?'), (?, ?)", []int{0, 1, 2}},
+	}
+	for _, test := range tests {
+		stmt, err := Parse(test.sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, indexes := PositionalArgs(String(stmt))
+		if out != test.out {
+			t.Fatalf("replace args of %q: got %q, expect %q", test.sql, out, test.out)
+		}
+		if len(indexes) != len(test.indexes) {
+			t.Fatalf("indexes of %q: got %v, expect %v", test.sql, indexes, test.indexes)
+		}
+		for i := range indexes {
+			if indexes[i] != test.indexes[i] {
+				t.Fatalf("indexes of %q: got %v, expect %v", test.sql, indexes, test.indexes)
+			}
+		}
+	}
+
+	//the args of a sub table in the rewritten sql
+	out, indexes := PositionalArgs("select * from t_0001 where id in (:v1, :v3) limit :v4")
+	if out != "select * from t_0001 where id in (?, ?) limit ?" {
+		t.Fatalf("replace args: got %q", out)
+	}
+	if len(indexes) != 3 || indexes[0] != 0 || indexes[1] != 2 || indexes[2] != 3 {
+		t.Fatalf("indexes: got %v", indexes)
+	}
+}
+
+func TestLoadData(t *testing.T) {
+	sqls := map[string]string{
+		"load data local infile '/tmp/a.txt' into table t": "load data local infile '/tmp/a.txt' into table t",
+		"LOAD DATA LOCAL INFILE 'a.csv' REPLACE INTO TABLE db.t FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' LINES TERMINATED BY '\\r\\n' IGNORE 1 LINES (id, name)": "load data local infile 'a.csv' replace into table db.t fields terminated by ',' optionally enclosed by '\\\"' lines terminated by '\\r\\n' ignore 1 lines (id, name)",
+		"load data local infile 'a.csv' ignore into table t columns escaped by '' enclosed by '\\'' lines starting by 'x' ignore 2 rows":                                     "load data local infile 'a.csv' ignore into table t fields enclosed by '\\'' escaped by '' lines starting by 'x' ignore 2 lines",
+	}
+	for sql, expect := range sqls {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if s := String(stmt); s != expect {
+			t.Fatalf("%s: expect %s, got %s", sql, expect, s)
+		}
+	}
+
+	for _, sql := range []string{
+		"load data infile 'a.csv' into table t",
+		"load data local infile 'a.csv' into table t fields lines",
+		"load data local infile 'a.csv' into table t lines",
+	} {
+		if _, err := Parse(sql); err == nil {
+			t.Fatal(sql, "must fail")
+		}
+	}
 }
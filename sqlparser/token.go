@@ -21,6 +21,7 @@ package sqlparser
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/flike/kingshard/sqltypes"
@@ -122,6 +123,7 @@ var keywords = map[string]int{
 	"begin":    BEGIN,
 	"rollback": ROLLBACK,
 	"commit":   COMMIT,
+	"release":  RELEASE,
 
 	"names":   NAMES,
 	"replace": REPLACE,
@@ -134,6 +136,15 @@ var keywords = map[string]int{
 	"collate":     COLLATE,
 	"offset":      OFFSET,
 	"truncate":    TRUNCATE,
+
+	"load":       LOAD,
+	"infile":     INFILE,
+	"terminated": TERMINATED,
+	"optionally": OPTIONALLY,
+	"enclosed":   ENCLOSED,
+	"escaped":    ESCAPED,
+	"lines":      LINES,
+	"starting":   STARTING,
 }
 
 // Lex returns the next token form the Tokenizer.
@@ -212,6 +223,8 @@ func (tkn *Tokenizer) Scan() (int, []byte) {
 			default:
 				return int(ch), nil
 			}
+		case '#':
+			return tkn.scanCommentType1("#")
 		case '-':
 			if tkn.lastChar == '-' {
 				tkn.next()
@@ -457,3 +470,62 @@ func digitVal(ch uint16) int {
 func isDigit(ch uint16) bool {
 	return '0' <= ch && ch <= '9'
 }
+
+// SplitStatements splits the sql into statements by the semicolons
+// out of strings, identifiers and comments. The empty statements
+// are skipped.
+func SplitStatements(sql string) []string {
+	tkn := NewStringTokenizer(sql)
+	stmts := make([]string, 0, 2)
+	start := 0
+	for {
+		typ, _ := tkn.Scan()
+		if typ == 0 {
+			break
+		}
+		if typ != ';' {
+			continue
+		}
+		// the tokenizer is one char ahead of the semicolon.
+		end := tkn.Position - 2
+		if stmt := strings.TrimSpace(sql[start:end]); len(stmt) != 0 {
+			stmts = append(stmts, stmt)
+		}
+		start = end + 1
+	}
+	if stmt := strings.TrimSpace(sql[start:]); len(stmt) != 0 {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// PositionalArgs replaces the positional args(:v1, :v2...) in the sql
+// formatted from a prepared statement with '?', and returns the indexes
+// of the args in the order they appear in the sql, the index of :v1 is 0.
+func PositionalArgs(sql string) (string, []int) {
+	tkn := NewStringTokenizer(sql)
+	buf := bytes.NewBuffer(make([]byte, 0, len(sql)))
+	indexes := make([]int, 0, 4)
+	start := 0
+	for {
+		typ, val := tkn.Scan()
+		if typ == 0 || typ == LEX_ERROR {
+			break
+		}
+		if typ != VALUE_ARG || len(val) < 3 || val[1] != 'v' {
+			continue
+		}
+		n, err := strconv.Atoi(string(val[2:]))
+		if err != nil || n < 1 {
+			continue
+		}
+		// the tokenizer is one char ahead of the arg.
+		end := tkn.Position - 1
+		buf.WriteString(sql[start : end-len(val)])
+		buf.WriteByte('?')
+		indexes = append(indexes, n-1)
+		start = end
+	}
+	buf.WriteString(sql[start:])
+	return buf.String(), indexes
+}
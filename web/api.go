@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flike/kingshard/backend"
 	ksError "github.com/flike/kingshard/core/errors"
 	"github.com/flike/kingshard/core/golog"
 	"github.com/labstack/echo"
@@ -76,6 +77,21 @@ type DBStatus struct {
 	LastPing string `json:"laste_ping"`
 	MaxConn  int    `json:"max_conn"`
 	IdleConn int    `json:"idle_conn"`
+	Lag      int64  `json:"lag"` //seconds behind master, -1 means unknown
+
+	InUse      int64 `json:"in_use"`
+	WaitCount  int64 `json:"wait_count"`
+	WaitTime   int64 `json:"wait_time"` //ms
+	DialErrors int64 `json:"dial_errors"`
+
+	TLS string `json:"tls"` //disabled, none or the tls version
+}
+
+func setPoolStats(status *DBStatus, stats backend.PoolStats) {
+	status.InUse = stats.InUse
+	status.WaitCount = stats.WaitCount
+	status.WaitTime = int64(stats.WaitTime / time.Millisecond)
+	status.DialErrors = stats.DialErrors
 }
 
 //get nodes status
@@ -94,6 +110,8 @@ func (s *ApiServer) GetNodesStatus(c echo.Context) error {
 		masterStatus.LastPing = fmt.Sprintf("%v", time.Unix(node.Master.GetLastPing(), 0))
 		masterStatus.MaxConn = node.Cfg.MaxConnNum
 		masterStatus.IdleConn = node.Master.IdleConnCount()
+		setPoolStats(&masterStatus, node.Master.PoolStats())
+		masterStatus.TLS = node.Master.TLSState()
 		dbStatus = append(dbStatus, masterStatus)
 
 		//get slaves status
@@ -105,6 +123,9 @@ func (s *ApiServer) GetNodesStatus(c echo.Context) error {
 			slaveStatus.LastPing = fmt.Sprintf("%v", time.Unix(slave.GetLastPing(), 0))
 			slaveStatus.MaxConn = node.Cfg.MaxConnNum
 			slaveStatus.IdleConn = slave.IdleConnCount()
+			slaveStatus.Lag = slave.GetLag()
+			setPoolStats(&slaveStatus, slave.PoolStats())
+			slaveStatus.TLS = slave.TLSState()
 			dbStatus = append(dbStatus, slaveStatus)
 		}
 	}